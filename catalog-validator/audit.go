@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/audit"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/importer"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/output"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+// runAudit validates all the packages in the catalog with all the rules, with their default severities and parameters
+// and without waivers, saves the result in the history directory and reports the packages broken and fixed since the
+// previous run. It fails if there are newly broken packages, so a scheduled audit notices the packages that stopped
+// passing the rules
+func runAudit(ctx context.Context) {
+	packageCatalogYamlFilepath, err := getKurtosisPackageCatalogYAMLFilepathFromArgs()
+	if err != nil {
+		exitFailure(err)
+	}
+
+	if *baseCatalogFlag != "" {
+		exitFailure(stacktrace.NewError("the --%s flag can't be used with the '%s' command, because it validates all the packages in the catalog", baseFlagName, auditCommandName))
+	}
+
+	// the audit results are compared with each other, so they have to check the same packages and rules with the same
+	// settings, otherwise a package could be reported as newly broken or fixed only because the settings changed
+	if len(*packageNamesFlag) > 0 {
+		exitFailure(stacktrace.NewError("the --%s flag can't be used with the '%s' command, because its result is compared with the previous one which checked all the packages", packagesFlagName, auditCommandName))
+	}
+	if len(*onlyRulePatternsFlag) > 0 || len(*skipRulePatternsFlag) > 0 {
+		exitFailure(stacktrace.NewError("the --%s and --%s flags can't be used with the '%s' command, because its result is compared with the previous one which checked all the rules", onlyFlagName, skipFlagName, auditCommandName))
+	}
+	if *configFilepathFlag != "" || len(ruleSeverityOverridesFlag) > 0 {
+		exitFailure(stacktrace.NewError("the --%s and --%s flags can't be used with the '%s' command, because its result is compared with the previous one which checked the rules with their default severities and parameters", configFlagName, ruleSeverityFlagName, auditCommandName))
+	}
+	if *waiversFilepathFlag != "" {
+		exitFailure(stacktrace.NewError("the --%s flag can't be used with the '%s' command, because its result is compared with the previous one which didn't waive any failure", waiversFileFlagName, auditCommandName))
+	}
+
+	outputFormat, err := output.ParseFormat(*outputFormatFlag)
+	if err != nil {
+		exitFailure(err)
+	}
+
+	auditTimestamp := time.Now()
+	logrus.Infof("Getting the Kurtosis packages to audit from '%s'...", packageCatalogYamlFilepath)
+	packageCatalog, err := importer.GetPackagesInTheCatalog(packageCatalogYamlFilepath, nil)
+	if err != nil {
+		exitFailure(err)
+	}
+	logrus.Infof("...'%d' packages to audit.", len(packageCatalog))
+
+	// the waivers file next to the catalog isn't used either, as a change in the waivers would change the broken packages
+	validatorResult, err := validateCatalog(ctx, packageCatalogYamlFilepath, packageCatalog, nil, nil)
+	if err != nil {
+		exitFailure(err)
+	}
+
+	logReport(validatorResult, packageCatalogYamlFilepath)
+
+	if err := writeReports(validatorResult, outputFormat, packageCatalogYamlFilepath); err != nil {
+		exitFailure(err)
+	}
+
+	history := audit.NewHistory(*historyDirpathFlag, packageCatalogYamlFilepath)
+	previousResult, err := history.GetLastResult()
+	if err != nil {
+		exitFailure(err)
+	}
+	historyFilepath, err := history.SaveResult(auditTimestamp, validatorResult)
+	if err != nil {
+		exitFailure(err)
+	}
+	logrus.Infof("The audit result was saved in '%s'", historyFilepath)
+
+	currentResult := audit.NewResultFromReport(auditTimestamp, validatorResult)
+	if previousResult == nil {
+		logrus.Infof("There isn't a previous audit result in '%s', all the broken packages are reported as newly broken", *historyDirpathFlag)
+	} else {
+		logrus.Infof("Comparing the audit result with the previous one from '%s'", previousResult.GetTimestamp().UTC().Format(time.RFC3339))
+	}
+	resultDiff := audit.CompareResults(previousResult, currentResult)
+
+	for _, packageName := range resultDiff.GetNewlyFixedPackages() {
+		logrus.Infof("Package '%s' was fixed, it was failing with: %s", packageName, strings.Join(previousResult.GetFailureCodes(packageName), ", "))
+	}
+	for _, packageName := range resultDiff.GetNewlyBrokenPackages() {
+		logrus.Errorf("Package '%s' is newly broken, it's failing with: %s", packageName, strings.Join(currentResult.GetFailureCodes(packageName), ", "))
+	}
+
+	if len(resultDiff.GetNewlyBrokenPackages()) > 0 {
+		exitFailure(stacktrace.NewError("there are '%d' newly broken packages since the previous audit", len(resultDiff.GetNewlyBrokenPackages())))
+	}
+
+	logrus.Infof("...audit finished, there aren't newly broken packages and '%d' were fixed", len(resultDiff.GetNewlyFixedPackages()))
+	logrus.Exit(successExitCode)
+}
@@ -0,0 +1,146 @@
+package audit
+
+import (
+	"encoding/json"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/output"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/rules"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/validator"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+	"github.com/kurtosis-tech/stacktrace"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// historyFileTimestampLayout sorts the history files by time when they are sorted by name
+	historyFileTimestampLayout = "20060102T150405Z"
+	historyFileExtension       = ".json"
+
+	historyDirPerms  = 0755
+	historyFilePerms = 0644
+)
+
+// historyReport contains the fields of the JSON report needed to get the audit result, the JSON report
+// schema is documented in 'output/json-report-schema.json'
+type historyReport struct {
+	Packages []string                `json:"packages"`
+	Failures []*historyReportFailure `json:"failures"`
+}
+
+type historyReportFailure struct {
+	Package  string `json:"package"`
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+}
+
+// History keeps the result of every audit run in a directory, as a JSON report named after the run timestamp,
+// e.g. '20240131T030000Z.json'
+type History struct {
+	dirpath string
+
+	// catalogFilepath is the catalog audited, it's used as the failures location in the JSON reports
+	catalogFilepath string
+}
+
+func NewHistory(dirpath string, catalogFilepath string) *History {
+	return &History{dirpath: dirpath, catalogFilepath: catalogFilepath}
+}
+
+// GetLastResult returns the result of the most recent run in the history, or nil if the history is empty
+func (history *History) GetLastResult() (*Result, error) {
+	dirEntries, err := os.ReadDir(history.dirpath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, stacktrace.Propagate(err, "an error occurred reading the audit history directory '%s'", history.dirpath)
+	}
+
+	historyFilenames := []string{}
+	for _, dirEntry := range dirEntries {
+		if _, err := parseHistoryFilename(dirEntry.Name()); err == nil && !dirEntry.IsDir() {
+			historyFilenames = append(historyFilenames, dirEntry.Name())
+		}
+	}
+	if len(historyFilenames) == 0 {
+		return nil, nil
+	}
+	sort.Strings(historyFilenames)
+
+	return history.readResult(historyFilenames[len(historyFilenames)-1])
+}
+
+// SaveResult writes the JSON report of the run in the history and returns its filepath, it fails if there is already a
+// result saved for the same second
+func (history *History) SaveResult(timestamp time.Time, report *validator.Report) (string, error) {
+	if err := os.MkdirAll(history.dirpath, historyDirPerms); err != nil {
+		return "", stacktrace.Propagate(err, "an error occurred creating the audit history directory '%s'", history.dirpath)
+	}
+
+	renderer, err := output.NewReportRenderer(output.FormatJSON, history.catalogFilepath)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "an error occurred creating the '%s' report renderer", output.FormatJSON)
+	}
+
+	historyFilepath := filepath.Join(history.dirpath, getHistoryFilename(timestamp))
+	// the file is never overwritten, an audit run in the same second as the previous one fails instead of replacing its result
+	historyFile, err := os.OpenFile(historyFilepath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, historyFilePerms)
+	if err != nil {
+		if os.IsExist(err) {
+			return "", stacktrace.Propagate(err, "there is already an audit result saved at '%s', from another audit run in the same second", historyFilepath)
+		}
+		return "", stacktrace.Propagate(err, "an error occurred creating the audit history file '%s'", historyFilepath)
+	}
+	defer historyFile.Close()
+	if err := renderer.Render(historyFile, report); err != nil {
+		return "", stacktrace.Propagate(err, "an error occurred writing the audit result to '%s'", historyFilepath)
+	}
+	return historyFilepath, nil
+}
+
+func (history *History) readResult(historyFilename string) (*Result, error) {
+	timestamp, err := parseHistoryFilename(historyFilename)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred getting the timestamp of the audit history file '%s'", historyFilename)
+	}
+
+	historyFilepath := filepath.Join(history.dirpath, historyFilename)
+	historyFileContent, err := os.ReadFile(historyFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "attempted to read the audit history file with path '%v' but failed", historyFilepath)
+	}
+	historyReportObj := &historyReport{}
+	if err := json.Unmarshal(historyFileContent, historyReportObj); err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred unmarshalling the audit history file '%s'", historyFilepath)
+	}
+
+	result := newResult(timestamp)
+	for _, packageName := range historyReportObj.Packages {
+		result.addCheckedPackage(types.PackageName(packageName))
+	}
+	for _, failure := range historyReportObj.Failures {
+		if failure.Severity == string(rules.SeverityError) {
+			result.addBrokenPackageFailure(types.PackageName(failure.Package), failure.Code)
+		}
+	}
+	return result, nil
+}
+
+func getHistoryFilename(timestamp time.Time) string {
+	return timestamp.UTC().Format(historyFileTimestampLayout) + historyFileExtension
+}
+
+func parseHistoryFilename(historyFilename string) (time.Time, error) {
+	timestampStr, found := strings.CutSuffix(historyFilename, historyFileExtension)
+	if !found {
+		return time.Time{}, stacktrace.NewError("expected the audit history file '%s' to have the '%s' extension", historyFilename, historyFileExtension)
+	}
+	timestamp, err := time.Parse(historyFileTimestampLayout, timestampStr)
+	if err != nil {
+		return time.Time{}, stacktrace.Propagate(err, "an error occurred parsing the timestamp of the audit history file '%s'", historyFilename)
+	}
+	return timestamp, nil
+}
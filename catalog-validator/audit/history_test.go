@@ -0,0 +1,99 @@
+package audit
+
+import (
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const (
+	testCatalogFilepath = "kurtosis-package-catalog.yml"
+)
+
+func TestHistory_SaveResult(t *testing.T) {
+	historyDirpath := filepath.Join(t.TempDir(), "history")
+	history := NewHistory(historyDirpath, testCatalogFilepath)
+	report := getTestReport(t, map[types.PackageName]string{
+		fooPackageName: validDescription,
+		barPackageName: shortDescription,
+	})
+
+	historyFilepath, err := history.SaveResult(testTimestamp, report)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(historyDirpath, "20240131T030000Z.json"), historyFilepath)
+
+	lastResult, err := history.GetLastResult()
+	require.NoError(t, err)
+	require.Equal(t, testTimestamp, lastResult.GetTimestamp())
+	require.Equal(t, map[types.PackageName]bool{fooPackageName: true, barPackageName: true}, lastResult.packageNames)
+	require.Equal(t, map[types.PackageName][]string{barPackageName: {descriptionTooShortCode}}, lastResult.brokenPackages)
+}
+
+func TestHistory_SaveResult_SameSecond(t *testing.T) {
+	history := NewHistory(t.TempDir(), testCatalogFilepath)
+	report := getTestReport(t, map[types.PackageName]string{fooPackageName: validDescription})
+
+	_, err := history.SaveResult(testTimestamp, report)
+	require.NoError(t, err)
+	_, err = history.SaveResult(testTimestamp.Add(500*time.Millisecond), report)
+	require.Error(t, err)
+}
+
+func TestHistory_GetLastResult(t *testing.T) {
+	testCases := []struct {
+		name                     string
+		historyFilenames         []string
+		expectedLastResultFound  bool
+		expectedLastResultBroken bool
+	}{
+		{
+			name:                    "empty history",
+			historyFilenames:        []string{},
+			expectedLastResultFound: false,
+		},
+		{
+			name:                    "history without audit results",
+			historyFilenames:        []string{"notes.json", "20240131T030000Z.txt"},
+			expectedLastResultFound: false,
+		},
+		{
+			name:                     "most recent result",
+			historyFilenames:         []string{"20240130T030000Z.json", "20240131T030000Z.json", "20240129T030000Z.json"},
+			expectedLastResultFound:  true,
+			expectedLastResultBroken: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			historyDirpath := t.TempDir()
+			for _, historyFilename := range testCase.historyFilenames {
+				// only the most recent result has a broken package
+				historyFileContent := `{"packages": ["github.com/foo/bar"], "failures": []}`
+				if historyFilename == "20240131T030000Z.json" {
+					historyFileContent = `{"packages": ["github.com/foo/bar"], "failures": [{"package": "github.com/foo/bar", "code": "DESCRIPTION_TOO_SHORT", "severity": "error"}, {"package": "github.com/foo/bar", "code": "ICON_NOT_FOUND", "severity": "warning"}]}`
+				}
+				require.NoError(t, os.WriteFile(filepath.Join(historyDirpath, historyFilename), []byte(historyFileContent), historyFilePerms))
+			}
+
+			lastResult, err := NewHistory(historyDirpath, testCatalogFilepath).GetLastResult()
+			require.NoError(t, err)
+			if !testCase.expectedLastResultFound {
+				require.Nil(t, lastResult)
+				return
+			}
+			require.Equal(t, testTimestamp, lastResult.GetTimestamp())
+			require.Equal(t, testCase.expectedLastResultBroken, lastResult.IsBroken(barPackageName))
+			require.Equal(t, []string{descriptionTooShortCode}, lastResult.GetFailureCodes(barPackageName))
+		})
+	}
+}
+
+func TestHistory_GetLastResult_MissingDirectory(t *testing.T) {
+	lastResult, err := NewHistory(filepath.Join(t.TempDir(), "missing"), testCatalogFilepath).GetLastResult()
+	require.NoError(t, err)
+	require.Nil(t, lastResult)
+}
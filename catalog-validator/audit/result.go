@@ -0,0 +1,119 @@
+package audit
+
+import (
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/validator"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+	"sort"
+	"time"
+)
+
+// Result is the outcome of an audit run, it's what is compared between runs
+type Result struct {
+	timestamp time.Time
+
+	// packageNames contains every package checked
+	packageNames map[types.PackageName]bool
+
+	// brokenPackages contains the failure codes of the packages with error failures
+	brokenPackages map[types.PackageName][]string
+}
+
+func newResult(timestamp time.Time) *Result {
+	return &Result{
+		timestamp:      timestamp,
+		packageNames:   map[types.PackageName]bool{},
+		brokenPackages: map[types.PackageName][]string{},
+	}
+}
+
+// NewResultFromReport returns the result of the audit run that produced the validator report
+func NewResultFromReport(timestamp time.Time, report *validator.Report) *Result {
+	result := newResult(timestamp)
+	for _, packageName := range report.GetPackageNames() {
+		result.addCheckedPackage(packageName)
+	}
+	for _, ruleName := range report.GetRuleNamesWithFailures() {
+		for _, packageName := range report.GetPackageNamesWithFailures(ruleName) {
+			for _, failure := range report.GetFailures(ruleName, packageName) {
+				if failure.IsError() {
+					result.addBrokenPackageFailure(packageName, string(failure.GetCode()))
+				}
+			}
+		}
+	}
+	return result
+}
+
+func (result *Result) GetTimestamp() time.Time {
+	return result.timestamp
+}
+
+// IsBroken returns true if the package had error failures in the run
+func (result *Result) IsBroken(packageName types.PackageName) bool {
+	_, found := result.brokenPackages[packageName]
+	return found
+}
+
+// GetFailureCodes returns the codes of the error failures of the package in the run
+func (result *Result) GetFailureCodes(packageName types.PackageName) []string {
+	return result.brokenPackages[packageName]
+}
+
+func (result *Result) addCheckedPackage(packageName types.PackageName) {
+	result.packageNames[packageName] = true
+}
+
+func (result *Result) addBrokenPackageFailure(packageName types.PackageName, failureCode string) {
+	result.addCheckedPackage(packageName)
+	result.brokenPackages[packageName] = append(result.brokenPackages[packageName], failureCode)
+}
+
+// ResultDiff contains the packages that changed their status between two audit runs
+type ResultDiff struct {
+	// newlyBrokenPackages are broken in the current run but they weren't in the previous one, it includes the
+	// broken packages that weren't checked in the previous run
+	newlyBrokenPackages []types.PackageName
+
+	// newlyFixedPackages were broken in the previous run and they are checked and not broken in the current one
+	newlyFixedPackages []types.PackageName
+}
+
+// CompareResults returns the packages that got broken or fixed since the previous run, all the broken packages
+// are newly broken if there isn't a previous run
+func CompareResults(previousResult *Result, currentResult *Result) *ResultDiff {
+	if previousResult == nil {
+		previousResult = newResult(time.Time{})
+	}
+
+	newlyBrokenPackages := []types.PackageName{}
+	for packageName := range currentResult.brokenPackages {
+		if !previousResult.IsBroken(packageName) {
+			newlyBrokenPackages = append(newlyBrokenPackages, packageName)
+		}
+	}
+
+	newlyFixedPackages := []types.PackageName{}
+	for packageName := range previousResult.brokenPackages {
+		if currentResult.packageNames[packageName] && !currentResult.IsBroken(packageName) {
+			newlyFixedPackages = append(newlyFixedPackages, packageName)
+		}
+	}
+
+	sortPackageNames(newlyBrokenPackages)
+	sortPackageNames(newlyFixedPackages)
+	return &ResultDiff{newlyBrokenPackages: newlyBrokenPackages, newlyFixedPackages: newlyFixedPackages}
+}
+
+func (resultDiff *ResultDiff) GetNewlyBrokenPackages() []types.PackageName {
+	return resultDiff.newlyBrokenPackages
+}
+
+func (resultDiff *ResultDiff) GetNewlyFixedPackages() []types.PackageName {
+	return resultDiff.newlyFixedPackages
+}
+
+func sortPackageNames(packageNames []types.PackageName) {
+	sort.Slice(packageNames, func(i, j int) bool {
+		return packageNames[i] < packageNames[j]
+	})
+}
@@ -0,0 +1,151 @@
+package audit
+
+import (
+	"context"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/source"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/rules"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/validator"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/consts"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testRepositoryOwner = "foo"
+
+	fooPackageName types.PackageName = "github.com/foo/foo"
+	barPackageName types.PackageName = "github.com/foo/bar"
+	bazPackageName types.PackageName = "github.com/foo/baz"
+
+	descriptionRuleName rules.RuleName = "Valid package description"
+
+	descriptionMissingCode  = "DESCRIPTION_MISSING"
+	descriptionTooShortCode = "DESCRIPTION_TOO_SHORT"
+
+	testParallelism = 2
+
+	validDescription = "Runs a Postgres database with a replica"
+	shortDescription = "Postgres"
+)
+
+var (
+	testTimestamp = time.Date(2024, time.January, 31, 3, 0, 0, 0, time.UTC)
+)
+
+func TestNewResultFromReport(t *testing.T) {
+	report := getTestReport(t, map[types.PackageName]string{
+		fooPackageName: validDescription,
+		barPackageName: shortDescription,
+		bazPackageName: "",
+	})
+
+	result := NewResultFromReport(testTimestamp, report)
+	require.Equal(t, testTimestamp, result.GetTimestamp())
+	require.Equal(t, map[types.PackageName]bool{fooPackageName: true, barPackageName: true, bazPackageName: true}, result.packageNames)
+	require.False(t, result.IsBroken(fooPackageName))
+	require.True(t, result.IsBroken(barPackageName))
+	require.Equal(t, []string{descriptionTooShortCode}, result.GetFailureCodes(barPackageName))
+	require.Equal(t, []string{descriptionMissingCode}, result.GetFailureCodes(bazPackageName))
+}
+
+func TestCompareResults(t *testing.T) {
+	testCases := []struct {
+		name                        string
+		previousResult              *Result
+		currentResult               *Result
+		expectedNewlyBrokenPackages []types.PackageName
+		expectedNewlyFixedPackages  []types.PackageName
+	}{
+		{
+			name:                        "first run",
+			previousResult:              nil,
+			currentResult:               newTestResult(map[types.PackageName][]string{fooPackageName: nil, barPackageName: {descriptionTooShortCode}}),
+			expectedNewlyBrokenPackages: []types.PackageName{barPackageName},
+			expectedNewlyFixedPackages:  []types.PackageName{},
+		},
+		{
+			name:                        "same broken packages",
+			previousResult:              newTestResult(map[types.PackageName][]string{fooPackageName: nil, barPackageName: {descriptionTooShortCode}}),
+			currentResult:               newTestResult(map[types.PackageName][]string{fooPackageName: nil, barPackageName: {descriptionMissingCode}}),
+			expectedNewlyBrokenPackages: []types.PackageName{},
+			expectedNewlyFixedPackages:  []types.PackageName{},
+		},
+		{
+			name:                        "broken and fixed packages",
+			previousResult:              newTestResult(map[types.PackageName][]string{fooPackageName: nil, barPackageName: {descriptionTooShortCode}, bazPackageName: {descriptionMissingCode}}),
+			currentResult:               newTestResult(map[types.PackageName][]string{fooPackageName: {descriptionTooShortCode}, barPackageName: nil, bazPackageName: {descriptionMissingCode}}),
+			expectedNewlyBrokenPackages: []types.PackageName{fooPackageName},
+			expectedNewlyFixedPackages:  []types.PackageName{barPackageName},
+		},
+		{
+			name:                        "broken package added to the catalog",
+			previousResult:              newTestResult(map[types.PackageName][]string{fooPackageName: nil}),
+			currentResult:               newTestResult(map[types.PackageName][]string{fooPackageName: nil, bazPackageName: {descriptionMissingCode}}),
+			expectedNewlyBrokenPackages: []types.PackageName{bazPackageName},
+			expectedNewlyFixedPackages:  []types.PackageName{},
+		},
+		{
+			name:                        "broken package removed from the catalog",
+			previousResult:              newTestResult(map[types.PackageName][]string{fooPackageName: nil, bazPackageName: {descriptionMissingCode}}),
+			currentResult:               newTestResult(map[types.PackageName][]string{fooPackageName: nil}),
+			expectedNewlyBrokenPackages: []types.PackageName{},
+			expectedNewlyFixedPackages:  []types.PackageName{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			resultDiff := CompareResults(testCase.previousResult, testCase.currentResult)
+			require.Equal(t, testCase.expectedNewlyBrokenPackages, resultDiff.GetNewlyBrokenPackages())
+			require.Equal(t, testCase.expectedNewlyFixedPackages, resultDiff.GetNewlyFixedPackages())
+		})
+	}
+}
+
+// newTestResult returns a result with the packages checked and the failure codes of the broken ones
+func newTestResult(failureCodes map[types.PackageName][]string) *Result {
+	result := newResult(testTimestamp)
+	for packageName, packageFailureCodes := range failureCodes {
+		result.addCheckedPackage(packageName)
+		for _, failureCode := range packageFailureCodes {
+			result.addBrokenPackageFailure(packageName, failureCode)
+		}
+	}
+	return result
+}
+
+// getTestReport returns the report of the 'Valid package description' rule over packages with the descriptions, the
+// packages with an empty description don't have one
+func getTestReport(t *testing.T, descriptions map[types.PackageName]string) *validator.Report {
+	packageSourceReader := source.NewInMemoryPackageSourceReader()
+	catalogYaml := &strings.Builder{}
+	catalogYaml.WriteString("packages:\n")
+	for _, packageName := range []types.PackageName{fooPackageName, barPackageName, bazPackageName} {
+		description, found := descriptions[packageName]
+		if !found {
+			continue
+		}
+		kurtosisYaml := "name: " + string(packageName) + "\n"
+		if description != "" {
+			kurtosisYaml += "description: " + description + "\n"
+		}
+		repositoryName := strings.TrimPrefix(string(packageName), "github.com/"+testRepositoryOwner+"/")
+		packageSourceReader.AddFile(testRepositoryOwner, repositoryName, consts.DefaultKurtosisYamlFilename, []byte(kurtosisYaml))
+		catalogYaml.WriteString("  - name: \"" + string(packageName) + "\"\n")
+	}
+	packageCatalog, err := catalog.GetPackageCatalogFromYamlFileContent([]byte(catalogYaml.String()))
+	require.NoError(t, err)
+
+	allRules, err := rules.GetAll(context.Background(), packageSourceReader, "", packageCatalog, nil)
+	require.NoError(t, err)
+	descriptionRules, err := rules.SelectRules(allRules, []string{string(descriptionRuleName)}, nil)
+	require.NoError(t, err)
+
+	report, err := validator.NewValidator(packageCatalog, descriptionRules, testParallelism, nil, nil, nil).Validate(context.Background())
+	require.NoError(t, err)
+	return report
+}
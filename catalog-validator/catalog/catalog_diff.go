@@ -0,0 +1,142 @@
+package catalog
+
+import (
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+	"strings"
+)
+
+// PackageChange is a package entry of the base catalog that was replaced by another entry in the new catalog
+type PackageChange struct {
+	basePackage *CatalogPackage
+	newPackage  *CatalogPackage
+}
+
+func newPackageChange(basePackage *CatalogPackage, newPackage *CatalogPackage) *PackageChange {
+	return &PackageChange{basePackage: basePackage, newPackage: newPackage}
+}
+
+func (packageChange *PackageChange) GetBasePackage() *CatalogPackage {
+	return packageChange.basePackage
+}
+
+func (packageChange *PackageChange) GetNewPackage() *CatalogPackage {
+	return packageChange.newPackage
+}
+
+// CatalogDiff contains the package entries added, removed, renamed and changed between a base catalog and a new one.
+// The duplicated packages are compared using their first entry
+type CatalogDiff struct {
+	// addedPackages are the packages of the new catalog that aren't in the base catalog and that aren't a rename
+	addedPackages PackageCatalog
+
+	// removedPackages are the packages of the base catalog that aren't in the new catalog and that aren't a rename
+	removedPackages PackageCatalog
+
+	// renamedPackages are the removed packages that were replaced by an added package of the same repository
+	renamedPackages []*PackageChange
+
+	// changedPackages are the packages in both catalogs whose entries have different fields
+	changedPackages []*PackageChange
+}
+
+// NewCatalogDiff compares both catalogs, the packages keep the order of the catalog they come from. A removed package
+// and an added one are considered a rename when they are in the same repository, comparing the owner and the name
+// without case because GitHub doesn't take it into account, e.g. 'github.com/foo/bar' renamed to 'github.com/Foo/bar/baz'.
+// The removed packages are paired with the added ones in the catalog order if there are several in the same repository
+func NewCatalogDiff(baseCatalog PackageCatalog, newCatalog PackageCatalog) *CatalogDiff {
+	basePackages := getFirstPackageEntries(baseCatalog)
+	newPackages := getFirstPackageEntries(newCatalog)
+
+	candidateAddedPackages := PackageCatalog{}
+	changedPackages := []*PackageChange{}
+	for _, newPackage := range newCatalog {
+		if newPackages[newPackage.GetPackageName()] != newPackage {
+			continue
+		}
+		basePackage, found := basePackages[newPackage.GetPackageName()]
+		if !found {
+			candidateAddedPackages = append(candidateAddedPackages, newPackage)
+			continue
+		}
+		if !basePackage.HasSameEntry(newPackage) {
+			changedPackages = append(changedPackages, newPackageChange(basePackage, newPackage))
+		}
+	}
+
+	// the added packages waiting to be paired with a removed one, by repository
+	candidateRenamedPackages := map[string]PackageCatalog{}
+	for _, addedPackage := range candidateAddedPackages {
+		repositoryKey := getCaseInsensitiveRepositoryKey(addedPackage)
+		candidateRenamedPackages[repositoryKey] = append(candidateRenamedPackages[repositoryKey], addedPackage)
+	}
+
+	removedPackages := PackageCatalog{}
+	renamedPackages := []*PackageChange{}
+	renamedNewPackages := map[*CatalogPackage]bool{}
+	for _, basePackage := range baseCatalog {
+		if basePackages[basePackage.GetPackageName()] != basePackage {
+			continue
+		}
+		if _, found := newPackages[basePackage.GetPackageName()]; found {
+			continue
+		}
+		repositoryKey := getCaseInsensitiveRepositoryKey(basePackage)
+		if sameRepositoryPackages := candidateRenamedPackages[repositoryKey]; len(sameRepositoryPackages) > 0 {
+			renamedPackages = append(renamedPackages, newPackageChange(basePackage, sameRepositoryPackages[0]))
+			renamedNewPackages[sameRepositoryPackages[0]] = true
+			candidateRenamedPackages[repositoryKey] = sameRepositoryPackages[1:]
+			continue
+		}
+		removedPackages = append(removedPackages, basePackage)
+	}
+
+	addedPackages := PackageCatalog{}
+	for _, candidateAddedPackage := range candidateAddedPackages {
+		if !renamedNewPackages[candidateAddedPackage] {
+			addedPackages = append(addedPackages, candidateAddedPackage)
+		}
+	}
+
+	return &CatalogDiff{
+		addedPackages:   addedPackages,
+		removedPackages: removedPackages,
+		renamedPackages: renamedPackages,
+		changedPackages: changedPackages,
+	}
+}
+
+func (catalogDiff *CatalogDiff) GetAddedPackages() PackageCatalog {
+	return catalogDiff.addedPackages
+}
+
+func (catalogDiff *CatalogDiff) GetRemovedPackages() PackageCatalog {
+	return catalogDiff.removedPackages
+}
+
+func (catalogDiff *CatalogDiff) GetRenamedPackages() []*PackageChange {
+	return catalogDiff.renamedPackages
+}
+
+func (catalogDiff *CatalogDiff) GetChangedPackages() []*PackageChange {
+	return catalogDiff.changedPackages
+}
+
+// IsEmpty returns true if both catalogs have the same package entries
+func (catalogDiff *CatalogDiff) IsEmpty() bool {
+	return len(catalogDiff.addedPackages) == 0 && len(catalogDiff.removedPackages) == 0 && len(catalogDiff.renamedPackages) == 0 && len(catalogDiff.changedPackages) == 0
+}
+
+// getFirstPackageEntries returns the first entry of each package in the catalog
+func getFirstPackageEntries(packageCatalog PackageCatalog) map[types.PackageName]*CatalogPackage {
+	firstPackageEntries := map[types.PackageName]*CatalogPackage{}
+	for _, catalogPackage := range packageCatalog {
+		if _, found := firstPackageEntries[catalogPackage.GetPackageName()]; !found {
+			firstPackageEntries[catalogPackage.GetPackageName()] = catalogPackage
+		}
+	}
+	return firstPackageEntries
+}
+
+func getCaseInsensitiveRepositoryKey(catalogPackage *CatalogPackage) string {
+	return strings.ToLower(catalogPackage.GetRepositoryOwner() + "/" + catalogPackage.GetRepositoryName())
+}
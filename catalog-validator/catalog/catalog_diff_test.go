@@ -0,0 +1,144 @@
+package catalog
+
+import (
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const (
+	renamedPackageSeparator = " -> "
+)
+
+func TestNewCatalogDiff(t *testing.T) {
+	testCases := []struct {
+		name                    string
+		baseCatalogYaml         string
+		newCatalogYaml          string
+		expectedAddedPackages   []types.PackageName
+		expectedRemovedPackages []types.PackageName
+		expectedRenamedPackages []string
+		expectedChangedPackages []types.PackageName
+	}{
+		{
+			name:                    "same catalogs",
+			baseCatalogYaml:         "packages:\n  - name: github.com/foo/bar\n  - name: github.com/foo/baz\n    ref: v1\n",
+			newCatalogYaml:          "packages:\n  - name: github.com/foo/bar\n  - name: github.com/foo/baz\n    ref: v1\n",
+			expectedAddedPackages:   []types.PackageName{},
+			expectedRemovedPackages: []types.PackageName{},
+			expectedRenamedPackages: []string{},
+			expectedChangedPackages: []types.PackageName{},
+		},
+		{
+			name:                    "added and removed packages of different repositories",
+			baseCatalogYaml:         "packages:\n  - name: github.com/foo/bar\n  - name: github.com/foo/old\n",
+			newCatalogYaml:          "packages:\n  - name: github.com/foo/new\n  - name: github.com/foo/bar\n",
+			expectedAddedPackages:   []types.PackageName{"github.com/foo/new"},
+			expectedRemovedPackages: []types.PackageName{"github.com/foo/old"},
+			expectedRenamedPackages: []string{},
+			expectedChangedPackages: []types.PackageName{},
+		},
+		{
+			name:                    "changed packages",
+			baseCatalogYaml:         "packages:\n  - name: github.com/foo/bar\n    ref: v1\n  - name: github.com/foo/baz\n",
+			newCatalogYaml:          "packages:\n  - name: github.com/foo/bar\n    ref: v2\n  - name: github.com/foo/baz\n    deprecated-since: \"2024-01-31\"\n",
+			expectedAddedPackages:   []types.PackageName{},
+			expectedRemovedPackages: []types.PackageName{},
+			expectedRenamedPackages: []string{},
+			expectedChangedPackages: []types.PackageName{"github.com/foo/bar", "github.com/foo/baz"},
+		},
+		{
+			name:                    "package moved in its repository",
+			baseCatalogYaml:         "packages:\n  - name: github.com/foo/bar\n",
+			newCatalogYaml:          "packages:\n  - name: github.com/foo/bar/baz\n",
+			expectedAddedPackages:   []types.PackageName{},
+			expectedRemovedPackages: []types.PackageName{},
+			expectedRenamedPackages: []string{"github.com/foo/bar -> github.com/foo/bar/baz"},
+			expectedChangedPackages: []types.PackageName{},
+		},
+		{
+			name:                    "repository compared without case",
+			baseCatalogYaml:         "packages:\n  - name: github.com/foo/bar\n  - name: github.com/foo/baz\n",
+			newCatalogYaml:          "packages:\n  - name: github.com/Foo/Bar/app\n  - name: github.com/FOO/baz\n",
+			expectedAddedPackages:   []types.PackageName{},
+			expectedRemovedPackages: []types.PackageName{},
+			expectedRenamedPackages: []string{"github.com/foo/bar -> github.com/Foo/Bar/app", "github.com/foo/baz -> github.com/FOO/baz"},
+			expectedChangedPackages: []types.PackageName{},
+		},
+		{
+			name:                    "several renames in the same repository paired in the catalog order",
+			baseCatalogYaml:         "packages:\n  - name: github.com/foo/bar/one\n  - name: github.com/foo/bar/two\n  - name: github.com/foo/bar/three\n",
+			newCatalogYaml:          "packages:\n  - name: github.com/foo/bar/uno\n  - name: github.com/foo/bar/dos\n",
+			expectedAddedPackages:   []types.PackageName{},
+			expectedRemovedPackages: []types.PackageName{"github.com/foo/bar/three"},
+			expectedRenamedPackages: []string{"github.com/foo/bar/one -> github.com/foo/bar/uno", "github.com/foo/bar/two -> github.com/foo/bar/dos"},
+			expectedChangedPackages: []types.PackageName{},
+		},
+		{
+			name:                    "more added packages than removed ones in the same repository",
+			baseCatalogYaml:         "packages:\n  - name: github.com/foo/bar/one\n",
+			newCatalogYaml:          "packages:\n  - name: github.com/foo/bar/uno\n  - name: github.com/foo/bar/dos\n",
+			expectedAddedPackages:   []types.PackageName{"github.com/foo/bar/dos"},
+			expectedRemovedPackages: []types.PackageName{},
+			expectedRenamedPackages: []string{"github.com/foo/bar/one -> github.com/foo/bar/uno"},
+			expectedChangedPackages: []types.PackageName{},
+		},
+		{
+			name:                    "duplicated packages compared using their first entry",
+			baseCatalogYaml:         "packages:\n  - name: github.com/foo/bar\n    ref: v1\n  - name: github.com/foo/bar\n    ref: v2\n  - name: github.com/foo/baz\n",
+			newCatalogYaml:          "packages:\n  - name: github.com/foo/bar\n    ref: v1\n  - name: github.com/foo/baz\n    ref: v1\n  - name: github.com/foo/baz\n",
+			expectedAddedPackages:   []types.PackageName{},
+			expectedRemovedPackages: []types.PackageName{},
+			expectedRenamedPackages: []string{},
+			expectedChangedPackages: []types.PackageName{"github.com/foo/baz"},
+		},
+		{
+			name:                    "duplicated removed package",
+			baseCatalogYaml:         "packages:\n  - name: github.com/foo/bar\n  - name: github.com/foo/bar\n",
+			newCatalogYaml:          "packages: []\n",
+			expectedAddedPackages:   []types.PackageName{},
+			expectedRemovedPackages: []types.PackageName{"github.com/foo/bar"},
+			expectedRenamedPackages: []string{},
+			expectedChangedPackages: []types.PackageName{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			baseCatalog, err := GetPackageCatalogFromYamlFileContent([]byte(testCase.baseCatalogYaml))
+			require.NoError(t, err)
+			newCatalog, err := GetPackageCatalogFromYamlFileContent([]byte(testCase.newCatalogYaml))
+			require.NoError(t, err)
+
+			catalogDiff := NewCatalogDiff(baseCatalog, newCatalog)
+			require.Equal(t, testCase.expectedAddedPackages, getPackageNames(catalogDiff.GetAddedPackages()))
+			require.Equal(t, testCase.expectedRemovedPackages, getPackageNames(catalogDiff.GetRemovedPackages()))
+			require.Equal(t, testCase.expectedRenamedPackages, getPackageChangeNames(catalogDiff.GetRenamedPackages()))
+			changedPackageNames := []types.PackageName{}
+			for _, changedPackage := range catalogDiff.GetChangedPackages() {
+				require.Equal(t, changedPackage.GetBasePackage().GetPackageName(), changedPackage.GetNewPackage().GetPackageName())
+				changedPackageNames = append(changedPackageNames, changedPackage.GetNewPackage().GetPackageName())
+			}
+			require.Equal(t, testCase.expectedChangedPackages, changedPackageNames)
+			isEmpty := len(testCase.expectedAddedPackages)+len(testCase.expectedRemovedPackages)+len(testCase.expectedRenamedPackages)+len(testCase.expectedChangedPackages) == 0
+			require.Equal(t, isEmpty, catalogDiff.IsEmpty())
+		})
+	}
+}
+
+func getPackageNames(packageCatalog PackageCatalog) []types.PackageName {
+	packageNames := []types.PackageName{}
+	for _, catalogPackage := range packageCatalog {
+		packageNames = append(packageNames, catalogPackage.GetPackageName())
+	}
+	return packageNames
+}
+
+// getPackageChangeNames returns each change as 'base package name -> new package name'
+func getPackageChangeNames(packageChanges []*PackageChange) []string {
+	packageChangeNames := []string{}
+	for _, packageChange := range packageChanges {
+		packageChangeNames = append(packageChangeNames, string(packageChange.GetBasePackage().GetPackageName())+renamedPackageSeparator+string(packageChange.GetNewPackage().GetPackageName()))
+	}
+	return packageChangeNames
+}
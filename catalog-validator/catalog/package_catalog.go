@@ -0,0 +1,210 @@
+package catalog
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/yamlnode"
+	indexercatalog "github.com/kurtosis-tech/kurtosis-package-indexer/server/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+	"github.com/kurtosis-tech/stacktrace"
+	"gopkg.in/yaml.v3"
+	"strings"
+)
+
+const (
+	packagesKey    = "packages"
+	packageNameKey = "name"
+
+	// PackageRefKey is the optional entry field with the branch, tag or commit SHA the package is read at, the
+	// repository default branch is read if it's not set
+	PackageRefKey = "ref"
+
+	// PackageRefSHAKey is the optional entry field with the commit SHA the ref resolved to when it was added, so a
+	// moved tag can be detected
+	PackageRefSHAKey = "ref-sha"
+
+	fileLinesSeparator = "\n"
+)
+
+// PackageCatalog contains the packages of a catalog file, in the same order they are in the file
+type PackageCatalog []*CatalogPackage
+
+// CatalogPackage is a package of the catalog, it keeps the position of its entry in the catalog file so the
+// failures can point to it
+type CatalogPackage struct {
+	// packageData is the package read by the package indexer, so the package names are resolved to repositories
+	// the same way the indexer does it
+	packageData indexerPackageData
+
+	// entryFields contains all the fields of the package entry in the catalog file, including the name
+	entryFields map[string]string
+
+	// line and column are the position of the package name value in the catalog file, they start at 1
+	line   int
+	column int
+}
+
+// indexerPackageData is implemented by the packages of the indexer catalog, its type is not exported
+type indexerPackageData interface {
+	GetPackageName() types.PackageName
+	GetRepositoryOwner() string
+	GetRepositoryName() string
+	GetRepositoryPackageRootPath() string
+}
+
+// GetPackageCatalogFromYamlFileContent receives the kurtosis-package-catalog.yml file content and returns the
+// catalog packages with the position of their entries in the file
+func GetPackageCatalogFromYamlFileContent(fileContent []byte) (PackageCatalog, error) {
+	indexerCatalog, err := indexercatalog.GetPackageCatalogFromYamlFileContent(fileContent)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred reading the package catalog file content")
+	}
+
+	packageEntryNodes, err := getPackageEntryNodes(fileContent)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred getting the package entries in the catalog file content")
+	}
+	if len(packageEntryNodes) != len(indexerCatalog) {
+		return nil, stacktrace.NewError("expected to find '%d' package entries in the catalog file content, but '%d' were found", len(indexerCatalog), len(packageEntryNodes))
+	}
+
+	packageCatalog := make(PackageCatalog, len(indexerCatalog))
+	for packageIndex := range indexerCatalog {
+		packageEntryNode := packageEntryNodes[packageIndex]
+		entryFields := map[string]string{}
+		if err := packageEntryNode.Decode(&entryFields); err != nil {
+			return nil, stacktrace.Propagate(err, "an error occurred decoding the package entry in line '%d' of the catalog file content", packageEntryNode.Line)
+		}
+		packageNameNode := yamlnode.FindMappingValueNode(packageEntryNode, packageNameKey)
+		if packageNameNode == nil {
+			return nil, stacktrace.NewError("expected to find key '%s' in the package entry in line '%d' of the catalog file content, but it was not found", packageNameKey, packageEntryNode.Line)
+		}
+		packageCatalog[packageIndex] = &CatalogPackage{
+			packageData: &indexerCatalog[packageIndex],
+			entryFields: entryFields,
+			line:        packageNameNode.Line,
+			column:      packageNameNode.Column,
+		}
+	}
+	return packageCatalog, nil
+}
+
+func (catalogPackage *CatalogPackage) GetPackageName() types.PackageName {
+	return catalogPackage.packageData.GetPackageName()
+}
+
+func (catalogPackage *CatalogPackage) GetRepositoryOwner() string {
+	return catalogPackage.packageData.GetRepositoryOwner()
+}
+
+func (catalogPackage *CatalogPackage) GetRepositoryName() string {
+	return catalogPackage.packageData.GetRepositoryName()
+}
+
+func (catalogPackage *CatalogPackage) GetRepositoryPackageRootPath() string {
+	return catalogPackage.packageData.GetRepositoryPackageRootPath()
+}
+
+// GetRef returns the branch, tag or commit SHA of the package repository set in the catalog entry, it's empty if it
+// isn't set, which means the repository default branch
+func (catalogPackage *CatalogPackage) GetRef() string {
+	return catalogPackage.entryFields[PackageRefKey]
+}
+
+// GetRefSHA returns the commit SHA the ref was recorded to resolve to in the catalog entry, it's empty if it isn't set
+func (catalogPackage *CatalogPackage) GetRefSHA() string {
+	return catalogPackage.entryFields[PackageRefSHAKey]
+}
+
+// GetCatalogLine returns the line of the package entry in the catalog file
+func (catalogPackage *CatalogPackage) GetCatalogLine() int {
+	return catalogPackage.line
+}
+
+// GetCatalogColumn returns the column of the package name in the catalog file
+func (catalogPackage *CatalogPackage) GetCatalogColumn() int {
+	return catalogPackage.column
+}
+
+// GetEntryField returns the value of a field of the package entry in the catalog file, e.g. 'name'
+func (catalogPackage *CatalogPackage) GetEntryField(key string) (string, bool) {
+	value, found := catalogPackage.entryFields[key]
+	return value, found
+}
+
+// HasSameEntry returns true if both package entries have the same fields with the same values
+func (catalogPackage *CatalogPackage) HasSameEntry(otherCatalogPackage *CatalogPackage) bool {
+	if len(catalogPackage.entryFields) != len(otherCatalogPackage.entryFields) {
+		return false
+	}
+	for key, value := range catalogPackage.entryFields {
+		if otherValue, found := otherCatalogPackage.entryFields[key]; !found || otherValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+// AddPackageRefSHAs receives the kurtosis-package-catalog.yml file content and returns it with the 'ref-sha' field
+// added to the package entries, keyed by their index in the catalog. Each field is added in the line after the entry
+// 'ref' field, so the rest of the file, including its comments, isn't changed
+func AddPackageRefSHAs(fileContent []byte, refSHAs map[int]string) ([]byte, error) {
+	packageEntryNodes, err := getPackageEntryNodes(fileContent)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred getting the package entries in the catalog file content")
+	}
+
+	// the 'ref-sha' lines keyed by the number of the line they are added after
+	refSHALinesAfterLines := map[int]string{}
+	for packageIndex, refSHA := range refSHAs {
+		if packageIndex < 0 || packageIndex >= len(packageEntryNodes) {
+			return nil, stacktrace.NewError("expected the package index to be between '0' and '%d', but it's '%d'", len(packageEntryNodes)-1, packageIndex)
+		}
+		packageEntryNode := packageEntryNodes[packageIndex]
+		if packageEntryNode.Kind != yaml.MappingNode || packageEntryNode.Style&yaml.FlowStyle != 0 {
+			return nil, stacktrace.NewError("expected the package entry in line '%d' of the catalog file content to be a block mapping to add the '%s' field to it", packageEntryNode.Line, PackageRefSHAKey)
+		}
+		if yamlnode.FindMappingValueNode(packageEntryNode, PackageRefSHAKey) != nil {
+			return nil, stacktrace.NewError("expected the package entry in line '%d' of the catalog file content to not have the '%s' field, but it has it", packageEntryNode.Line, PackageRefSHAKey)
+		}
+		refNode := yamlnode.FindMappingValueNode(packageEntryNode, PackageRefKey)
+		if refNode == nil || refNode.Kind != yaml.ScalarNode {
+			return nil, stacktrace.NewError("expected the package entry in line '%d' of the catalog file content to have a '%s' field, as the '%s' field records the commit it resolves to", packageEntryNode.Line, PackageRefKey, PackageRefSHAKey)
+		}
+		// the entry fields are aligned with the column of its first key
+		refSHAIndentation := strings.Repeat(" ", packageEntryNode.Content[0].Column-1)
+		refSHALinesAfterLines[refNode.Line] = fmt.Sprintf("%s%s: %q", refSHAIndentation, PackageRefSHAKey, refSHA)
+	}
+
+	fileLines := strings.SplitAfter(string(fileContent), fileLinesSeparator)
+	newFileContent := &strings.Builder{}
+	for lineIndex, fileLine := range fileLines {
+		newFileContent.WriteString(fileLine)
+		refSHALine, found := refSHALinesAfterLines[lineIndex+1]
+		if !found {
+			continue
+		}
+		if !strings.HasSuffix(fileLine, fileLinesSeparator) {
+			newFileContent.WriteString(fileLinesSeparator)
+		}
+		newFileContent.WriteString(refSHALine + fileLinesSeparator)
+	}
+	return []byte(newFileContent.String()), nil
+}
+
+// getPackageEntryNodes returns the YAML mapping node of every package entry, in the same order they are in the file
+func getPackageEntryNodes(fileContent []byte) ([]*yaml.Node, error) {
+	documentNode := &yaml.Node{}
+	if err := yaml.Unmarshal(fileContent, documentNode); err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred unmarshalling the package catalog file content")
+	}
+
+	packagesNode := yamlnode.FindMappingValueNode(documentNode, packagesKey)
+	if packagesNode == nil {
+		return []*yaml.Node{}, nil
+	}
+	if packagesNode.Kind != yaml.SequenceNode {
+		return nil, stacktrace.NewError("expected '%s' to be a list in the catalog file, but it's in line '%d'", packagesKey, packagesNode.Line)
+	}
+
+	return packagesNode.Content, nil
+}
@@ -0,0 +1,95 @@
+package catalog
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const (
+	testRefSHA      = "0123456789abcdef0123456789abcdef01234567"
+	otherTestRefSHA = "89abcdef0123456789abcdef0123456789abcdef"
+)
+
+func TestAddPackageRefSHAs(t *testing.T) {
+	testCases := []struct {
+		name                string
+		catalogYaml         string
+		refSHAs             map[int]string
+		expectedCatalogYaml string
+	}{
+		{
+			name:                "field added after the ref",
+			catalogYaml:         "# the packages\npackages:\n  - name: github.com/foo/bar\n    ref: v1 # the first release\n    description: the bar package\n  - name: github.com/foo/baz\n",
+			refSHAs:             map[int]string{0: testRefSHA},
+			expectedCatalogYaml: "# the packages\npackages:\n  - name: github.com/foo/bar\n    ref: v1 # the first release\n    ref-sha: \"" + testRefSHA + "\"\n    description: the bar package\n  - name: github.com/foo/baz\n",
+		},
+		{
+			name:                "several entries with other indentation",
+			catalogYaml:         "packages:\n- name: github.com/foo/bar\n  ref: v1\n- name: github.com/foo/baz\n- ref: v2\n  name: github.com/foo/qux\n",
+			refSHAs:             map[int]string{0: testRefSHA, 2: otherTestRefSHA},
+			expectedCatalogYaml: "packages:\n- name: github.com/foo/bar\n  ref: v1\n  ref-sha: \"" + testRefSHA + "\"\n- name: github.com/foo/baz\n- ref: v2\n  ref-sha: \"" + otherTestRefSHA + "\"\n  name: github.com/foo/qux\n",
+		},
+		{
+			name:                "ref in the last line without newline",
+			catalogYaml:         "packages:\n  - name: github.com/foo/bar\n    ref: v1",
+			refSHAs:             map[int]string{0: testRefSHA},
+			expectedCatalogYaml: "packages:\n  - name: github.com/foo/bar\n    ref: v1\n    ref-sha: \"" + testRefSHA + "\"\n",
+		},
+		{
+			name:                "no fields to add",
+			catalogYaml:         "packages:\n  - name: github.com/foo/bar\n    ref: v1\n",
+			refSHAs:             map[int]string{},
+			expectedCatalogYaml: "packages:\n  - name: github.com/foo/bar\n    ref: v1\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			newCatalogYaml, err := AddPackageRefSHAs([]byte(testCase.catalogYaml), testCase.refSHAs)
+			require.NoError(t, err)
+			require.Equal(t, testCase.expectedCatalogYaml, string(newCatalogYaml))
+
+			packageCatalog, err := GetPackageCatalogFromYamlFileContent(newCatalogYaml)
+			require.NoError(t, err)
+			for packageIndex, refSHA := range testCase.refSHAs {
+				require.Equal(t, refSHA, packageCatalog[packageIndex].GetRefSHA())
+			}
+		})
+	}
+}
+
+func TestAddPackageRefSHAs_Error(t *testing.T) {
+	testCases := []struct {
+		name        string
+		catalogYaml string
+		refSHAs     map[int]string
+	}{
+		{
+			name:        "entry without ref",
+			catalogYaml: "packages:\n  - name: github.com/foo/bar\n",
+			refSHAs:     map[int]string{0: testRefSHA},
+		},
+		{
+			name:        "entry with a ref-sha",
+			catalogYaml: "packages:\n  - name: github.com/foo/bar\n    ref: v1\n    ref-sha: \"" + otherTestRefSHA + "\"\n",
+			refSHAs:     map[int]string{0: testRefSHA},
+		},
+		{
+			name:        "flow entry",
+			catalogYaml: "packages:\n  - {name: github.com/foo/bar, ref: v1}\n",
+			refSHAs:     map[int]string{0: testRefSHA},
+		},
+		{
+			name:        "package index out of range",
+			catalogYaml: "packages:\n  - name: github.com/foo/bar\n    ref: v1\n",
+			refSHAs:     map[int]string{1: testRefSHA},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := AddPackageRefSHAs([]byte(testCase.catalogYaml), testCase.refSHAs)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package dependencygraph
+
+import (
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+	"sort"
+)
+
+// DependencyGraph is the graph of the packages and the packages they depend on, the dependencies that aren't in the
+// catalog are also in the graph so they can be reported. It isn't safe for concurrent use, the queries cache what they
+// compute
+type DependencyGraph struct {
+	packages map[types.PackageName]*packageNode
+
+	// components, componentIdxs and longestChains are computed on the first query that needs them and reset when a
+	// package or a dependency is added, so querying every package computes them only once
+	components    [][]types.PackageName
+	componentIdxs map[types.PackageName]int
+	longestChains map[types.PackageName][]types.PackageName
+}
+
+type packageNode struct {
+	isInCatalog  bool
+	dependencies map[types.PackageName]bool
+}
+
+func NewDependencyGraph() *DependencyGraph {
+	return &DependencyGraph{packages: map[types.PackageName]*packageNode{}, components: nil, componentIdxs: nil, longestChains: nil}
+}
+
+// AddPackage adds the package to the graph, a package added as a dependency is marked as in the catalog if it's added
+// again as a catalog package
+func (graph *DependencyGraph) AddPackage(packageName types.PackageName, isInCatalog bool) {
+	node, found := graph.packages[packageName]
+	if !found {
+		graph.packages[packageName] = &packageNode{isInCatalog: isInCatalog, dependencies: map[types.PackageName]bool{}}
+		graph.resetComponents()
+		return
+	}
+	node.isInCatalog = node.isInCatalog || isInCatalog
+}
+
+// AddDependency adds the dependency of the package, the dependency is added as a package outside the catalog if it
+// isn't in the graph yet
+func (graph *DependencyGraph) AddDependency(packageName types.PackageName, dependency types.PackageName) {
+	graph.AddPackage(packageName, false)
+	graph.AddPackage(dependency, false)
+	graph.packages[packageName].dependencies[dependency] = true
+	graph.resetComponents()
+}
+
+func (graph *DependencyGraph) HasPackage(packageName types.PackageName) bool {
+	_, found := graph.packages[packageName]
+	return found
+}
+
+func (graph *DependencyGraph) IsInCatalog(packageName types.PackageName) bool {
+	node, found := graph.packages[packageName]
+	return found && node.isInCatalog
+}
+
+// GetPackageNames returns the names of all the packages in the graph, sorted
+func (graph *DependencyGraph) GetPackageNames() []types.PackageName {
+	packageNames := []types.PackageName{}
+	for packageName := range graph.packages {
+		packageNames = append(packageNames, packageName)
+	}
+	sortPackageNames(packageNames)
+	return packageNames
+}
+
+// GetDependencies returns the packages the package depends on directly, sorted
+func (graph *DependencyGraph) GetDependencies(packageName types.PackageName) []types.PackageName {
+	dependencies := []types.PackageName{}
+	node, found := graph.packages[packageName]
+	if !found {
+		return dependencies
+	}
+	for dependency := range node.dependencies {
+		dependencies = append(dependencies, dependency)
+	}
+	sortPackageNames(dependencies)
+	return dependencies
+}
+
+// FindCycles returns the cycles of the graph, each one as the packages in the cycle starting and ending with the
+// first of them by name, e.g. [a, b, a]. There is one cycle for each group of packages depending on each other
+func (graph *DependencyGraph) FindCycles() [][]types.PackageName {
+	cycles := [][]types.PackageName{}
+	for _, component := range graph.getComponents() {
+		firstPackageName := component[0]
+		if len(component) == 1 && !graph.packages[firstPackageName].dependencies[firstPackageName] {
+			continue
+		}
+		cycles = append(cycles, graph.findCyclePath(firstPackageName, component))
+	}
+	sort.Slice(cycles, func(i, j int) bool {
+		return cycles[i][0] < cycles[j][0]
+	})
+	return cycles
+}
+
+// GetCycle returns a cycle of dependencies going through the package, starting and ending with it, or an empty list if
+// the package isn't in any cycle
+func (graph *DependencyGraph) GetCycle(packageName types.PackageName) []types.PackageName {
+	if !graph.HasPackage(packageName) {
+		return []types.PackageName{}
+	}
+	component := graph.getComponents()[graph.getComponentIdxs()[packageName]]
+	if len(component) == 1 && !graph.packages[packageName].dependencies[packageName] {
+		return []types.PackageName{}
+	}
+	return graph.findCyclePath(packageName, component)
+}
+
+// GetLongestDependencyChain returns the longest chain of dependencies starting from the package, e.g. [a, b, c] if a
+// depends on b which depends on c. The dependencies between packages in the same cycle aren't followed, so the chain
+// is finite
+func (graph *DependencyGraph) GetLongestDependencyChain(packageName types.PackageName) []types.PackageName {
+	if !graph.HasPackage(packageName) {
+		return []types.PackageName{}
+	}
+	if graph.longestChains == nil {
+		graph.longestChains = map[types.PackageName][]types.PackageName{}
+	}
+	return graph.getLongestDependencyChain(packageName, graph.getComponentIdxs(), graph.longestChains)
+}
+
+func (graph *DependencyGraph) getLongestDependencyChain(packageName types.PackageName, componentIdxs map[types.PackageName]int, longestChains map[types.PackageName][]types.PackageName) []types.PackageName {
+	if longestChain, found := longestChains[packageName]; found {
+		return longestChain
+	}
+	longestDependencyChain := []types.PackageName{}
+	for _, dependency := range graph.GetDependencies(packageName) {
+		if componentIdxs[dependency] == componentIdxs[packageName] {
+			continue
+		}
+		dependencyChain := graph.getLongestDependencyChain(dependency, componentIdxs, longestChains)
+		if len(dependencyChain) > len(longestDependencyChain) {
+			longestDependencyChain = dependencyChain
+		}
+	}
+	longestChain := append([]types.PackageName{packageName}, longestDependencyChain...)
+	longestChains[packageName] = longestChain
+	return longestChain
+}
+
+// getComponents returns the strongly connected components of the graph, computing them if the graph changed since the
+// last query
+func (graph *DependencyGraph) getComponents() [][]types.PackageName {
+	if graph.components == nil {
+		graph.components = graph.getStronglyConnectedComponents()
+	}
+	return graph.components
+}
+
+// getComponentIdxs returns the index of the component of each package in the list returned by getComponents
+func (graph *DependencyGraph) getComponentIdxs() map[types.PackageName]int {
+	if graph.componentIdxs == nil {
+		graph.componentIdxs = map[types.PackageName]int{}
+		for componentIdx, component := range graph.getComponents() {
+			for _, componentPackageName := range component {
+				graph.componentIdxs[componentPackageName] = componentIdx
+			}
+		}
+	}
+	return graph.componentIdxs
+}
+
+// resetComponents drops the components and the longest chains computed so far, it's called when the graph changes
+func (graph *DependencyGraph) resetComponents() {
+	graph.components = nil
+	graph.componentIdxs = nil
+	graph.longestChains = nil
+}
+
+// getStronglyConnectedComponents returns the groups of packages that depend on each other with Tarjan's algorithm,
+// each group is sorted by package name
+func (graph *DependencyGraph) getStronglyConnectedComponents() [][]types.PackageName {
+	nextIdx := 0
+	idxs := map[types.PackageName]int{}
+	lowLinks := map[types.PackageName]int{}
+	isOnStack := map[types.PackageName]bool{}
+	stack := []types.PackageName{}
+	components := [][]types.PackageName{}
+
+	var visit func(packageName types.PackageName)
+	visit = func(packageName types.PackageName) {
+		idxs[packageName] = nextIdx
+		lowLinks[packageName] = nextIdx
+		nextIdx++
+		stack = append(stack, packageName)
+		isOnStack[packageName] = true
+
+		for _, dependency := range graph.GetDependencies(packageName) {
+			if _, isVisited := idxs[dependency]; !isVisited {
+				visit(dependency)
+				lowLinks[packageName] = minInt(lowLinks[packageName], lowLinks[dependency])
+			} else if isOnStack[dependency] {
+				lowLinks[packageName] = minInt(lowLinks[packageName], idxs[dependency])
+			}
+		}
+
+		if lowLinks[packageName] != idxs[packageName] {
+			return
+		}
+		component := []types.PackageName{}
+		for {
+			componentPackageName := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			isOnStack[componentPackageName] = false
+			component = append(component, componentPackageName)
+			if componentPackageName == packageName {
+				break
+			}
+		}
+		sortPackageNames(component)
+		components = append(components, component)
+	}
+
+	for _, packageName := range graph.GetPackageNames() {
+		if _, isVisited := idxs[packageName]; !isVisited {
+			visit(packageName)
+		}
+	}
+	return components
+}
+
+// findCyclePath returns the shortest path from the package back to itself through the packages of its component
+func (graph *DependencyGraph) findCyclePath(packageName types.PackageName, component []types.PackageName) []types.PackageName {
+	isInComponent := map[types.PackageName]bool{}
+	for _, componentPackageName := range component {
+		isInComponent[componentPackageName] = true
+	}
+
+	previousPackageNames := map[types.PackageName]types.PackageName{}
+	pendingPackageNames := []types.PackageName{packageName}
+	for len(pendingPackageNames) > 0 {
+		currentPackageName := pendingPackageNames[0]
+		pendingPackageNames = pendingPackageNames[1:]
+		for _, dependency := range graph.GetDependencies(currentPackageName) {
+			if !isInComponent[dependency] {
+				continue
+			}
+			if dependency == packageName {
+				cyclePath := []types.PackageName{packageName}
+				for pathPackageName := currentPackageName; pathPackageName != packageName; pathPackageName = previousPackageNames[pathPackageName] {
+					cyclePath = append([]types.PackageName{pathPackageName}, cyclePath...)
+				}
+				return append([]types.PackageName{packageName}, cyclePath...)
+			}
+			if _, isVisited := previousPackageNames[dependency]; !isVisited {
+				previousPackageNames[dependency] = currentPackageName
+				pendingPackageNames = append(pendingPackageNames, dependency)
+			}
+		}
+	}
+	return []types.PackageName{packageName, packageName}
+}
+
+func sortPackageNames(packageNames []types.PackageName) {
+	sort.Slice(packageNames, func(i, j int) bool {
+		return packageNames[i] < packageNames[j]
+	})
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
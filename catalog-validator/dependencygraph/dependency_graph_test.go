@@ -0,0 +1,170 @@
+package dependencygraph
+
+import (
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const (
+	packageA types.PackageName = "github.com/owner/a"
+	packageB types.PackageName = "github.com/owner/b"
+	packageC types.PackageName = "github.com/owner/c"
+	packageD types.PackageName = "github.com/owner/d"
+
+	// packageOutsideCatalog is only added to the graph as a dependency
+	packageOutsideCatalog types.PackageName = "github.com/other/x"
+)
+
+func TestDependencyGraph_GetCycleAndLongestDependencyChain(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		dependencies         map[types.PackageName][]types.PackageName
+		packageName          types.PackageName
+		expectedCycle        []types.PackageName
+		expectedLongestChain []types.PackageName
+	}{
+		{
+			name:                 "package without dependencies",
+			dependencies:         map[types.PackageName][]types.PackageName{packageA: {}},
+			packageName:          packageA,
+			expectedCycle:        []types.PackageName{},
+			expectedLongestChain: []types.PackageName{packageA},
+		},
+		{
+			name:                 "longest of several chains",
+			dependencies:         map[types.PackageName][]types.PackageName{packageA: {packageB, packageC}, packageB: {packageC}, packageC: {}},
+			packageName:          packageA,
+			expectedCycle:        []types.PackageName{},
+			expectedLongestChain: []types.PackageName{packageA, packageB, packageC},
+		},
+		{
+			name:                 "package depending on itself",
+			dependencies:         map[types.PackageName][]types.PackageName{packageA: {packageA}},
+			packageName:          packageA,
+			expectedCycle:        []types.PackageName{packageA, packageA},
+			expectedLongestChain: []types.PackageName{packageA},
+		},
+		{
+			name:                 "chain skipping the dependencies of the cycle",
+			dependencies:         map[types.PackageName][]types.PackageName{packageA: {packageB}, packageB: {packageA, packageC}, packageC: {}},
+			packageName:          packageB,
+			expectedCycle:        []types.PackageName{packageB, packageA, packageB},
+			expectedLongestChain: []types.PackageName{packageB, packageC},
+		},
+		{
+			name:                 "shortest cycle through the package",
+			dependencies:         map[types.PackageName][]types.PackageName{packageA: {packageB}, packageB: {packageA, packageC}, packageC: {packageA}},
+			packageName:          packageA,
+			expectedCycle:        []types.PackageName{packageA, packageB, packageA},
+			expectedLongestChain: []types.PackageName{packageA},
+		},
+		{
+			name:                 "longer cycle through the package",
+			dependencies:         map[types.PackageName][]types.PackageName{packageA: {packageB}, packageB: {packageA, packageC}, packageC: {packageA}},
+			packageName:          packageC,
+			expectedCycle:        []types.PackageName{packageC, packageA, packageB, packageC},
+			expectedLongestChain: []types.PackageName{packageC},
+		},
+		{
+			name:                 "package depending on a cycle",
+			dependencies:         map[types.PackageName][]types.PackageName{packageA: {packageB}, packageB: {packageC}, packageC: {packageB, packageD}, packageD: {}},
+			packageName:          packageA,
+			expectedCycle:        []types.PackageName{},
+			expectedLongestChain: []types.PackageName{packageA, packageB},
+		},
+		{
+			name:                 "dependency outside the catalog",
+			dependencies:         map[types.PackageName][]types.PackageName{packageA: {packageOutsideCatalog}},
+			packageName:          packageA,
+			expectedCycle:        []types.PackageName{},
+			expectedLongestChain: []types.PackageName{packageA, packageOutsideCatalog},
+		},
+		{
+			name:                 "package not in the graph",
+			dependencies:         map[types.PackageName][]types.PackageName{packageA: {}},
+			packageName:          packageB,
+			expectedCycle:        []types.PackageName{},
+			expectedLongestChain: []types.PackageName{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			graph := newTestDependencyGraph(testCase.dependencies)
+			require.Equal(t, testCase.expectedCycle, graph.GetCycle(testCase.packageName))
+			require.Equal(t, testCase.expectedLongestChain, graph.GetLongestDependencyChain(testCase.packageName))
+		})
+	}
+}
+
+func TestDependencyGraph_FindCycles(t *testing.T) {
+	testCases := []struct {
+		name           string
+		dependencies   map[types.PackageName][]types.PackageName
+		expectedCycles [][]types.PackageName
+	}{
+		{
+			name:           "no cycles",
+			dependencies:   map[types.PackageName][]types.PackageName{packageA: {packageB}, packageB: {packageOutsideCatalog}},
+			expectedCycles: [][]types.PackageName{},
+		},
+		{
+			name:           "cycles sorted by their first package",
+			dependencies:   map[types.PackageName][]types.PackageName{packageD: {packageC}, packageC: {packageD}, packageB: {packageA}, packageA: {packageB, packageC}},
+			expectedCycles: [][]types.PackageName{{packageA, packageB, packageA}, {packageC, packageD, packageC}},
+		},
+		{
+			name:           "one cycle for each group of packages",
+			dependencies:   map[types.PackageName][]types.PackageName{packageA: {packageB, packageC}, packageB: {packageA}, packageC: {packageA}},
+			expectedCycles: [][]types.PackageName{{packageA, packageB, packageA}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			graph := newTestDependencyGraph(testCase.dependencies)
+			require.Equal(t, testCase.expectedCycles, graph.FindCycles())
+		})
+	}
+}
+
+func TestDependencyGraph_IsInCatalog(t *testing.T) {
+	graph := newTestDependencyGraph(map[types.PackageName][]types.PackageName{packageA: {packageOutsideCatalog, packageB}, packageB: {}})
+	require.True(t, graph.IsInCatalog(packageA))
+	require.True(t, graph.IsInCatalog(packageB))
+	require.True(t, graph.HasPackage(packageOutsideCatalog))
+	require.False(t, graph.IsInCatalog(packageOutsideCatalog))
+	require.Equal(t, []types.PackageName{packageOutsideCatalog, packageA, packageB}, graph.GetPackageNames())
+
+	// a dependency added later as a catalog package is in the catalog
+	graph.AddPackage(packageOutsideCatalog, true)
+	require.True(t, graph.IsInCatalog(packageOutsideCatalog))
+}
+
+func TestDependencyGraph_QueriesAfterAddingDependency(t *testing.T) {
+	graph := newTestDependencyGraph(map[types.PackageName][]types.PackageName{packageA: {packageB}, packageB: {}})
+	require.Empty(t, graph.GetCycle(packageA))
+	require.Equal(t, []types.PackageName{packageA, packageB}, graph.GetLongestDependencyChain(packageA))
+
+	graph.AddDependency(packageB, packageC)
+	graph.AddDependency(packageC, packageB)
+	require.Equal(t, []types.PackageName{packageB, packageC, packageB}, graph.GetCycle(packageB))
+	require.Equal(t, []types.PackageName{packageA, packageB}, graph.GetLongestDependencyChain(packageA))
+	require.Equal(t, [][]types.PackageName{{packageB, packageC, packageB}}, graph.FindCycles())
+}
+
+// newTestDependencyGraph returns a graph with the packages in the catalog and their dependencies, the dependencies that
+// aren't keys of the map are outside the catalog
+func newTestDependencyGraph(dependencies map[types.PackageName][]types.PackageName) *DependencyGraph {
+	graph := NewDependencyGraph()
+	for packageName := range dependencies {
+		graph.AddPackage(packageName, true)
+	}
+	for packageName, packageDependencies := range dependencies {
+		for _, dependency := range packageDependencies {
+			graph.AddDependency(packageName, dependency)
+		}
+	}
+	return graph
+}
@@ -0,0 +1,99 @@
+package dependencygraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/kurtosis-tech/stacktrace"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	// JsonDependencyGraphSchemaVersion is the version of the JSON dependency graph, the minor version is increased when
+	// fields are added and the major version when fields are removed or changed
+	JsonDependencyGraphSchemaVersion = "1.0"
+
+	jsonIndent = "  "
+
+	dotGraphName = "kurtosis-package-catalog"
+	dotIndent    = "  "
+)
+
+type jsonDependencyGraph struct {
+	SchemaVersion string                   `json:"schemaVersion"`
+	Packages      []*jsonDependencyPackage `json:"packages"`
+	Cycles        [][]string               `json:"cycles"`
+}
+
+type jsonDependencyPackage struct {
+	Name         string   `json:"name"`
+	IsInCatalog  bool     `json:"isInCatalog"`
+	Dependencies []string `json:"dependencies"`
+}
+
+// WriteJson writes the graph as JSON, with each package, its dependencies and the cycles of the graph, e.g.:
+// {"schemaVersion": "1.0", "packages": [{"name": "github.com/owner/a", "isInCatalog": true, "dependencies": ["github.com/owner/b"]}], "cycles": []}
+func (graph *DependencyGraph) WriteJson(writer io.Writer) error {
+	jsonGraph := &jsonDependencyGraph{
+		SchemaVersion: JsonDependencyGraphSchemaVersion,
+		Packages:      []*jsonDependencyPackage{},
+		Cycles:        [][]string{},
+	}
+	for _, packageName := range graph.GetPackageNames() {
+		jsonPackage := &jsonDependencyPackage{
+			Name:         string(packageName),
+			IsInCatalog:  graph.IsInCatalog(packageName),
+			Dependencies: []string{},
+		}
+		for _, dependency := range graph.GetDependencies(packageName) {
+			jsonPackage.Dependencies = append(jsonPackage.Dependencies, string(dependency))
+		}
+		jsonGraph.Packages = append(jsonGraph.Packages, jsonPackage)
+	}
+	for _, cycle := range graph.FindCycles() {
+		jsonCycle := []string{}
+		for _, packageName := range cycle {
+			jsonCycle = append(jsonCycle, string(packageName))
+		}
+		jsonGraph.Cycles = append(jsonGraph.Cycles, jsonCycle)
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", jsonIndent)
+	if err := encoder.Encode(jsonGraph); err != nil {
+		return stacktrace.Propagate(err, "an error occurred encoding the dependency graph as JSON")
+	}
+	return nil
+}
+
+// WriteDot writes the graph in the Graphviz DOT language, the packages that aren't in the catalog are drawn dashed, e.g.:
+// digraph "kurtosis-package-catalog" {
+//
+//	"github.com/owner/a";
+//	"github.com/owner/b" [style=dashed];
+//	"github.com/owner/a" -> "github.com/owner/b";
+//
+// }
+func (graph *DependencyGraph) WriteDot(writer io.Writer) error {
+	dotLines := []string{fmt.Sprintf("digraph %s {", strconv.Quote(dotGraphName))}
+	packageNames := graph.GetPackageNames()
+	for _, packageName := range packageNames {
+		nodeLine := dotIndent + strconv.Quote(string(packageName))
+		if !graph.IsInCatalog(packageName) {
+			nodeLine += " [style=dashed]"
+		}
+		dotLines = append(dotLines, nodeLine+";")
+	}
+	for _, packageName := range packageNames {
+		for _, dependency := range graph.GetDependencies(packageName) {
+			dotLines = append(dotLines, fmt.Sprintf("%s%s -> %s;", dotIndent, strconv.Quote(string(packageName)), strconv.Quote(string(dependency))))
+		}
+	}
+	dotLines = append(dotLines, "}")
+
+	if _, err := fmt.Fprintln(writer, strings.Join(dotLines, "\n")); err != nil {
+		return stacktrace.Propagate(err, "an error occurred writing the dependency graph as DOT")
+	}
+	return nil
+}
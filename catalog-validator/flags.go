@@ -0,0 +1,79 @@
+package main
+
+import (
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/rules"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+	"github.com/kurtosis-tech/stacktrace"
+	"sort"
+	"strings"
+)
+
+const (
+	ruleSeverityOverrideSeparator = "="
+	flagValuesSeparator           = ","
+)
+
+// ruleSeverityOverrides is a repeatable flag with values like '<rule name>=<severity>'
+type ruleSeverityOverrides map[rules.RuleName]rules.Severity
+
+func (overrides ruleSeverityOverrides) String() string {
+	overridesStrs := []string{}
+	for ruleName, severity := range overrides {
+		overridesStrs = append(overridesStrs, string(ruleName)+ruleSeverityOverrideSeparator+string(severity))
+	}
+	sort.Strings(overridesStrs)
+	return strings.Join(overridesStrs, flagValuesSeparator)
+}
+
+func (overrides ruleSeverityOverrides) Set(value string) error {
+	ruleNameStr, severityStr, found := strings.Cut(value, ruleSeverityOverrideSeparator)
+	if !found || ruleNameStr == "" {
+		return stacktrace.NewError("expected the rule severity override to be like '<rule name>%s<severity>', but it's '%s'", ruleSeverityOverrideSeparator, value)
+	}
+	severity, err := rules.ParseSeverity(severityStr)
+	if err != nil {
+		return stacktrace.Propagate(err, "an error occurred parsing the severity override of rule '%s'", ruleNameStr)
+	}
+	overrides[rules.RuleName(ruleNameStr)] = severity
+	return nil
+}
+
+// packageNames is a repeatable flag with comma separated package names, e.g. 'github.com/foo/bar,github.com/foo/baz'
+type packageNames []types.PackageName
+
+func (names *packageNames) String() string {
+	namesStrs := []string{}
+	for _, packageName := range *names {
+		namesStrs = append(namesStrs, string(packageName))
+	}
+	return strings.Join(namesStrs, flagValuesSeparator)
+}
+
+func (names *packageNames) Set(value string) error {
+	for _, packageNameStr := range strings.Split(value, flagValuesSeparator) {
+		packageNameStr = strings.TrimSpace(packageNameStr)
+		if packageNameStr == "" {
+			return stacktrace.NewError("expected a comma separated list of package names, but it's '%s'", value)
+		}
+		*names = append(*names, types.PackageName(packageNameStr))
+	}
+	return nil
+}
+
+// ruleNamePatterns is a repeatable flag with comma separated rule name globs, e.g. 'Valid package*,Duplicated package'
+type ruleNamePatterns []string
+
+func (patterns *ruleNamePatterns) String() string {
+	return strings.Join(*patterns, flagValuesSeparator)
+}
+
+func (patterns *ruleNamePatterns) Set(value string) error {
+	for _, pattern := range strings.Split(value, flagValuesSeparator) {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			return stacktrace.NewError("expected a comma separated list of rule names, which can be globs, but it's '%s'", value)
+		}
+		*patterns = append(*patterns, pattern)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/dependencygraph"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/importer"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/rules"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+)
+
+// runGraph writes the dependency graph of all the catalog packages in the --graph-format format, to stdout or to the
+// --output-file file, so it can be rendered in the docs
+func runGraph(ctx context.Context) {
+	packageCatalogYamlFilepath, err := getKurtosisPackageCatalogYAMLFilepathFromArgs()
+	if err != nil {
+		exitFailure(err)
+	}
+
+	writeGraphFunc, err := getWriteGraphFunc(*graphFormatFlag)
+	if err != nil {
+		exitFailure(err)
+	}
+
+	packageCatalog, err := importer.GetPackagesInTheCatalog(packageCatalogYamlFilepath, nil)
+	if err != nil {
+		exitFailure(err)
+	}
+
+	packageSourceReader, err := createPackageSourceReader(ctx, *sourceTypeFlag)
+	if err != nil {
+		exitFailure(stacktrace.Propagate(err, "an error occurred creating the package source reader"))
+	}
+
+	logrus.Infof("Getting the dependencies of the '%d' packages in '%s'...", len(packageCatalog), packageCatalogYamlFilepath)
+	graph := rules.BuildDependencyGraph(ctx, packageSourceReader, packageCatalog, packageCatalog)
+	logrus.Infof("...dependency graph successfully built, it has '%d' cycles.", len(graph.FindCycles()))
+
+	if *outputFilepathFlag == "" {
+		if err := writeGraphFunc(graph, os.Stdout); err != nil {
+			exitFailure(stacktrace.Propagate(err, "an error occurred writing the dependency graph to stdout"))
+		}
+		logrus.Exit(successExitCode)
+	}
+
+	outputFile, err := os.Create(*outputFilepathFlag)
+	if err != nil {
+		exitFailure(stacktrace.Propagate(err, "an error occurred creating the dependency graph file '%s'", *outputFilepathFlag))
+	}
+	if err := writeGraphFunc(graph, outputFile); err != nil {
+		outputFile.Close()
+		exitFailure(stacktrace.Propagate(err, "an error occurred writing the dependency graph to '%s'", *outputFilepathFlag))
+	}
+	if err := outputFile.Close(); err != nil {
+		exitFailure(stacktrace.Propagate(err, "an error occurred closing the dependency graph file '%s'", *outputFilepathFlag))
+	}
+	logrus.Infof("The '%s' dependency graph was written to '%s'", *graphFormatFlag, *outputFilepathFlag)
+	logrus.Exit(successExitCode)
+}
+
+func getWriteGraphFunc(graphFormat string) (func(graph *dependencygraph.DependencyGraph, writer io.Writer) error, error) {
+	switch graphFormat {
+	case graphFormatDot:
+		return (*dependencygraph.DependencyGraph).WriteDot, nil
+	case graphFormatJson:
+		return (*dependencygraph.DependencyGraph).WriteJson, nil
+	}
+	return nil, stacktrace.NewError("invalid dependency graph format '%s', the valid ones are '%s' and '%s'", graphFormat, graphFormatDot, graphFormatJson)
+}
@@ -0,0 +1,141 @@
+package imagereference
+
+import (
+	"github.com/kurtosis-tech/stacktrace"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DefaultRegistry is the registry of the images without a registry, like 'postgres:16'
+	DefaultRegistry = "docker.io"
+
+	// LatestTag is the tag of the images without a tag nor a digest, it points to a different image on every push
+	LatestTag = "latest"
+
+	// maxNameLength is the max length of the registry and repository of a reference, like the Docker registry enforces
+	maxNameLength = 255
+
+	localhostRegistry = "localhost"
+
+	pathSeparator   = "/"
+	tagSeparator    = ":"
+	digestSeparator = "@"
+)
+
+var (
+	// registryRegex matches a hostname, an IPv4 or an IPv6 address between brackets, with an optional port
+	registryRegex = regexp.MustCompile(`^(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)*|\[[a-fA-F0-9:]+])(?::[0-9]+)?$`)
+
+	// repositoryPathComponentRegex matches the lowercase alphanumeric components of the repository, which can be
+	// separated by one period, one or two underscores or any number of hyphens
+	repositoryPathComponentRegex = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*$`)
+
+	tagRegex = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+
+	digestRegex = regexp.MustCompile(`^[a-z0-9]+(?:[+._-][a-z0-9]+)*:[a-fA-F0-9]{32,}$`)
+)
+
+// ImageReference is an OCI image reference, like 'ghcr.io/owner/image:1.0' or 'postgres@sha256:...'
+type ImageReference struct {
+	// registry is empty if the reference doesn't set it, the image is pulled from the default registry then
+	registry   string
+	repository string
+
+	// tag and digest are empty if the reference doesn't set them
+	tag    string
+	digest string
+}
+
+// ParseImageReference returns the reference if it's a well-formed '[<registry>/]<repository>[:<tag>][@<digest>]' image
+// reference, following the grammar of the Docker distribution reference
+func ParseImageReference(imageReferenceStr string) (*ImageReference, error) {
+	if imageReferenceStr == "" {
+		return nil, stacktrace.NewError("expected the image reference to not be empty")
+	}
+
+	name, digest, hasDigest := strings.Cut(imageReferenceStr, digestSeparator)
+	if hasDigest && !digestRegex.MatchString(digest) {
+		return nil, stacktrace.NewError("the digest '%s' of the image reference '%s' is not a valid digest, like 'sha256:<64 hex characters>'", digest, imageReferenceStr)
+	}
+
+	// the tag is after the last ':' only if it isn't followed by a '/', otherwise the ':' is the registry port
+	tag := ""
+	if tagSeparatorIdx := strings.LastIndex(name, tagSeparator); tagSeparatorIdx > strings.LastIndex(name, pathSeparator) {
+		tag = name[tagSeparatorIdx+1:]
+		name = name[:tagSeparatorIdx]
+		if !tagRegex.MatchString(tag) {
+			return nil, stacktrace.NewError("the tag '%s' of the image reference '%s' is not a valid tag, it can contain up to 128 letters, digits, underscores, periods and hyphens", tag, imageReferenceStr)
+		}
+	}
+
+	if len(name) > maxNameLength {
+		return nil, stacktrace.NewError("the name of the image reference '%s' is longer than %d characters", imageReferenceStr, maxNameLength)
+	}
+
+	registry := ""
+	repository := name
+	if firstComponent, rest, hasMoreComponents := strings.Cut(name, pathSeparator); hasMoreComponents && isRegistry(firstComponent) {
+		registry = firstComponent
+		repository = rest
+		if !registryRegex.MatchString(registry) {
+			return nil, stacktrace.NewError("the registry '%s' of the image reference '%s' is not a valid hostname", registry, imageReferenceStr)
+		}
+	}
+
+	for _, pathComponent := range strings.Split(repository, pathSeparator) {
+		if !repositoryPathComponentRegex.MatchString(pathComponent) {
+			return nil, stacktrace.NewError("the repository '%s' of the image reference '%s' is not valid, its components separated by '/' can only contain lowercase letters, digits and separators", repository, imageReferenceStr)
+		}
+	}
+
+	return &ImageReference{
+		registry:   registry,
+		repository: repository,
+		tag:        tag,
+		digest:     digest,
+	}, nil
+}
+
+// GetRegistry returns the registry the image is pulled from, the default one if the reference doesn't set it
+func (imageReference *ImageReference) GetRegistry() string {
+	if imageReference.registry == "" {
+		return DefaultRegistry
+	}
+	return imageReference.registry
+}
+
+func (imageReference *ImageReference) GetRepository() string {
+	return imageReference.repository
+}
+
+// GetTag returns the tag of the reference, it's empty if it isn't set
+func (imageReference *ImageReference) GetTag() string {
+	return imageReference.tag
+}
+
+// GetDigest returns the digest of the reference, it's empty if it isn't set
+func (imageReference *ImageReference) GetDigest() string {
+	return imageReference.digest
+}
+
+func (imageReference *ImageReference) String() string {
+	imageReferenceStr := imageReference.repository
+	if imageReference.registry != "" {
+		imageReferenceStr = imageReference.registry + pathSeparator + imageReferenceStr
+	}
+	if imageReference.tag != "" {
+		imageReferenceStr += tagSeparator + imageReference.tag
+	}
+	if imageReference.digest != "" {
+		imageReferenceStr += digestSeparator + imageReference.digest
+	}
+	return imageReferenceStr
+}
+
+// isRegistry returns true if the first component of the reference name is a registry, like Docker does: it's a
+// registry if it has a domain, a port, uppercase letters or is localhost, otherwise it's part of a Docker Hub
+// repository like 'owner/image', which can only be lowercase
+func isRegistry(firstComponent string) bool {
+	return strings.ContainsAny(firstComponent, ".:") || firstComponent == localhostRegistry || strings.ToLower(firstComponent) != firstComponent
+}
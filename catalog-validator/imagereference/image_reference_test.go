@@ -0,0 +1,125 @@
+package imagereference
+
+import (
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+const (
+	testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+)
+
+func TestParseImageReference(t *testing.T) {
+	testCases := []struct {
+		imageReferenceStr  string
+		expectedRegistry   string
+		expectedRepository string
+		expectedTag        string
+		expectedDigest     string
+	}{
+		{
+			imageReferenceStr:  "postgres",
+			expectedRegistry:   DefaultRegistry,
+			expectedRepository: "postgres",
+		},
+		{
+			imageReferenceStr:  "postgres:16.2",
+			expectedRegistry:   DefaultRegistry,
+			expectedRepository: "postgres",
+			expectedTag:        "16.2",
+		},
+		{
+			imageReferenceStr:  "kurtosistech/postgres-package:latest",
+			expectedRegistry:   DefaultRegistry,
+			expectedRepository: "kurtosistech/postgres-package",
+			expectedTag:        LatestTag,
+		},
+		{
+			imageReferenceStr:  "ghcr.io/owner/sub__path/image-name:1.0_rc",
+			expectedRegistry:   "ghcr.io",
+			expectedRepository: "owner/sub__path/image-name",
+			expectedTag:        "1.0_rc",
+		},
+		{
+			imageReferenceStr:  "localhost/a",
+			expectedRegistry:   "localhost",
+			expectedRepository: "a",
+		},
+		{
+			imageReferenceStr:  "localhost:5000/a",
+			expectedRegistry:   "localhost:5000",
+			expectedRepository: "a",
+		},
+		{
+			imageReferenceStr:  "[::1]:5000/a:b",
+			expectedRegistry:   "[::1]:5000",
+			expectedRepository: "a",
+			expectedTag:        "b",
+		},
+		{
+			imageReferenceStr:  "192.168.1.10/a/b",
+			expectedRegistry:   "192.168.1.10",
+			expectedRepository: "a/b",
+		},
+		{
+			imageReferenceStr:  "Foo/bar",
+			expectedRegistry:   "Foo",
+			expectedRepository: "bar",
+		},
+		{
+			imageReferenceStr:  "a@" + testDigest,
+			expectedRegistry:   DefaultRegistry,
+			expectedRepository: "a",
+			expectedDigest:     testDigest,
+		},
+		{
+			imageReferenceStr:  "registry.example.com:443/a/b:1.0@" + testDigest,
+			expectedRegistry:   "registry.example.com:443",
+			expectedRepository: "a/b",
+			expectedTag:        "1.0",
+			expectedDigest:     testDigest,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.imageReferenceStr, func(t *testing.T) {
+			imageReference, err := ParseImageReference(testCase.imageReferenceStr)
+			require.NoError(t, err)
+			require.Equal(t, testCase.expectedRegistry, imageReference.GetRegistry())
+			require.Equal(t, testCase.expectedRepository, imageReference.GetRepository())
+			require.Equal(t, testCase.expectedTag, imageReference.GetTag())
+			require.Equal(t, testCase.expectedDigest, imageReference.GetDigest())
+			require.Equal(t, testCase.imageReferenceStr, imageReference.String())
+		})
+	}
+}
+
+func TestParseImageReference_Invalid(t *testing.T) {
+	testCases := []struct {
+		name              string
+		imageReferenceStr string
+	}{
+		{name: "empty reference", imageReferenceStr: ""},
+		{name: "uppercase repository", imageReferenceStr: "Postgres"},
+		{name: "uppercase repository after the registry", imageReferenceStr: "ghcr.io/Owner/image"},
+		{name: "empty tag", imageReferenceStr: "postgres:"},
+		{name: "tag starting with a period", imageReferenceStr: "postgres:.16"},
+		{name: "too long tag", imageReferenceStr: "postgres:" + strings.Repeat("a", 129)},
+		{name: "digest without algorithm", imageReferenceStr: "postgres@0123456789abcdef0123456789abcdef"},
+		{name: "too short digest", imageReferenceStr: "postgres@sha256:0123456789abcdef"},
+		{name: "invalid registry", imageReferenceStr: "-registry.io/a"},
+		{name: "invalid IPv6 registry", imageReferenceStr: "[::g]:5000/a"},
+		{name: "empty repository component", imageReferenceStr: "owner//image"},
+		{name: "repository with consecutive periods", imageReferenceStr: "owner/image..name"},
+		{name: "only a registry", imageReferenceStr: "localhost:5000/"},
+		{name: "too long name", imageReferenceStr: strings.Repeat("a", 256)},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := ParseImageReference(testCase.imageReferenceStr)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"github.com/kurtosis-tech/stacktrace"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// gitBaseCatalogSourcePrefix selects a git ref of the repository containing the catalog file, e.g. 'git:origin/main'
+	gitBaseCatalogSourcePrefix = "git:"
+	httpURLPrefix              = "http://"
+	httpsURLPrefix             = "https://"
+
+	gitBinaryName = "git"
+	// currentDirGitPathPrefix makes 'git show <ref>:<path>' resolve the path relative to the command directory
+	currentDirGitPathPrefix = "./"
+)
+
+// BaseCatalogSource reads the catalog that the validated one is compared with, to find the packages being added
+type BaseCatalogSource interface {
+	ReadCatalogFileContent(ctx context.Context) ([]byte, error)
+	String() string
+}
+
+// NewBaseCatalogSource returns the base catalog source of the '--base' value, which can be 'git:<ref>' to read the
+// catalog file in a ref of its git repository, an HTTP(S) URL or a local filepath. It returns nil if the value is
+// empty, which means that there isn't a base catalog
+func NewBaseCatalogSource(baseCatalog string, catalogFilepath string) (BaseCatalogSource, error) {
+	if baseCatalog == "" {
+		return nil, nil
+	}
+	if gitRef, isGitRef := strings.CutPrefix(baseCatalog, gitBaseCatalogSourcePrefix); isGitRef {
+		if gitRef == "" {
+			return nil, stacktrace.NewError("the base catalog '%s' doesn't contain a git ref, e.g. '%sorigin/main'", baseCatalog, gitBaseCatalogSourcePrefix)
+		}
+		return newGitRefBaseCatalogSource(gitRef, catalogFilepath), nil
+	}
+	if strings.HasPrefix(baseCatalog, httpURLPrefix) || strings.HasPrefix(baseCatalog, httpsURLPrefix) {
+		return newURLBaseCatalogSource(baseCatalog), nil
+	}
+	return newFileBaseCatalogSource(baseCatalog), nil
+}
+
+// fileBaseCatalogSource reads the base catalog from a local file
+type fileBaseCatalogSource struct {
+	filepath string
+}
+
+func newFileBaseCatalogSource(filepath string) *fileBaseCatalogSource {
+	return &fileBaseCatalogSource{filepath: filepath}
+}
+
+func (source *fileBaseCatalogSource) ReadCatalogFileContent(_ context.Context) ([]byte, error) {
+	fileContent, err := os.ReadFile(source.filepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "attempted to read the base catalog file with path '%v' but failed", source.filepath)
+	}
+	return fileContent, nil
+}
+
+func (source *fileBaseCatalogSource) String() string {
+	return source.filepath
+}
+
+// gitRefBaseCatalogSource reads the base catalog from a ref of the git repository containing the catalog file,
+// the same way as 'git show origin/main:kurtosis-package-catalog.yml'
+type gitRefBaseCatalogSource struct {
+	ref             string
+	catalogFilepath string
+}
+
+func newGitRefBaseCatalogSource(ref string, catalogFilepath string) *gitRefBaseCatalogSource {
+	return &gitRefBaseCatalogSource{ref: ref, catalogFilepath: catalogFilepath}
+}
+
+func (source *gitRefBaseCatalogSource) ReadCatalogFileContent(ctx context.Context) ([]byte, error) {
+	catalogDirpath, catalogFilename := filepath.Split(source.catalogFilepath)
+	gitShowArg := source.ref + ":" + currentDirGitPathPrefix + catalogFilename
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	gitCmd := exec.CommandContext(ctx, gitBinaryName, "show", gitShowArg)
+	gitCmd.Dir = catalogDirpath
+	gitCmd.Stdout = stdout
+	gitCmd.Stderr = stderr
+	if err := gitCmd.Run(); err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred running 'git show %s' in '%s', the command output was:\n%s", gitShowArg, catalogDirpath, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+func (source *gitRefBaseCatalogSource) String() string {
+	return gitBaseCatalogSourcePrefix + source.ref
+}
+
+// urlBaseCatalogSource downloads the base catalog from an URL, e.g. the raw content of the catalog file in GitHub
+type urlBaseCatalogSource struct {
+	url string
+}
+
+func newURLBaseCatalogSource(url string) *urlBaseCatalogSource {
+	return &urlBaseCatalogSource{url: url}
+}
+
+func (source *urlBaseCatalogSource) ReadCatalogFileContent(ctx context.Context) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, source.url, nil)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred creating the request to get the yaml file content from URL '%s'", source.url)
+	}
+	response, getErr := http.DefaultClient.Do(request)
+	if getErr != nil {
+		return nil, stacktrace.Propagate(getErr, "an error occurred getting the yaml file content from URL '%s'", source.url)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, stacktrace.NewError("expected status code '%d' getting the yaml file content from URL '%s', but it was '%d'", http.StatusOK, source.url, response.StatusCode)
+	}
+	responseBodyBytes, readAllErr := io.ReadAll(response.Body)
+	if readAllErr != nil {
+		return nil, stacktrace.Propagate(readAllErr, "an error occurred reading the yaml file content")
+	}
+	return responseBodyBytes, nil
+}
+
+func (source *urlBaseCatalogSource) String() string {
+	return source.url
+}
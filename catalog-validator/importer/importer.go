@@ -1,46 +1,82 @@
 package importer
 
 import (
-	"github.com/kurtosis-tech/kurtosis-package-indexer/server/catalog"
+	"context"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
 	"github.com/kurtosis-tech/stacktrace"
-	"io"
-	"net/http"
 	"os"
 )
 
-const (
-	currentPackageCatalogYamlFileURL = "https://raw.githubusercontent.com/kurtosis-tech/kurtosis-package-catalog/main/kurtosis-package-catalog.yml"
-)
-
-// GetNewPackageInTheCatalog compares the current state of the catalog in the repository main branch
-// with the catalog read from a filepath and returns a subset containing the new packages being added
-func GetNewPackageInTheCatalog(kurtosisPackageCatalogYamlFilepath string) (catalog.PackageCatalog, error) {
+// GetNewPackageInTheCatalog compares the base catalog with the catalog read from a filepath and returns a subset
+// containing the new packages being added, renamed or changed, and the diff between both catalogs.
+// All the packages are returned, without diff, if there isn't a base catalog
+func GetNewPackageInTheCatalog(ctx context.Context, kurtosisPackageCatalogYamlFilepath string, baseCatalogSource BaseCatalogSource) (catalog.PackageCatalog, *catalog.CatalogDiff, error) {
 	newCatalog, err := readCatalog(kurtosisPackageCatalogYamlFilepath)
 	if err != nil {
-		return nil, stacktrace.Propagate(err, "an error occurred reading the catalog from '%s'", kurtosisPackageCatalogYamlFilepath)
+		return nil, nil, stacktrace.Propagate(err, "an error occurred reading the catalog from '%s'", kurtosisPackageCatalogYamlFilepath)
 	}
 
-	currentCatalog, err := getCurrentPackageCatalog()
+	if baseCatalogSource == nil {
+		return newCatalog, nil, nil
+	}
+
+	currentCatalog, err := getBaseCatalog(ctx, baseCatalogSource)
 	if err != nil {
-		return nil, stacktrace.Propagate(err, "an error occurred reading the current package catalog")
+		return nil, nil, stacktrace.Propagate(err, "an error occurred reading the base package catalog from '%s'", baseCatalogSource)
 	}
 
-	currentCatalogSet := map[string]bool{}
+	catalogDiff := catalog.NewCatalogDiff(currentCatalog, newCatalog)
 
-	for _, kurtosisPackage := range currentCatalog {
-		kurtosisPackageStr := string(kurtosisPackage.GetPackageName())
-		currentCatalogSet[kurtosisPackageStr] = true
+	newPackagesSet := map[types.PackageName]bool{}
+	for _, kurtosisPackage := range catalogDiff.GetAddedPackages() {
+		newPackagesSet[kurtosisPackage.GetPackageName()] = true
+	}
+	for _, packageChange := range append(catalogDiff.GetRenamedPackages(), catalogDiff.GetChangedPackages()...) {
+		newPackagesSet[packageChange.GetNewPackage().GetPackageName()] = true
 	}
 
+	// every entry of the new packages is kept, so the duplicated entries are also validated
 	var catalogWithNewPackages catalog.PackageCatalog
-
 	for _, kurtosisPackage := range newCatalog {
-		kurtosisPackageStr := string(kurtosisPackage.GetPackageName())
-		if _, found := currentCatalogSet[kurtosisPackageStr]; !found {
+		if newPackagesSet[kurtosisPackage.GetPackageName()] {
 			catalogWithNewPackages = append(catalogWithNewPackages, kurtosisPackage)
 		}
 	}
-	return catalogWithNewPackages, nil
+	return catalogWithNewPackages, catalogDiff, nil
+}
+
+// GetPackagesInTheCatalog returns the packages of the catalog read from a filepath, only the ones with the package
+// names are returned if there is any, in the same order they are in the catalog
+func GetPackagesInTheCatalog(kurtosisPackageCatalogYamlFilepath string, packageNames []types.PackageName) (catalog.PackageCatalog, error) {
+	packageCatalog, err := readCatalog(kurtosisPackageCatalogYamlFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred reading the catalog from '%s'", kurtosisPackageCatalogYamlFilepath)
+	}
+
+	if len(packageNames) == 0 {
+		return packageCatalog, nil
+	}
+
+	packageNamesFound := map[types.PackageName]bool{}
+	for _, packageName := range packageNames {
+		packageNamesFound[packageName] = false
+	}
+
+	var selectedPackageCatalog catalog.PackageCatalog
+	for _, kurtosisPackage := range packageCatalog {
+		if _, found := packageNamesFound[kurtosisPackage.GetPackageName()]; found {
+			selectedPackageCatalog = append(selectedPackageCatalog, kurtosisPackage)
+			packageNamesFound[kurtosisPackage.GetPackageName()] = true
+		}
+	}
+
+	for _, packageName := range packageNames {
+		if !packageNamesFound[packageName] {
+			return nil, stacktrace.NewError("package '%s' was selected to be validated but it isn't in the catalog '%s'", packageName, kurtosisPackageCatalogYamlFilepath)
+		}
+	}
+	return selectedPackageCatalog, nil
 }
 
 func readCatalog(kurtosisPackageCatalogYamlFilepath string) (catalog.PackageCatalog, error) {
@@ -62,21 +98,15 @@ func readCatalog(kurtosisPackageCatalogYamlFilepath string) (catalog.PackageCata
 	return packageCatalog, nil
 }
 
-func getCurrentPackageCatalog() (catalog.PackageCatalog, error) {
-
-	response, getErr := http.Get(currentPackageCatalogYamlFileURL)
-	if getErr != nil {
-		return nil, stacktrace.Propagate(getErr, "an error occurred getting the yaml file content from URL '%s'", currentPackageCatalogYamlFileURL)
-	}
-	defer response.Body.Close()
-	responseBodyBytes, readAllErr := io.ReadAll(response.Body)
-	if readAllErr != nil {
-		return nil, stacktrace.Propagate(readAllErr, "an error occurred reading the yaml file content")
+func getBaseCatalog(ctx context.Context, baseCatalogSource BaseCatalogSource) (catalog.PackageCatalog, error) {
+	fileContent, err := baseCatalogSource.ReadCatalogFileContent(ctx)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred reading the base catalog file content")
 	}
 
-	packageCatalog, err := catalog.GetPackageCatalogFromYamlFileContent(responseBodyBytes)
+	packageCatalog, err := catalog.GetPackageCatalogFromYamlFileContent(fileContent)
 	if err != nil {
-		return nil, stacktrace.Propagate(err, "an error occurred reading the Kurtosis package catalog YAML file content from '%s'", currentPackageCatalogYamlFileURL)
+		return nil, stacktrace.Propagate(err, "an error occurred reading the Kurtosis package catalog YAML file content from '%s'", baseCatalogSource)
 	}
 
 	return packageCatalog, nil
@@ -0,0 +1,99 @@
+package locator
+
+import (
+	"github.com/kurtosis-tech/stacktrace"
+	"regexp"
+	"strings"
+)
+
+const (
+	// gitHubDomainPrefix is the prefix of every locator, Kurtosis only supports packages on GitHub for now
+	gitHubDomainPrefix = "github.com/"
+
+	pathSeparator = "/"
+
+	currentDirPathSegment = "."
+	parentDirPathSegment  = ".."
+)
+
+var (
+	// gitHubOwnerRegex follows the GitHub rules for user and organization names: alphanumeric characters or single
+	// hyphens, not starting or ending with a hyphen, with up to 39 characters
+	gitHubOwnerRegex = regexp.MustCompile(`^[A-Za-z0-9](?:-?[A-Za-z0-9]){0,38}$`)
+
+	// gitHubRepositoryNameRegex follows the GitHub rules for repository names
+	gitHubRepositoryNameRegex = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+	// pathSegmentRegex rejects the characters that can't be in a locator because they have another meaning in URLs,
+	// or that are usually a mistake like whitespaces
+	pathSegmentRegex = regexp.MustCompile(`^[^\s?#@:\\]+$`)
+)
+
+// Locator is a Kurtosis locator of a package or of a file in a package, like 'github.com/owner/repository/path/file.star'
+type Locator struct {
+	owner          string
+	repositoryName string
+
+	// path is the path relative to the repository root without the leading '/', it's empty if the locator is the repository root
+	path string
+}
+
+// ParseLocator returns the locator if it's a well-formed 'github.com/<owner>/<repository name>[/<path>]' locator,
+// without a scheme, a trailing '/' nor '.' and '..' path segments
+func ParseLocator(locatorStr string) (*Locator, error) {
+	locatorPath, found := strings.CutPrefix(locatorStr, gitHubDomainPrefix)
+	if !found {
+		return nil, stacktrace.NewError("expected the locator '%s' to start with '%s', without a scheme like 'https://'", locatorStr, gitHubDomainPrefix)
+	}
+
+	pathSegments := strings.Split(locatorPath, pathSeparator)
+	if len(pathSegments) < 2 {
+		return nil, stacktrace.NewError("expected the locator '%s' to contain the repository owner and name, like '%sowner/repository'", locatorStr, gitHubDomainPrefix)
+	}
+	owner := pathSegments[0]
+	if !gitHubOwnerRegex.MatchString(owner) {
+		return nil, stacktrace.NewError("the repository owner '%s' of the locator '%s' is not a valid GitHub user or organization name", owner, locatorStr)
+	}
+	repositoryName := pathSegments[1]
+	if !gitHubRepositoryNameRegex.MatchString(repositoryName) || repositoryName == currentDirPathSegment || repositoryName == parentDirPathSegment {
+		return nil, stacktrace.NewError("the repository name '%s' of the locator '%s' is not a valid GitHub repository name", repositoryName, locatorStr)
+	}
+	for _, pathSegment := range pathSegments[2:] {
+		if pathSegment == "" {
+			return nil, stacktrace.NewError("the locator '%s' contains an empty path segment, it can't contain '//' nor end with '/'", locatorStr)
+		}
+		if pathSegment == currentDirPathSegment || pathSegment == parentDirPathSegment {
+			return nil, stacktrace.NewError("the locator '%s' contains the '%s' path segment, it has to be a clean path", locatorStr, pathSegment)
+		}
+		if !pathSegmentRegex.MatchString(pathSegment) {
+			return nil, stacktrace.NewError("the path segment '%s' of the locator '%s' contains invalid characters", pathSegment, locatorStr)
+		}
+	}
+
+	return &Locator{
+		owner:          owner,
+		repositoryName: repositoryName,
+		path:           strings.Join(pathSegments[2:], pathSeparator),
+	}, nil
+}
+
+func (locator *Locator) GetOwner() string {
+	return locator.owner
+}
+
+func (locator *Locator) GetRepositoryName() string {
+	return locator.repositoryName
+}
+
+// GetPath returns the path relative to the repository root without the leading '/', it's empty for the repository root
+func (locator *Locator) GetPath() string {
+	return locator.path
+}
+
+func (locator *Locator) String() string {
+	locatorStr := gitHubDomainPrefix + locator.owner + pathSeparator + locator.repositoryName
+	if locator.path != "" {
+		locatorStr += pathSeparator + locator.path
+	}
+	return locatorStr
+}
@@ -2,15 +2,26 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
 	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/importer"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/output"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/source"
 	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/rules"
 	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/validator"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/github"
 	"github.com/kurtosis-tech/stacktrace"
 	"github.com/sirupsen/logrus"
 	"os"
+	"os/signal"
 	"path"
+	"path/filepath"
 	"runtime"
 	"strings"
+	"syscall"
+	"time"
 )
 
 const (
@@ -23,68 +34,609 @@ const (
 	logMethodAlongWithLogLine = true
 	functionPathSeparator     = "."
 	emptyFunctionName         = ""
+
+	sourceFlagName           = "source"
+	sourceDirFlagName        = "source-dir"
+	gitRemoteBaseURLFlagName = "git-remote-base-url"
+	gitCacheDirFlagName      = "git-cache-dir"
+	gitHubSourceType         = "github"
+	localSourceType          = "local"
+	gitSourceType            = "git"
+	defaultSourceType        = gitHubSourceType
+	defaultSourceDirpathFlag = ""
+	defaultGitRemoteBaseURL  = "https://github.com"
+	defaultGitCacheDirpath   = ""
+
+	gitCacheTempDirPattern = "catalog-validator-git-cache-"
+
+	parallelismFlagName = "parallelism"
+	defaultParallelism  = 8
+
+	ruleSeverityFlagName = "rule-severity"
+
+	onlyFlagName = "only"
+	skipFlagName = "skip"
+
+	outputFlagName        = "output"
+	outputFileFlagName    = "output-file"
+	defaultOutputFormat   = output.FormatText
+	defaultOutputFilepath = ""
+
+	baseFlagName       = "base"
+	defaultBaseCatalog = ""
+
+	allFlagName      = "all"
+	packagesFlagName = "packages"
+
+	auditCommandName      = "audit"
+	rulesCommandName      = "rules"
+	rulesListCommandName  = "list"
+	graphCommandName      = "graph"
+	historyDirFlagName    = "history-dir"
+	defaultHistoryDirpath = ".catalog-validator-history"
+
+	authorFlagName = "author"
+	defaultAuthor  = ""
+
+	configFlagName        = "config"
+	defaultConfigFilepath = ""
+
+	waiversFileFlagName    = "waivers-file"
+	defaultWaiversFilepath = ""
+
+	junitOutFlagName        = "junit-out"
+	defaultJUnitOutFilepath = ""
+
+	graphFormatFlagName = "graph-format"
+	graphFormatDot      = "dot"
+	graphFormatJson     = "json"
+	defaultGraphFormat  = graphFormatDot
+
+	// gitHubStepSummaryEnvVarName is set by GitHub Actions with the file where the job summary Markdown is appended
+	gitHubStepSummaryEnvVarName = "GITHUB_STEP_SUMMARY"
+	gitHubStepSummaryFilePerms  = 0644
+)
+
+var (
+	baseCatalogFlag = flag.String(
+		baseFlagName,
+		defaultBaseCatalog,
+		"catalog compared with the validated one to only validate the new packages, it can be a local file, 'git:<ref>' to read the catalog file in a ref of its git repository (e.g. 'git:origin/main') or an HTTP(S) URL. All the packages are validated if it's not set",
+	)
+	sourceTypeFlag = flag.String(
+		sourceFlagName,
+		defaultSourceType,
+		"where the package repositories are read from, one of: '"+gitHubSourceType+"' (GitHub contents API), '"+localSourceType+"' (local checkouts in the --"+sourceDirFlagName+" directory) or '"+gitSourceType+"' (shallow clones of each repository)",
+	)
+	sourceDirpathFlag = flag.String(
+		sourceDirFlagName,
+		defaultSourceDirpathFlag,
+		"directory containing a checkout of each package repository on '<owner>/<repository name>', used with --"+sourceFlagName+"="+localSourceType,
+	)
+	gitRemoteBaseURLFlag = flag.String(
+		gitRemoteBaseURLFlagName,
+		defaultGitRemoteBaseURL,
+		"URL the package repositories are cloned from as '<URL>/<owner>/<repository name>', e.g. 'file:///path/to/remotes', used with --"+sourceFlagName+"="+gitSourceType,
+	)
+	gitCacheDirpathFlag = flag.String(
+		gitCacheDirFlagName,
+		defaultGitCacheDirpath,
+		"directory where the package repositories are cloned as '<owner>/<repository name>.git', existing bare mirrors on it are used without cloning them again. "+
+			"A temporary directory is used if it's not set. Used with --"+sourceFlagName+"="+gitSourceType,
+	)
+	parallelismFlag = flag.Int(
+		parallelismFlagName,
+		defaultParallelism,
+		"max number of rule checks running at the same time",
+	)
+	ruleSeverityOverridesFlag = ruleSeverityOverrides{}
+	packageNamesFlag          = &packageNames{}
+	onlyRulePatternsFlag      = &ruleNamePatterns{}
+	skipRulePatternsFlag      = &ruleNamePatterns{}
+	allFlag                   = flag.Bool(
+		allFlagName,
+		false,
+		"validates all the packages in the catalog instead of only the new ones, it can't be used with --"+baseFlagName,
+	)
+	authorFlag = flag.String(
+		authorFlagName,
+		defaultAuthor,
+		"GitHub user proposing the catalog change, the rules about removing packages from the catalog allow the repository owner to do it",
+	)
+	historyDirpathFlag = flag.String(
+		historyDirFlagName,
+		defaultHistoryDirpath,
+		"directory where the '"+auditCommandName+"' command keeps the result of each run, to compare it with the previous one",
+	)
+	configFilepathFlag = flag.String(
+		configFlagName,
+		defaultConfigFilepath,
+		"YAML file enabling or disabling the rules, overriding their severity and setting their parameters, all the rules are enabled with their defaults if it's not set. It can't be used with the '"+auditCommandName+"' command",
+	)
+	waiversFilepathFlag = flag.String(
+		waiversFileFlagName,
+		defaultWaiversFilepath,
+		"file with the waivers accepting known failures of the packages until an expiry date, the '"+rules.WaiversFilename+"' file next to the catalog file is used if it exists and the flag is not set. The '"+auditCommandName+"' command doesn't use waivers",
+	)
+	outputFormatFlag = flag.String(
+		outputFlagName,
+		string(defaultOutputFormat),
+		fmt.Sprintf("format of the validator report, one of %v. The text report is always logged, the other formats are also written to stdout or to the --%s file", output.GetAllFormats(), outputFileFlagName),
+	)
+	outputFilepathFlag = flag.String(
+		outputFileFlagName,
+		defaultOutputFilepath,
+		"file where the report is written when the --"+outputFlagName+" format isn't text, or where the '"+graphCommandName+"' command writes the graph. It's written to stdout if it's not set",
+	)
+	junitOutFilepathFlag = flag.String(
+		junitOutFlagName,
+		defaultJUnitOutFilepath,
+		"file where the report is also written as JUnit XML, with a test case for each rule and package, it isn't written if it's not set",
+	)
+	graphFormatFlag = flag.String(
+		graphFormatFlagName,
+		defaultGraphFormat,
+		"format of the dependency graph written by the '"+graphCommandName+"' command, one of: '"+graphFormatDot+"' (Graphviz) or '"+graphFormatJson+"'",
+	)
 )
 
 func main() {
 
-	ctx := context.Background()
+	// the checks running are cancelled if the validator is interrupted
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 	configureLogger()
+	flag.Var(
+		ruleSeverityOverridesFlag,
+		ruleSeverityFlagName,
+		"overrides the severity of a rule for this run as '<rule name>=<error|warning|info>', it can be repeated, e.g. --"+ruleSeverityFlagName+"='Valid package icon=warning'. It can't be used with the '"+auditCommandName+"' command",
+	)
+	flag.Var(
+		packageNamesFlag,
+		packagesFlagName,
+		"comma separated names of the catalog packages to validate instead of only the new ones, it can be repeated and it can't be used with --"+baseFlagName+" or the '"+auditCommandName+"' command",
+	)
+
+	flag.Var(
+		onlyRulePatternsFlag,
+		onlyFlagName,
+		"comma separated names of the rules to check instead of all of them, they can be globs like 'Valid package*' and the flag can be repeated",
+	)
+	flag.Var(
+		skipRulePatternsFlag,
+		skipFlagName,
+		"comma separated names of the rules to not check, they can be globs like 'Valid package*' and the flag can be repeated",
+	)
+
+	if len(os.Args) > 2 && os.Args[1] == rulesCommandName && os.Args[2] == rulesListCommandName {
+		if err := flag.CommandLine.Parse(os.Args[3:]); err != nil {
+			exitFailure(stacktrace.Propagate(err, "an error occurred parsing the '%s %s' command flags", rulesCommandName, rulesListCommandName))
+		}
+		runRulesList(ctx)
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == graphCommandName {
+		if err := flag.CommandLine.Parse(os.Args[2:]); err != nil {
+			exitFailure(stacktrace.Propagate(err, "an error occurred parsing the '%s' command flags", graphCommandName))
+		}
+		runGraph(ctx)
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == rules.RecordRefSHAsCommandName {
+		if err := flag.CommandLine.Parse(os.Args[2:]); err != nil {
+			exitFailure(stacktrace.Propagate(err, "an error occurred parsing the '%s' command flags", rules.RecordRefSHAsCommandName))
+		}
+		runRecordRefSHAs(ctx)
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == auditCommandName {
+		if err := flag.CommandLine.Parse(os.Args[2:]); err != nil {
+			exitFailure(stacktrace.Propagate(err, "an error occurred parsing the '%s' command flags", auditCommandName))
+		}
+		runAudit(ctx)
+		return
+	}
+	flag.Parse()
 
 	packageCatalogYamlFilepath, err := getKurtosisPackageCatalogYAMLFilepathFromArgs()
 	if err != nil {
 		exitFailure(err)
 	}
 
-	logrus.Infof("Getting the new Kurtosis packages from '%s'...", packageCatalogYamlFilepath)
-	packageCatalog, err := importer.GetNewPackageInTheCatalog(packageCatalogYamlFilepath)
+	outputFormat, err := output.ParseFormat(*outputFormatFlag)
 	if err != nil {
 		exitFailure(err)
 	}
+
+	packageCatalog, catalogDiff, err := getPackageCatalogToValidate(ctx, packageCatalogYamlFilepath)
+	if err != nil {
+		exitFailure(err)
+	}
+
+	waivers, err := getWaivers(packageCatalogYamlFilepath)
+	if err != nil {
+		exitFailure(stacktrace.Propagate(err, "an error occurred getting the waivers"))
+	}
+
+	validatorResult, err := validateCatalog(ctx, packageCatalogYamlFilepath, packageCatalog, catalogDiff, waivers)
+	if err != nil {
+		exitFailure(err)
+	}
+
+	logReport(validatorResult, packageCatalogYamlFilepath)
+
+	if err := writeReports(validatorResult, outputFormat, packageCatalogYamlFilepath); err != nil {
+		exitFailure(err)
+	}
+
+	if !validatorResult.IsValidCatalog() {
+		exitFailure(stacktrace.NewError("the current package catalog is not valid."))
+	}
+
+	if validatorResult.HasWarnings() {
+		logrus.Warn("...all validations passed, but there are warnings reported above")
+	} else {
+		logrus.Info("...all validations passed")
+	}
+
+	logrus.Exit(successExitCode)
+}
+
+// validateCatalog checks all the rules over the packages, the diff rules are only checked if there is a catalog diff.
+// The failures accepted by the waivers are reported apart
+func validateCatalog(ctx context.Context, packageCatalogYamlFilepath string, packageCatalog catalog.PackageCatalog, catalogDiff *catalog.CatalogDiff, waivers []*rules.Waiver) (*validator.Report, error) {
+	packageSourceReader, err := createPackageSourceReader(ctx, *sourceTypeFlag)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred creating the package source reader")
+	}
+
+	// the rules looking across packages need all of them, even if only some are validated
+	fullPackageCatalog, err := importer.GetPackagesInTheCatalog(packageCatalogYamlFilepath, nil)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred getting all the packages in the catalog")
+	}
+
+	rulesToValidate, ruleSeverityOverrides, err := getRulesToValidate(ctx, packageSourceReader, fullPackageCatalog)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred getting the rules to validate")
+	}
+
+	logrus.Info("Running the validations...")
+	validatorObj := validator.NewValidator(packageCatalog, rulesToValidate, *parallelismFlag, ruleSeverityOverrides, catalogDiff, waivers)
+	validatorResult, err := validatorObj.Validate(ctx)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred validating the catalog")
+	}
+	return validatorResult, nil
+}
+
+// getRulesToValidate returns the rules enabled in the rules config and selected with --only and --skip, and the
+// severity overrides of the rules config and of --rule-severity, which takes precedence. The rules config overrides
+// of the disabled and unselected rules are dropped, the validator only rejects the --rule-severity ones of rules that
+// aren't checked
+func getRulesToValidate(ctx context.Context, packageSourceReader source.PackageSourceReader, fullPackageCatalog catalog.PackageCatalog) ([]rules.Rule, map[rules.RuleName]rules.Severity, error) {
+	rulesConfig, err := getRulesConfig()
+	if err != nil {
+		return nil, nil, stacktrace.Propagate(err, "an error occurred getting the rules config")
+	}
+
+	enabledRules, err := rules.GetAll(ctx, packageSourceReader, *authorFlag, fullPackageCatalog, rulesConfig)
+	if err != nil {
+		return nil, nil, stacktrace.Propagate(err, "an error occurred getting the rules")
+	}
+	selectedRules, err := rules.SelectRules(enabledRules, *onlyRulePatternsFlag, *skipRulePatternsFlag)
+	if err != nil {
+		return nil, nil, stacktrace.Propagate(err, "an error occurred selecting the rules with --%s and --%s", onlyFlagName, skipFlagName)
+	}
+	if len(selectedRules) < len(enabledRules) {
+		logrus.Infof("'%d' of the '%d' enabled rules were selected with --%s and --%s", len(selectedRules), len(enabledRules), onlyFlagName, skipFlagName)
+	}
+
+	ruleSeverityOverrides := map[rules.RuleName]rules.Severity{}
+	rulesConfigSeverityOverrides := rulesConfig.GetRuleSeverityOverrides()
+	for _, rule := range selectedRules {
+		if severity, found := rulesConfigSeverityOverrides[rule.GetName()]; found {
+			ruleSeverityOverrides[rule.GetName()] = severity
+		}
+	}
+	for ruleName, severity := range ruleSeverityOverridesFlag {
+		ruleSeverityOverrides[ruleName] = severity
+	}
+	return selectedRules, ruleSeverityOverrides, nil
+}
+
+// getRulesConfig reads the rules config from the --config file, or returns the default one if the flag is not set
+func getRulesConfig() (*rules.RulesConfig, error) {
+	if *configFilepathFlag == "" {
+		return rules.NewDefaultRulesConfig(), nil
+	}
+
+	fileContent, err := os.ReadFile(*configFilepathFlag)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "attempted to read the rules config file with path '%v' but failed", *configFilepathFlag)
+	}
+	rulesConfig, err := rules.ReadRulesConfigFileContent(fileContent)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred reading the rules config file content from '%s'", *configFilepathFlag)
+	}
+	logrus.Infof("The rules config was read from '%s'", *configFilepathFlag)
+	return rulesConfig, nil
+}
+
+// getWaivers reads the waivers from the --waivers-file file, or from the default waivers file next to the catalog file
+// if the flag is not set. The default waivers file is optional, so there aren't waivers if it doesn't exist
+func getWaivers(packageCatalogYamlFilepath string) ([]*rules.Waiver, error) {
+	waiversFilepath := *waiversFilepathFlag
+	if waiversFilepath == "" {
+		waiversFilepath = filepath.Join(filepath.Dir(packageCatalogYamlFilepath), rules.WaiversFilename)
+		if _, err := os.Stat(waiversFilepath); errors.Is(err, os.ErrNotExist) {
+			logrus.Debugf("There isn't a waivers file on '%s', no failure will be waived", waiversFilepath)
+			return nil, nil
+		}
+	}
+
+	fileContent, err := os.ReadFile(waiversFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "attempted to read the waivers file with path '%v' but failed", waiversFilepath)
+	}
+	waivers, err := rules.ReadWaiversFileContent(fileContent)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred reading the waivers file content from '%s'", waiversFilepath)
+	}
+	logrus.Infof("'%d' waivers read from '%s'", len(waivers), waiversFilepath)
+	return waivers, nil
+}
+
+// logReport logs the report failures grouped by severity, the errors are logged last so they are easier to find
+func logReport(report *validator.Report, packageCatalogYamlFilepath string) {
+	logWaivedFailures(report, packageCatalogYamlFilepath)
+	logReportFailures(report, packageCatalogYamlFilepath, rules.SeverityInfo, "THE VALIDATOR REPORT THE FOLLOWING NOTES")
+	logReportFailures(report, packageCatalogYamlFilepath, rules.SeverityWarning, "THE VALIDATOR REPORT WARNINGS IN THE FOLLOWING RULES, THEY DON'T MAKE THE CATALOG INVALID BUT PLEASE FIX THEM")
+	logReportFailures(report, packageCatalogYamlFilepath, rules.SeverityError, "THE VALIDATOR REPORT FAILURES IN THE FOLLOWING RULES")
+}
+
+// writeReports writes the report in the --output format, as JUnit XML if --junit-out is set and in the GitHub step
+// summary if the output is for GitHub Actions
+func writeReports(report *validator.Report, outputFormat output.Format, packageCatalogYamlFilepath string) error {
+	if outputFormat != output.FormatText {
+		if err := writeReport(report, outputFormat, packageCatalogYamlFilepath, *outputFilepathFlag); err != nil {
+			return stacktrace.Propagate(err, "an error occurred writing the '%s' report", outputFormat)
+		}
+	}
+
+	if *junitOutFilepathFlag != "" {
+		if err := writeReport(report, output.FormatJUnit, packageCatalogYamlFilepath, *junitOutFilepathFlag); err != nil {
+			return stacktrace.Propagate(err, "an error occurred writing the '%s' report", output.FormatJUnit)
+		}
+	}
+
+	if gitHubStepSummaryFilepath := os.Getenv(gitHubStepSummaryEnvVarName); outputFormat == output.FormatGitHub && gitHubStepSummaryFilepath != "" {
+		if err := appendGitHubStepSummary(report, packageCatalogYamlFilepath, gitHubStepSummaryFilepath); err != nil {
+			return stacktrace.Propagate(err, "an error occurred writing the GitHub step summary")
+		}
+	}
+	return nil
+}
+
+// getPackageCatalogToValidate returns the packages selected with --all or --packages, or the new packages and the
+// catalog diff if there is a base catalog to compare with
+func getPackageCatalogToValidate(ctx context.Context, packageCatalogYamlFilepath string) (catalog.PackageCatalog, *catalog.CatalogDiff, error) {
+	isPackageSelection := *allFlag || len(*packageNamesFlag) > 0
+	if isPackageSelection && *baseCatalogFlag != "" {
+		return nil, nil, stacktrace.NewError("the --%s and --%s flags can't be used with --%s, because they select the packages to validate instead of comparing the catalog with a base catalog", allFlagName, packagesFlagName, baseFlagName)
+	}
+
+	if isPackageSelection {
+		logrus.Infof("Getting the selected Kurtosis packages from '%s'...", packageCatalogYamlFilepath)
+		packageCatalog, err := importer.GetPackagesInTheCatalog(packageCatalogYamlFilepath, *packageNamesFlag)
+		if err != nil {
+			return nil, nil, stacktrace.Propagate(err, "an error occurred getting the selected packages in the catalog")
+		}
+		logrus.Infof("...'%d' packages selected to validate.", len(packageCatalog))
+		return packageCatalog, nil, nil
+	}
+
+	baseCatalogSource, err := importer.NewBaseCatalogSource(*baseCatalogFlag, packageCatalogYamlFilepath)
+	if err != nil {
+		return nil, nil, stacktrace.Propagate(err, "an error occurred creating the base catalog source")
+	}
+	if baseCatalogSource == nil {
+		logrus.Infof("There isn't a base catalog set with --%s, all the packages will be validated", baseFlagName)
+	} else {
+		logrus.Infof("Comparing the catalog with the base catalog '%s'", baseCatalogSource)
+	}
+
+	logrus.Infof("Getting the new Kurtosis packages from '%s'...", packageCatalogYamlFilepath)
+	packageCatalog, catalogDiff, err := importer.GetNewPackageInTheCatalog(ctx, packageCatalogYamlFilepath, baseCatalogSource)
+	if err != nil {
+		return nil, nil, stacktrace.Propagate(err, "an error occurred getting the new packages in the catalog")
+	}
 	if packageCatalog == nil {
 		logrus.Infof("...there aren't new packages, in the catalog file '%s', to validate", packageCatalogYamlFilepath)
 	}
 	logrus.Info("...new packages added successfully obtained.")
+	if catalogDiff != nil {
+		logrus.Infof(
+			"Compared with the base catalog there are '%d' added, '%d' removed, '%d' renamed and '%d' changed packages",
+			len(catalogDiff.GetAddedPackages()),
+			len(catalogDiff.GetRemovedPackages()),
+			len(catalogDiff.GetRenamedPackages()),
+			len(catalogDiff.GetChangedPackages()),
+		)
+	}
+	return packageCatalog, catalogDiff, nil
+}
 
-	logrus.Info("Running the validations...")
-	rulesToValidate, err := rules.GetAll(ctx)
+// writeReport renders the report in the format to the output file, or to stdout if the filepath is empty
+func writeReport(report *validator.Report, format output.Format, packageCatalogYamlFilepath string, outputFilepath string) error {
+	renderer, err := output.NewReportRenderer(format, packageCatalogYamlFilepath)
 	if err != nil {
-		exitFailure(err)
+		return stacktrace.Propagate(err, "an error occurred creating the '%s' report renderer", format)
 	}
-	validatorObj := validator.NewValidator(packageCatalog, rulesToValidate)
-	validatorResult, err := validatorObj.Validate(ctx)
+
+	if outputFilepath == "" {
+		if err := renderer.Render(os.Stdout, report); err != nil {
+			return stacktrace.Propagate(err, "an error occurred writing the '%s' report to stdout", format)
+		}
+		return nil
+	}
+
+	outputFile, err := os.Create(outputFilepath)
 	if err != nil {
-		exitFailure(err)
+		return stacktrace.Propagate(err, "an error occurred creating the report file '%s'", outputFilepath)
+	}
+	defer outputFile.Close()
+	if err := renderer.Render(outputFile, report); err != nil {
+		return stacktrace.Propagate(err, "an error occurred writing the '%s' report to '%s'", format, outputFilepath)
 	}
+	logrus.Infof("The '%s' report was written to '%s'", format, outputFilepath)
+	return nil
+}
 
-	if !validatorResult.IsValidCatalog() {
-		logrus.Errorf("THE VALIDATOR REPORT FAILURES IN THE FOLLOWING RULES")
-		logrus.Errorf("======================================================================")
-		for ruleName, packagesWithFailures := range validatorResult.GetRulesResult() {
-			logrus.Errorf("-------------------------------------------------------------------")
-			logrus.Errorf("RULE: '%s'", ruleName)
-			logrus.Errorf("-------------------------------------------------------------------")
-			for packageName, failures := range packagesWithFailures {
-				logrus.Errorf("Package: '%s'", packageName)
-				for _, failure := range failures {
-					logrus.Errorf("  - %s", failure)
+// appendGitHubStepSummary appends the Markdown report to the GitHub Actions job summary file
+func appendGitHubStepSummary(report *validator.Report, packageCatalogYamlFilepath string, gitHubStepSummaryFilepath string) error {
+	renderer, err := output.NewReportRenderer(output.FormatMarkdown, packageCatalogYamlFilepath)
+	if err != nil {
+		return stacktrace.Propagate(err, "an error occurred creating the '%s' report renderer", output.FormatMarkdown)
+	}
+
+	gitHubStepSummaryFile, err := os.OpenFile(gitHubStepSummaryFilepath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, gitHubStepSummaryFilePerms)
+	if err != nil {
+		return stacktrace.Propagate(err, "an error occurred opening the GitHub step summary file '%s'", gitHubStepSummaryFilepath)
+	}
+	defer gitHubStepSummaryFile.Close()
+	if err := renderer.Render(gitHubStepSummaryFile, report); err != nil {
+		return stacktrace.Propagate(err, "an error occurred writing the '%s' report to the GitHub step summary file '%s'", output.FormatMarkdown, gitHubStepSummaryFilepath)
+	}
+	return nil
+}
+
+// logReportFailures logs the report failures with the severity, grouped by rule and package
+func logReportFailures(report *validator.Report, packageCatalogYamlFilepath string, severity rules.Severity, title string) {
+	if !report.HasFailuresWithSeverity(severity) {
+		return
+	}
+
+	logFunc := getSeverityLogFunc(severity)
+	logFunc(title)
+	logFunc("======================================================================")
+	for _, ruleName := range report.GetRuleNamesWithFailures() {
+		ruleHeaderWasLogged := false
+		for _, packageName := range report.GetPackageNamesWithFailures(ruleName) {
+			packageHeaderWasLogged := false
+			for _, failure := range report.GetFailures(ruleName, packageName) {
+				if failure.GetSeverity() != severity {
+					continue
 				}
+				if !ruleHeaderWasLogged {
+					logFunc("-------------------------------------------------------------------")
+					logFunc("RULE: '%s'", ruleName)
+					logFunc("-------------------------------------------------------------------")
+					ruleHeaderWasLogged = true
+				}
+				if !packageHeaderWasLogged {
+					logFunc("Package: '%s'", packageName)
+					packageHeaderWasLogged = true
+				}
+				logFailure(logFunc, failure, packageCatalogYamlFilepath)
 			}
 		}
-		logrus.Errorf("========================================================================")
-		exitFailure(stacktrace.NewError("the current package catalog is not valid."))
 	}
+	logFunc("========================================================================")
+}
 
-	logrus.Info("...all validations passed")
+// logWaivedFailures logs the failures accepted by a waiver, with the reason and the expiry date of the waiver
+func logWaivedFailures(report *validator.Report, packageCatalogYamlFilepath string) {
+	if len(report.GetWaivedFailures()) == 0 {
+		return
+	}
 
-	logrus.Exit(successExitCode)
+	logrus.Info("THE FOLLOWING FAILURES WERE WAIVED, THEY DON'T MAKE THE CATALOG INVALID")
+	logrus.Info("======================================================================")
+	for _, waivedFailure := range report.GetWaivedFailures() {
+		waiver := waivedFailure.GetWaiver()
+		logrus.Infof("Package: '%s', rule: '%s'", waivedFailure.GetPackageName(), waivedFailure.GetRuleName())
+		logFailure(logrus.Infof, waivedFailure.GetFailure(), packageCatalogYamlFilepath)
+		logrus.Infof("    waived until %s: %s", waiver.GetExpiryDate().Format(time.DateOnly), waiver.GetReason())
+	}
+	logrus.Info("========================================================================")
+}
+
+// logFailure logs the failure, e.g.:
+// '  - [ERROR] ICON_TOO_SMALL (kurtosis-package-icon.png): invalid image min size...'
+// '    catalog entry: kurtosis-package-catalog.yml:4:11'
+func logFailure(logFunc func(format string, args ...interface{}), failure *rules.Failure, packageCatalogYamlFilepath string) {
+	failureLocation := failure.GetFilepath()
+	if failure.HasPosition() {
+		failureLocation = fmt.Sprintf("%s:%d:%d", failureLocation, failure.GetLine(), failure.GetColumn())
+	}
+	if failureLocation != "" {
+		failureLocation = fmt.Sprintf(" (%s)", failureLocation)
+	}
+
+	logFunc("  - [%s] %s%s: %s", strings.ToUpper(string(failure.GetSeverity())), failure.GetCode(), failureLocation, failure.GetMessage())
+	if failure.HasCatalogPosition() {
+		logFunc("    catalog entry: %s:%d:%d", packageCatalogYamlFilepath, failure.GetCatalogLine(), failure.GetCatalogColumn())
+	}
+	if failure.GetRemediation() != "" {
+		logFunc("    hint: %s", failure.GetRemediation())
+	}
+}
+
+func getSeverityLogFunc(severity rules.Severity) func(format string, args ...interface{}) {
+	switch severity {
+	case rules.SeverityWarning:
+		return logrus.Warnf
+	case rules.SeverityInfo:
+		return logrus.Infof
+	}
+	return logrus.Errorf
 }
 
 func getKurtosisPackageCatalogYAMLFilepathFromArgs() (string, error) {
-	args := os.Args
-	if len(args) < 2 {
+	args := flag.Args()
+	if len(args) < 1 {
 		return "", stacktrace.NewError("expected to received the kurtosis package catalog YAML filepath as the first argument, but it was not received")
 	}
-	return args[1], nil
+	return args[0], nil
+}
+
+func createPackageSourceReader(ctx context.Context, sourceType string) (source.PackageSourceReader, error) {
+	switch sourceType {
+	case gitHubSourceType:
+		gitHubClient, err := github.CreateGithubClient(ctx)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "an error occurred creating the GitHub client")
+		}
+		return source.NewGitHubPackageSourceReader(gitHubClient), nil
+	case localSourceType:
+		sourceDirpath := *sourceDirpathFlag
+		if sourceDirpath == "" {
+			return nil, stacktrace.NewError("the --%s flag is required when the packages are read from the '%s' source", sourceDirFlagName, localSourceType)
+		}
+		if _, err := os.Stat(sourceDirpath); err != nil {
+			return nil, stacktrace.Propagate(err, "an error occurred checking for the package repositories directory existence on '%s'", sourceDirpath)
+		}
+		return source.NewLocalPackageSourceReader(sourceDirpath), nil
+	case gitSourceType:
+		gitCacheDirpath := *gitCacheDirpathFlag
+		if gitCacheDirpath == "" {
+			tempDirpath, err := os.MkdirTemp("", gitCacheTempDirPattern)
+			if err != nil {
+				return nil, stacktrace.Propagate(err, "an error occurred creating a temporary directory to clone the package repositories")
+			}
+			gitCacheDirpath = tempDirpath
+			// every command ends with logrus.Exit, which runs the exit handlers
+			logrus.RegisterExitHandler(func() {
+				if err := os.RemoveAll(tempDirpath); err != nil {
+					logrus.Warnf("an error occurred removing the temporary directory '%s' where the package repositories were cloned. Error was:\n%v", tempDirpath, err.Error())
+				}
+			})
+		}
+		logrus.Infof("Package repositories will be cloned from '%s' into '%s'", *gitRemoteBaseURLFlag, gitCacheDirpath)
+		return source.NewGitPackageSourceReader(*gitRemoteBaseURLFlag, gitCacheDirpath), nil
+	}
+	return nil, stacktrace.NewError("invalid package source '%s', the valid ones are '%s', '%s' and '%s'", sourceType, gitHubSourceType, localSourceType, gitSourceType)
 }
 
 func configureLogger() {
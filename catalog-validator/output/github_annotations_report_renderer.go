@@ -0,0 +1,91 @@
+package output
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/rules"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/validator"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+	"github.com/kurtosis-tech/stacktrace"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	gitHubErrorCommand   = "error"
+	gitHubWarningCommand = "warning"
+	gitHubNoticeCommand  = "notice"
+)
+
+var (
+	// see https://github.com/actions/toolkit/blob/main/packages/core/src/command.ts
+	gitHubCommandDataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	gitHubCommandPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+// gitHubAnnotationsReportRenderer writes each failure as a GitHub Actions workflow command, e.g.:
+// '::error file=kurtosis-package-catalog.yml,line=4,col=11,title=Valid package::...', so it's shown as an annotation
+// in the catalog entry of the package in the pull request
+type gitHubAnnotationsReportRenderer struct {
+	catalogFilepath string
+}
+
+func newGitHubAnnotationsReportRenderer(catalogFilepath string) *gitHubAnnotationsReportRenderer {
+	return &gitHubAnnotationsReportRenderer{catalogFilepath: catalogFilepath}
+}
+
+func (renderer *gitHubAnnotationsReportRenderer) Render(writer io.Writer, report *validator.Report) error {
+	for _, ruleName := range report.GetRuleNamesWithFailures() {
+		for _, packageName := range report.GetPackageNamesWithFailures(ruleName) {
+			for _, failure := range report.GetFailures(ruleName, packageName) {
+				properties := []string{
+					"file=" + gitHubCommandPropertyEscaper.Replace(filepath.ToSlash(renderer.catalogFilepath)),
+				}
+				if failure.HasCatalogPosition() {
+					properties = append(properties, fmt.Sprintf("line=%d", failure.GetCatalogLine()), fmt.Sprintf("col=%d", failure.GetCatalogColumn()))
+				}
+				properties = append(properties, "title="+gitHubCommandPropertyEscaper.Replace(fmt.Sprintf("%s: %s", ruleName, failure.GetCode())))
+				workflowCommand := fmt.Sprintf(
+					"::%s %s::%s\n",
+					getGitHubCommand(failure.GetSeverity()),
+					strings.Join(properties, ","),
+					gitHubCommandDataEscaper.Replace(getGitHubAnnotationMessage(packageName, failure)),
+				)
+				if _, err := io.WriteString(writer, workflowCommand); err != nil {
+					return stacktrace.Propagate(err, "an error occurred writing the GitHub annotation of package '%s' for rule '%s'", packageName, ruleName)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func getGitHubAnnotationMessage(packageName types.PackageName, failure *rules.Failure) string {
+	messageBuilder := &strings.Builder{}
+	messageBuilder.WriteString(fmt.Sprintf("Package '%s': %s", packageName, failure.GetMessage()))
+	if failure.GetFilepath() != "" {
+		messageBuilder.WriteString(fmt.Sprintf("\nFile: %s", formatFailureFileLocation(failure)))
+	}
+	if failure.GetRemediation() != "" {
+		messageBuilder.WriteString(fmt.Sprintf("\nHint: %s", failure.GetRemediation()))
+	}
+	return messageBuilder.String()
+}
+
+func getGitHubCommand(severity rules.Severity) string {
+	switch severity {
+	case rules.SeverityWarning:
+		return gitHubWarningCommand
+	case rules.SeverityInfo:
+		return gitHubNoticeCommand
+	}
+	return gitHubErrorCommand
+}
+
+// formatFailureFileLocation returns the failure file and position in the package repository, e.g. 'kurtosis.yml:2:7'
+func formatFailureFileLocation(failure *rules.Failure) string {
+	if !failure.HasPosition() {
+		return failure.GetFilepath()
+	}
+	return fmt.Sprintf("%s:%d:%d", failure.GetFilepath(), failure.GetLine(), failure.GetColumn())
+}
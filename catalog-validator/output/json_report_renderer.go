@@ -0,0 +1,119 @@
+package output
+
+import (
+	"encoding/json"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/rules"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/validator"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+	"github.com/kurtosis-tech/stacktrace"
+	"io"
+)
+
+const (
+	// JsonReportSchemaVersion is the version of the JSON report schema documented in 'json-report-schema.json'.
+	// The minor version is increased when fields are added and the major version when fields are removed or changed
+	JsonReportSchemaVersion = "1.2"
+
+	jsonIndent = "  "
+
+	jsonWaiverExpiryDateLayout = "2006-01-02"
+)
+
+type jsonReport struct {
+	SchemaVersion  string               `json:"schemaVersion"`
+	IsValidCatalog bool                 `json:"isValidCatalog"`
+	HasWarnings    bool                 `json:"hasWarnings"`
+	Rules          []string             `json:"rules"`
+	Packages       []string             `json:"packages"`
+	Failures       []*jsonReportFailure `json:"failures"`
+	WaivedFailures []*jsonReportFailure `json:"waivedFailures"`
+}
+
+type jsonReportFailure struct {
+	Rule          string `json:"rule"`
+	Package       string `json:"package"`
+	Code          string `json:"code"`
+	Severity      string `json:"severity"`
+	Message       string `json:"message"`
+	Filepath      string `json:"filepath,omitempty"`
+	Line          int    `json:"line,omitempty"`
+	Column        int    `json:"column,omitempty"`
+	Remediation   string `json:"remediation,omitempty"`
+	CatalogLine   int    `json:"catalogLine,omitempty"`
+	CatalogColumn int    `json:"catalogColumn,omitempty"`
+	WaiverReason  string `json:"waiverReason,omitempty"`
+	WaiverExpires string `json:"waiverExpires,omitempty"`
+}
+
+// jsonReportRenderer writes the report following the schema in 'json-report-schema.json'
+type jsonReportRenderer struct{}
+
+func newJsonReportRenderer() *jsonReportRenderer {
+	return &jsonReportRenderer{}
+}
+
+func (renderer *jsonReportRenderer) Render(writer io.Writer, report *validator.Report) error {
+	jsonReportObj := newJsonReport(report)
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", jsonIndent)
+	if err := encoder.Encode(jsonReportObj); err != nil {
+		return stacktrace.Propagate(err, "an error occurred encoding the JSON report")
+	}
+	return nil
+}
+
+func newJsonReport(report *validator.Report) *jsonReport {
+	ruleNames := []string{}
+	for _, ruleName := range report.GetRuleNames() {
+		ruleNames = append(ruleNames, string(ruleName))
+	}
+
+	packageNames := []string{}
+	for _, packageName := range report.GetPackageNames() {
+		packageNames = append(packageNames, string(packageName))
+	}
+
+	failures := []*jsonReportFailure{}
+	for _, ruleName := range report.GetRuleNamesWithFailures() {
+		for _, packageName := range report.GetPackageNamesWithFailures(ruleName) {
+			for _, failure := range report.GetFailures(ruleName, packageName) {
+				failures = append(failures, newJsonReportFailure(ruleName, packageName, failure))
+			}
+		}
+	}
+
+	waivedFailures := []*jsonReportFailure{}
+	for _, waivedFailure := range report.GetWaivedFailures() {
+		waivedFailureObj := newJsonReportFailure(waivedFailure.GetRuleName(), waivedFailure.GetPackageName(), waivedFailure.GetFailure())
+		waivedFailureObj.WaiverReason = waivedFailure.GetWaiver().GetReason()
+		waivedFailureObj.WaiverExpires = waivedFailure.GetWaiver().GetExpiryDate().Format(jsonWaiverExpiryDateLayout)
+		waivedFailures = append(waivedFailures, waivedFailureObj)
+	}
+
+	return &jsonReport{
+		SchemaVersion:  JsonReportSchemaVersion,
+		IsValidCatalog: report.IsValidCatalog(),
+		HasWarnings:    report.HasWarnings(),
+		Rules:          ruleNames,
+		Packages:       packageNames,
+		Failures:       failures,
+		WaivedFailures: waivedFailures,
+	}
+}
+
+func newJsonReportFailure(ruleName rules.RuleName, packageName types.PackageName, failure *rules.Failure) *jsonReportFailure {
+	return &jsonReportFailure{
+		Rule:          string(ruleName),
+		Package:       string(packageName),
+		Code:          string(failure.GetCode()),
+		Severity:      string(failure.GetSeverity()),
+		Message:       failure.GetMessage(),
+		Filepath:      failure.GetFilepath(),
+		Line:          failure.GetLine(),
+		Column:        failure.GetColumn(),
+		Remediation:   failure.GetRemediation(),
+		CatalogLine:   failure.GetCatalogLine(),
+		CatalogColumn: failure.GetCatalogColumn(),
+	}
+}
@@ -0,0 +1,152 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/rules"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/validator"
+	"github.com/kurtosis-tech/stacktrace"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	junitTestSuitesName = "catalog-validator"
+
+	// junitTimeFormat is the format of the 'time' attributes, the seconds with millisecond precision
+	junitTimeFormat = "%.3f"
+
+	junitIndent = "  "
+)
+
+type junitTestSuites struct {
+	XMLName    xml.Name          `xml:"testsuites"`
+	Name       string            `xml:"name,attr"`
+	Tests      int               `xml:"tests,attr"`
+	Failures   int               `xml:"failures,attr"`
+	Time       string            `xml:"time,attr"`
+	TestSuites []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	Tests     int              `xml:"tests,attr"`
+	Failures  int              `xml:"failures,attr"`
+	Time      string           `xml:"time,attr"`
+	TestCases []*junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitReportRenderer writes the report as JUnit XML, each rule is a test suite and each package checked is a test
+// case of it. A test case fails if the package has an error failure for the rule, the warnings and notes are
+// written to the test case output so they are visible without failing it
+type junitReportRenderer struct{}
+
+func newJUnitReportRenderer() *junitReportRenderer {
+	return &junitReportRenderer{}
+}
+
+func (renderer *junitReportRenderer) Render(writer io.Writer, report *validator.Report) error {
+	junitTestSuitesObj := newJUnitTestSuites(report)
+
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return stacktrace.Propagate(err, "an error occurred writing the JUnit report XML header")
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", junitIndent)
+	if err := encoder.Encode(junitTestSuitesObj); err != nil {
+		return stacktrace.Propagate(err, "an error occurred encoding the JUnit report")
+	}
+	if _, err := io.WriteString(writer, "\n"); err != nil {
+		return stacktrace.Propagate(err, "an error occurred writing the end of the JUnit report")
+	}
+	return nil
+}
+
+func newJUnitTestSuites(report *validator.Report) *junitTestSuites {
+	junitTestSuitesObj := &junitTestSuites{
+		Name:       junitTestSuitesName,
+		TestSuites: []*junitTestSuite{},
+	}
+
+	for _, ruleName := range report.GetRuleNames() {
+		junitTestSuiteObj := &junitTestSuite{
+			Name:      string(ruleName),
+			Time:      formatJUnitTime(report.GetRuleDuration(ruleName)),
+			TestCases: []*junitTestCase{},
+		}
+		for _, packageName := range report.GetReportedPackageNames() {
+			// the packages that weren't checked only have a test case in the suites of the rules that failed them
+			if !report.IsCheckedPackage(packageName) && len(report.GetFailures(ruleName, packageName)) == 0 {
+				continue
+			}
+			junitTestCaseObj := &junitTestCase{
+				ClassName: string(ruleName),
+				Name:      string(packageName),
+				Time:      formatJUnitTime(report.GetPackageCheckDuration(ruleName, packageName)),
+			}
+
+			errorMessages := []string{}
+			otherMessages := []string{}
+			for _, failure := range report.GetFailures(ruleName, packageName) {
+				if failure.IsError() {
+					errorMessages = append(errorMessages, getJUnitFailureMessage(failure))
+					if junitTestCaseObj.Failure == nil {
+						junitTestCaseObj.Failure = &junitFailure{
+							Message: failure.GetMessage(),
+							Type:    string(failure.GetCode()),
+						}
+					}
+					continue
+				}
+				otherMessages = append(otherMessages, getJUnitFailureMessage(failure))
+			}
+			if junitTestCaseObj.Failure != nil {
+				junitTestCaseObj.Failure.Text = strings.Join(errorMessages, "\n")
+				junitTestSuiteObj.Failures++
+			}
+			junitTestCaseObj.SystemOut = strings.Join(otherMessages, "\n")
+
+			junitTestSuiteObj.TestCases = append(junitTestSuiteObj.TestCases, junitTestCaseObj)
+			junitTestSuiteObj.Tests++
+		}
+
+		junitTestSuitesObj.TestSuites = append(junitTestSuitesObj.TestSuites, junitTestSuiteObj)
+		junitTestSuitesObj.Tests += junitTestSuiteObj.Tests
+		junitTestSuitesObj.Failures += junitTestSuiteObj.Failures
+	}
+	junitTestSuitesObj.Time = formatJUnitTime(report.GetDuration())
+
+	return junitTestSuitesObj
+}
+
+// getJUnitFailureMessage returns a single line with all the failure information, so every failure of the test case
+// can be written one per line
+func getJUnitFailureMessage(failure *rules.Failure) string {
+	failureMessage := fmt.Sprintf("[%s] %s: %s", failure.GetSeverity(), failure.GetCode(), failure.GetMessage())
+	if fileLocation := formatFailureFileLocation(failure); fileLocation != "" {
+		failureMessage = fmt.Sprintf("%s (%s)", failureMessage, fileLocation)
+	}
+	if remediation := failure.GetRemediation(); remediation != "" {
+		failureMessage = fmt.Sprintf("%s. Hint: %s", failureMessage, remediation)
+	}
+	return failureMessage
+}
+
+func formatJUnitTime(duration time.Duration) string {
+	return fmt.Sprintf(junitTimeFormat, duration.Seconds())
+}
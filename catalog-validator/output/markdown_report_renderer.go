@@ -0,0 +1,183 @@
+package output
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/rules"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/validator"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+	"github.com/kurtosis-tech/stacktrace"
+	"io"
+	"strings"
+)
+
+const (
+	markdownPassedCell  = "✅"
+	markdownFailedCell  = "❌"
+	markdownWarningCell = "⚠️"
+	markdownInfoCell    = "ℹ️"
+	markdownWaivedCell  = "🔕"
+
+	// markdownNotCheckedCell is used for the packages that weren't checked by the rule, like the removed packages
+	markdownNotCheckedCell = "-"
+
+	markdownTableColumnSeparator = " | "
+
+	markdownWaiverExpiryDateLayout = "2006-01-02"
+)
+
+var (
+	markdownTableCellEscaper = strings.NewReplacer("|", "\\|", "\n", "<br>")
+)
+
+// markdownReportRenderer writes a summary of the report to be posted as a pull request comment or to be written
+// on '$GITHUB_STEP_SUMMARY'. It has a table with the result of each rule for each package, followed by the failures
+type markdownReportRenderer struct{}
+
+func newMarkdownReportRenderer() *markdownReportRenderer {
+	return &markdownReportRenderer{}
+}
+
+func (renderer *markdownReportRenderer) Render(writer io.Writer, report *validator.Report) error {
+	markdownBuilder := &strings.Builder{}
+
+	markdownBuilder.WriteString("## Kurtosis package catalog validation\n\n")
+	switch {
+	case !report.IsValidCatalog():
+		markdownBuilder.WriteString(fmt.Sprintf("%s The catalog is **not valid**, please fix the errors below.\n\n", markdownFailedCell))
+	case report.HasWarnings():
+		markdownBuilder.WriteString(fmt.Sprintf("%s The catalog is valid, but there are warnings below.\n\n", markdownWarningCell))
+	default:
+		markdownBuilder.WriteString(fmt.Sprintf("%s All validations passed.\n\n", markdownPassedCell))
+	}
+
+	if len(report.GetReportedPackageNames()) == 0 {
+		markdownBuilder.WriteString("There aren't packages to validate.\n")
+	} else {
+		writeMarkdownResultsTable(markdownBuilder, report)
+		writeMarkdownFailures(markdownBuilder, report)
+		writeMarkdownWaivedFailures(markdownBuilder, report)
+	}
+
+	if _, err := io.WriteString(writer, markdownBuilder.String()); err != nil {
+		return stacktrace.Propagate(err, "an error occurred writing the Markdown report")
+	}
+	return nil
+}
+
+func writeMarkdownResultsTable(markdownBuilder *strings.Builder, report *validator.Report) {
+	headerCells := []string{"Package"}
+	separatorCells := []string{"---"}
+	for _, ruleName := range report.GetRuleNames() {
+		headerCells = append(headerCells, escapeMarkdownTableCell(string(ruleName)))
+		separatorCells = append(separatorCells, ":---:")
+	}
+	writeMarkdownTableRow(markdownBuilder, headerCells)
+	writeMarkdownTableRow(markdownBuilder, separatorCells)
+
+	for _, packageName := range report.GetReportedPackageNames() {
+		rowCells := []string{fmt.Sprintf("`%s`", escapeMarkdownTableCell(string(packageName)))}
+		for _, ruleName := range report.GetRuleNames() {
+			failures := report.GetFailures(ruleName, packageName)
+			if !report.IsCheckedPackage(packageName) && len(failures) == 0 {
+				rowCells = append(rowCells, markdownNotCheckedCell)
+				continue
+			}
+			rowCells = append(rowCells, getMarkdownResultCell(failures))
+		}
+		writeMarkdownTableRow(markdownBuilder, rowCells)
+	}
+	markdownBuilder.WriteString("\n")
+}
+
+func writeMarkdownFailures(markdownBuilder *strings.Builder, report *validator.Report) {
+	failuresByPackage := map[types.PackageName][]string{}
+	for _, ruleName := range report.GetRuleNamesWithFailures() {
+		for _, packageName := range report.GetPackageNamesWithFailures(ruleName) {
+			for _, failure := range report.GetFailures(ruleName, packageName) {
+				failureItem := fmt.Sprintf("- %s **%s** `%s`: %s", getMarkdownSeverityCell(failure.GetSeverity()), ruleName, failure.GetCode(), failure.GetMessage())
+				if failure.GetFilepath() != "" {
+					failureItem += fmt.Sprintf(" (`%s`)", formatFailureFileLocation(failure))
+				}
+				if failure.GetRemediation() != "" {
+					failureItem += fmt.Sprintf("\n  - _Hint: %s_", failure.GetRemediation())
+				}
+				failuresByPackage[packageName] = append(failuresByPackage[packageName], failureItem)
+			}
+		}
+	}
+	if len(failuresByPackage) == 0 {
+		return
+	}
+
+	markdownBuilder.WriteString("### Failures\n\n")
+	for _, packageName := range report.GetReportedPackageNames() {
+		packageFailureItems, found := failuresByPackage[packageName]
+		if !found {
+			continue
+		}
+		markdownBuilder.WriteString(fmt.Sprintf("#### `%s`\n\n", packageName))
+		markdownBuilder.WriteString(strings.Join(packageFailureItems, "\n"))
+		markdownBuilder.WriteString("\n\n")
+	}
+}
+
+// writeMarkdownWaivedFailures lists the failures accepted by a waiver, so the reviewers can see what is tolerated
+func writeMarkdownWaivedFailures(markdownBuilder *strings.Builder, report *validator.Report) {
+	if len(report.GetWaivedFailures()) == 0 {
+		return
+	}
+
+	markdownBuilder.WriteString("### Waived failures\n\n")
+	for _, waivedFailure := range report.GetWaivedFailures() {
+		waiver := waivedFailure.GetWaiver()
+		markdownBuilder.WriteString(fmt.Sprintf(
+			"- %s `%s` **%s** `%s`: %s\n  - _Waived until %s: %s_\n",
+			markdownWaivedCell,
+			waivedFailure.GetPackageName(),
+			waivedFailure.GetRuleName(),
+			waivedFailure.GetFailure().GetCode(),
+			waivedFailure.GetFailure().GetMessage(),
+			waiver.GetExpiryDate().Format(markdownWaiverExpiryDateLayout),
+			waiver.GetReason(),
+		))
+	}
+	markdownBuilder.WriteString("\n")
+}
+
+func writeMarkdownTableRow(markdownBuilder *strings.Builder, cells []string) {
+	markdownBuilder.WriteString("| ")
+	markdownBuilder.WriteString(strings.Join(cells, markdownTableColumnSeparator))
+	markdownBuilder.WriteString(" |\n")
+}
+
+// getMarkdownResultCell returns the cell for the most severe of the failures, or the passed cell if there aren't any
+func getMarkdownResultCell(failures []*rules.Failure) string {
+	resultCell := markdownPassedCell
+	for _, failure := range failures {
+		switch failure.GetSeverity() {
+		case rules.SeverityError:
+			return markdownFailedCell
+		case rules.SeverityWarning:
+			resultCell = markdownWarningCell
+		case rules.SeverityInfo:
+			if resultCell == markdownPassedCell {
+				resultCell = markdownInfoCell
+			}
+		}
+	}
+	return resultCell
+}
+
+func getMarkdownSeverityCell(severity rules.Severity) string {
+	switch severity {
+	case rules.SeverityWarning:
+		return markdownWarningCell
+	case rules.SeverityInfo:
+		return markdownInfoCell
+	}
+	return markdownFailedCell
+}
+
+func escapeMarkdownTableCell(cell string) string {
+	return markdownTableCellEscaper.Replace(cell)
+}
@@ -0,0 +1,57 @@
+package output
+
+import (
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/validator"
+	"github.com/kurtosis-tech/stacktrace"
+	"io"
+)
+
+type Format string
+
+const (
+	// FormatText is the human-readable report logged by the validator, it's not rendered by this package
+	FormatText  Format = "text"
+	FormatJSON  Format = "json"
+	FormatSARIF Format = "sarif"
+	// FormatGitHub writes GitHub Actions workflow commands that annotate the catalog file
+	FormatGitHub   Format = "github"
+	FormatMarkdown Format = "markdown"
+	FormatJUnit    Format = "junit"
+)
+
+// ReportRenderer writes the validator report in a machine-readable format
+type ReportRenderer interface {
+	Render(writer io.Writer, report *validator.Report) error
+}
+
+// ParseFormat returns the format matching the string, or an error if it isn't a valid one
+func ParseFormat(formatStr string) (Format, error) {
+	for _, format := range GetAllFormats() {
+		if formatStr == string(format) {
+			return format, nil
+		}
+	}
+	return "", stacktrace.NewError("invalid output format '%s', the valid ones are %v", formatStr, GetAllFormats())
+}
+
+func GetAllFormats() []Format {
+	return []Format{FormatText, FormatJSON, FormatSARIF, FormatGitHub, FormatMarkdown, FormatJUnit}
+}
+
+// NewReportRenderer returns the renderer of the format, the catalog filepath is the one validated
+// and it's used as the location of the failures
+func NewReportRenderer(format Format, catalogFilepath string) (ReportRenderer, error) {
+	switch format {
+	case FormatJSON:
+		return newJsonReportRenderer(), nil
+	case FormatSARIF:
+		return newSarifReportRenderer(catalogFilepath), nil
+	case FormatGitHub:
+		return newGitHubAnnotationsReportRenderer(catalogFilepath), nil
+	case FormatMarkdown:
+		return newMarkdownReportRenderer(), nil
+	case FormatJUnit:
+		return newJUnitReportRenderer(), nil
+	}
+	return nil, stacktrace.NewError("there isn't a renderer for output format '%s'", format)
+}
@@ -0,0 +1,194 @@
+package output
+
+import (
+	"encoding/json"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/rules"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/validator"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+	"github.com/kurtosis-tech/stacktrace"
+	"io"
+	"path/filepath"
+)
+
+const (
+	sarifSchemaURI = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion   = "2.1.0"
+
+	sarifToolName           = "catalog-validator"
+	sarifToolInformationURI = "https://github.com/kurtosis-tech/kurtosis-package-catalog"
+
+	sarifErrorLevel   = "error"
+	sarifWarningLevel = "warning"
+	sarifNoteLevel    = "note"
+
+	// sarifExternalSuppressionKind is used for the waived failures because the waivers are in the waivers file
+	sarifExternalSuppressionKind = "external"
+)
+
+type sarifLog struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    *sarifTool     `json:"tool"`
+	Results []*sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver *sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string                `json:"name"`
+	InformationURI string                `json:"informationUri"`
+	Rules          []*sarifReportingRule `json:"rules"`
+}
+
+type sarifReportingRule struct {
+	Id string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleId       string                 `json:"ruleId"`
+	Level        string                 `json:"level"`
+	Message      *sarifMessage          `json:"message"`
+	Locations    []*sarifLocation       `json:"locations"`
+	Suppressions []*sarifSuppression    `json:"suppressions,omitempty"`
+	Properties   *sarifResultProperties `json:"properties"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation *sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion           `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// sarifResultProperties keeps the failure information that doesn't have a place in the SARIF result
+type sarifResultProperties struct {
+	Package         string `json:"package"`
+	Code            string `json:"code"`
+	PackageFilepath string `json:"packageFilepath,omitempty"`
+	Remediation     string `json:"remediation,omitempty"`
+}
+
+// sarifReportRenderer writes the report as a SARIF 2.1.0 log, which can be uploaded to GitHub code scanning.
+// Each failure is a result located in the catalog file, because it's the file changed by the catalog contributors
+type sarifReportRenderer struct {
+	catalogFilepath string
+}
+
+func newSarifReportRenderer(catalogFilepath string) *sarifReportRenderer {
+	return &sarifReportRenderer{catalogFilepath: catalogFilepath}
+}
+
+func (renderer *sarifReportRenderer) Render(writer io.Writer, report *validator.Report) error {
+	reportingRules := []*sarifReportingRule{}
+	for _, ruleName := range report.GetRuleNames() {
+		reportingRules = append(reportingRules, &sarifReportingRule{Id: string(ruleName)})
+	}
+
+	results := []*sarifResult{}
+	for _, ruleName := range report.GetRuleNamesWithFailures() {
+		for _, packageName := range report.GetPackageNamesWithFailures(ruleName) {
+			for _, failure := range report.GetFailures(ruleName, packageName) {
+				results = append(results, renderer.newSarifResult(ruleName, packageName, failure))
+			}
+		}
+	}
+
+	// the waived failures are reported as suppressed results, so they aren't shown as alerts
+	for _, waivedFailure := range report.GetWaivedFailures() {
+		result := renderer.newSarifResult(waivedFailure.GetRuleName(), waivedFailure.GetPackageName(), waivedFailure.GetFailure())
+		result.Suppressions = []*sarifSuppression{
+			{Kind: sarifExternalSuppressionKind, Justification: waivedFailure.GetWaiver().GetReason()},
+		}
+		results = append(results, result)
+	}
+
+	sarifLogObj := &sarifLog{
+		Schema:  sarifSchemaURI,
+		Version: sarifVersion,
+		Runs: []*sarifRun{
+			{
+				Tool: &sarifTool{
+					Driver: &sarifDriver{
+						Name:           sarifToolName,
+						InformationURI: sarifToolInformationURI,
+						Rules:          reportingRules,
+					},
+				},
+				Results: results,
+			},
+		},
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", jsonIndent)
+	if err := encoder.Encode(sarifLogObj); err != nil {
+		return stacktrace.Propagate(err, "an error occurred encoding the SARIF report")
+	}
+	return nil
+}
+
+func (renderer *sarifReportRenderer) newSarifResult(ruleName rules.RuleName, packageName types.PackageName, failure *rules.Failure) *sarifResult {
+	return &sarifResult{
+		RuleId:    string(ruleName),
+		Level:     getSarifLevel(failure.GetSeverity()),
+		Message:   &sarifMessage{Text: failure.GetMessage()},
+		Locations: []*sarifLocation{renderer.getCatalogLocation(failure)},
+		Properties: &sarifResultProperties{
+			Package:         string(packageName),
+			Code:            string(failure.GetCode()),
+			PackageFilepath: failure.GetFilepath(),
+			Remediation:     failure.GetRemediation(),
+		},
+	}
+}
+
+// getCatalogLocation returns the location of the package entry in the catalog file, or the whole file if the failure
+// isn't about a single entry
+func (renderer *sarifReportRenderer) getCatalogLocation(failure *rules.Failure) *sarifLocation {
+	var region *sarifRegion
+	if failure.HasCatalogPosition() {
+		region = &sarifRegion{StartLine: failure.GetCatalogLine(), StartColumn: failure.GetCatalogColumn()}
+	}
+	return &sarifLocation{
+		PhysicalLocation: &sarifPhysicalLocation{
+			ArtifactLocation: &sarifArtifactLocation{URI: filepath.ToSlash(renderer.catalogFilepath)},
+			Region:           region,
+		},
+	}
+}
+
+func getSarifLevel(severity rules.Severity) string {
+	switch severity {
+	case rules.SeverityWarning:
+		return sarifWarningLevel
+	case rules.SeverityInfo:
+		return sarifNoteLevel
+	}
+	return sarifErrorLevel
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/rules"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"os"
+)
+
+// runRecordRefSHAs resolves the ref of the catalog packages without a recorded ref-sha and writes the commit SHA each
+// one resolves to in the 'ref-sha' field of its entry, so the 'Valid package ref' rule detects when the ref moves
+func runRecordRefSHAs(ctx context.Context) {
+	packageCatalogYamlFilepath, err := getKurtosisPackageCatalogYAMLFilepathFromArgs()
+	if err != nil {
+		exitFailure(err)
+	}
+
+	catalogFileInfo, err := os.Stat(packageCatalogYamlFilepath)
+	if err != nil {
+		exitFailure(stacktrace.Propagate(err, "an error occurred checking for Kurtosis package catalog YAML file existence on '%s'", packageCatalogYamlFilepath))
+	}
+	fileContent, err := os.ReadFile(packageCatalogYamlFilepath)
+	if err != nil {
+		exitFailure(stacktrace.Propagate(err, "attempted to read file with path '%v' but failed", packageCatalogYamlFilepath))
+	}
+	packageCatalog, err := catalog.GetPackageCatalogFromYamlFileContent(fileContent)
+	if err != nil {
+		exitFailure(stacktrace.Propagate(err, "an error occurred reading the Kurtosis package catalog YAML file content from '%s'", packageCatalogYamlFilepath))
+	}
+
+	packageSourceReader, err := createPackageSourceReader(ctx, *sourceTypeFlag)
+	if err != nil {
+		exitFailure(stacktrace.Propagate(err, "an error occurred creating the package source reader"))
+	}
+
+	logrus.Infof("Resolving the refs without '%s' of the '%d' packages in '%s'...", catalog.PackageRefSHAKey, len(packageCatalog), packageCatalogYamlFilepath)
+	refSHAs, err := rules.ResolveMissingRefSHAs(ctx, packageSourceReader, packageCatalog)
+	if err != nil {
+		exitFailure(stacktrace.Propagate(err, "an error occurred resolving the refs of the catalog packages"))
+	}
+	if len(refSHAs) == 0 {
+		logrus.Infof("...there isn't any ref without '%s' to record.", catalog.PackageRefSHAKey)
+		logrus.Exit(successExitCode)
+	}
+
+	newFileContent, err := catalog.AddPackageRefSHAs(fileContent, refSHAs)
+	if err != nil {
+		exitFailure(stacktrace.Propagate(err, "an error occurred adding the '%s' fields to the catalog file content", catalog.PackageRefSHAKey))
+	}
+	if err := os.WriteFile(packageCatalogYamlFilepath, newFileContent, catalogFileInfo.Mode().Perm()); err != nil {
+		exitFailure(stacktrace.Propagate(err, "an error occurred writing the catalog file '%s'", packageCatalogYamlFilepath))
+	}
+	logrus.Infof("...the '%s' field was recorded in '%d' package entries of '%s'.", catalog.PackageRefSHAKey, len(refSHAs), packageCatalogYamlFilepath)
+	logrus.Exit(successExitCode)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/rules"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// runRulesList prints the name, description, severity and parameters of the rules that would be checked with the
+// same flags, so the rules config and the --only, --skip and --rule-severity flags can be tried without validating
+func runRulesList(ctx context.Context) {
+	// the rules don't read any package nor the catalog while they are listed, so they don't need a package source reader
+	rulesToList, ruleSeverityOverrides, err := getRulesToValidate(ctx, nil, nil)
+	if err != nil {
+		exitFailure(err)
+	}
+
+	for _, rule := range rulesToList {
+		severity := rule.GetDefaultSeverity()
+		if severityOverride, found := ruleSeverityOverrides[rule.GetName()]; found {
+			severity = severityOverride
+		}
+		if err := writeRuleDescription(os.Stdout, rule, severity); err != nil {
+			exitFailure(stacktrace.Propagate(err, "an error occurred writing the description of rule '%s'", rule.GetName()))
+		}
+	}
+	logrus.Exit(successExitCode)
+}
+
+// writeRuleDescription writes the rule with its parameters sorted by name, e.g.:
+// Valid package icon
+//
+//	description: checks that the package icon is a square PNG image within the size bounds...
+//	severity: error
+//	parameters:
+//	  max-size: 1024
+//	  min-size: 120
+func writeRuleDescription(writer io.Writer, rule rules.Rule, severity rules.Severity) error {
+	ruleDescriptionLines := []string{
+		string(rule.GetName()),
+		fmt.Sprintf("  description: %s", rule.GetDescription()),
+		fmt.Sprintf("  severity: %s", severity),
+	}
+
+	parameters := rule.GetParameters()
+	if len(parameters) == 0 {
+		ruleDescriptionLines = append(ruleDescriptionLines, "  parameters: none")
+	} else {
+		parameterNames := []string{}
+		for parameterName := range parameters {
+			parameterNames = append(parameterNames, parameterName)
+		}
+		sort.Strings(parameterNames)
+		ruleDescriptionLines = append(ruleDescriptionLines, "  parameters:")
+		for _, parameterName := range parameterNames {
+			ruleDescriptionLines = append(ruleDescriptionLines, fmt.Sprintf("    %s: %s", parameterName, formatRuleParameterValue(parameters[parameterName])))
+		}
+	}
+
+	if _, err := fmt.Fprintln(writer, strings.Join(ruleDescriptionLines, "\n")); err != nil {
+		return stacktrace.Propagate(err, "an error occurred writing the rule description")
+	}
+	return nil
+}
+
+// formatRuleParameterValue quotes the strings of the list parameters, so the ones with whitespaces can be told apart
+func formatRuleParameterValue(value interface{}) string {
+	stringsValue, isStringList := value.([]string)
+	if !isStringList {
+		return fmt.Sprintf("%v", value)
+	}
+	quotedStrings := []string{}
+	for _, stringValue := range stringsValue {
+		quotedStrings = append(quotedStrings, strconv.Quote(stringValue))
+	}
+	return "[" + strings.Join(quotedStrings, ", ") + "]"
+}
@@ -11,7 +11,7 @@ app_root_dirpath="$(dirname "${script_dirpath}")"
 # ==================================================================================================
 BUILD_DIRNAME="build"
 
-MAIN_GO_FILEPATH="${app_root_dirpath}/main.go"
+MAIN_PACKAGE_DIRPATH="${app_root_dirpath}"
 MAIN_BINARY_OUTPUT_FILENAME="catalog-validator"
 MAIN_BINARY_OUTPUT_FILEPATH="${app_root_dirpath}/${BUILD_DIRNAME}/${MAIN_BINARY_OUTPUT_FILENAME}"
 
@@ -32,8 +32,8 @@ fi
 echo "Tests succeeded"
 
 # Build binary for packaging inside an Alpine Linux image
-echo "Building server main.go '${MAIN_GO_FILEPATH}'..."
-if ! CGO_ENABLED=0 GOOS=linux GOARCH=amd64 go build -o "${MAIN_BINARY_OUTPUT_FILEPATH}" "${MAIN_GO_FILEPATH}"; then
+echo "Building server main package '${MAIN_PACKAGE_DIRPATH}'..."
+if ! CGO_ENABLED=0 GOOS=linux GOARCH=amd64 go build -o "${MAIN_BINARY_OUTPUT_FILEPATH}" "${MAIN_PACKAGE_DIRPATH}"; then
   echo "Error: An error occurred building the server code" >&2
   exit 1
 fi
@@ -0,0 +1,36 @@
+package source
+
+type FileType string
+
+const (
+	FileTypeFile      FileType = "file"
+	FileTypeDirectory FileType = "dir"
+)
+
+type FileInfo struct {
+	// path is relative to the repository root
+	path     string
+	fileType FileType
+	// size is in bytes, it's zero for directories
+	size int64
+}
+
+func newFileInfo(path string, fileType FileType, size int64) *FileInfo {
+	return &FileInfo{path: path, fileType: fileType, size: size}
+}
+
+func (fileInfo *FileInfo) GetPath() string {
+	return fileInfo.path
+}
+
+func (fileInfo *FileInfo) GetType() FileType {
+	return fileInfo.fileType
+}
+
+func (fileInfo *FileInfo) GetSize() int64 {
+	return fileInfo.size
+}
+
+func (fileInfo *FileInfo) IsDirectory() bool {
+	return fileInfo.fileType == FileTypeDirectory
+}
@@ -0,0 +1,358 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	gitBinaryName = "git"
+
+	bareRepositoryDirnameSuffix = ".git"
+	originRemoteName            = "origin"
+	fetchHeadRef                = "FETCH_HEAD"
+	shallowCloneDepthArg        = "--depth=1"
+
+	// disables the credentials prompt so a missing repository fails instead of hanging
+	disableGitTerminalPromptEnvVar = "GIT_TERMINAL_PROMPT=0"
+
+	gitTreeObjectType = "tree"
+	gitObjectSizeNone = "-"
+
+	// ls-tree -l output is '<mode> SP <type> SP <object> SP <object size> TAB <file>'
+	lsTreeMetadataAndPathSeparator = "\t"
+	lsTreeNumberOfMetadataFields   = 4
+	lsTreeTypeFieldIndex           = 1
+	lsTreeObjectFieldIndex         = 2
+	lsTreeSizeFieldIndex           = 3
+	lsTreeEntriesSeparator         = "\x00"
+)
+
+var (
+	// gitNotFoundOutputRegex matches the git outputs of a clone or fetch failing because the remote repository or the
+	// ref don't exist, the other failures (network, authentication, disk) aren't a missing file
+	gitNotFoundOutputRegex = regexp.MustCompile(`(?i)repository not found|repository '[^']*' not found|does not appear to be a git repository|couldn't find remote ref`)
+)
+
+// gitPackageSourceReader serves the package repositories content from local bare git repositories, each repository
+// is shallow-cloned once on '<cache dirpath>/<repository owner>/<repository name>.git' the first time it's read.
+// Repositories already present on that path (e.g. a cache of a previous run or existing bare mirrors) aren't cloned
+// again, their refs are fetched the first time they're resolved so a moved branch or tag isn't read from a stale clone.
+// Refs other than the default branch are fetched on demand
+type gitPackageSourceReader struct {
+	// remoteBaseURL is the URL where the repositories are cloned from, appending '/<repository owner>/<repository name>' to it
+	// e.g. 'https://github.com' or 'file:///path/to/remotes'
+	remoteBaseURL string
+
+	cacheDirpath string
+
+	// repositoryMutexes serializes the git commands that modify the same local repository
+	repositoryMutexesMutex *sync.Mutex
+	repositoryMutexes      map[string]*sync.Mutex
+
+	// clonedRepositories are the repositories cloned by this reader, their refs are up to date without fetching them
+	clonedRepositoriesMutex *sync.RWMutex
+	clonedRepositories      map[string]bool
+
+	// resolvedRefs caches the commit SHA for each '<repository owner>/<repository name>@<ref>', there is no need to run
+	// git again once resolved
+	resolvedRefsMutex *sync.RWMutex
+	resolvedRefs      map[string]string
+}
+
+func NewGitPackageSourceReader(remoteBaseURL string, cacheDirpath string) *gitPackageSourceReader {
+	return &gitPackageSourceReader{
+		remoteBaseURL:           strings.TrimSuffix(remoteBaseURL, rootPath),
+		cacheDirpath:            cacheDirpath,
+		repositoryMutexesMutex:  &sync.Mutex{},
+		repositoryMutexes:       map[string]*sync.Mutex{},
+		clonedRepositoriesMutex: &sync.RWMutex{},
+		clonedRepositories:      map[string]bool{},
+		resolvedRefsMutex:       &sync.RWMutex{},
+		resolvedRefs:            map[string]string{},
+	}
+}
+
+func (reader *gitPackageSourceReader) ReadFile(ctx context.Context, repository *PackageRepository, filepath string) ([]byte, error) {
+	treeEntry, err := reader.getTreeEntry(ctx, repository, filepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred getting file '%s' from repository '%s'", filepath, repository)
+	}
+	if treeEntry.fileInfo.IsDirectory() {
+		return nil, stacktrace.NewError("expected '%s' in repository '%s' to be a file, but it's a directory", filepath, repository)
+	}
+
+	fileContent, err := reader.runGitCommand(ctx, repository, "cat-file", "blob", treeEntry.objectId)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred reading the content of file '%s' from repository '%s'", filepath, repository)
+	}
+	return fileContent, nil
+}
+
+func (reader *gitPackageSourceReader) ListDirectory(ctx context.Context, repository *PackageRepository, dirpath string) ([]*FileInfo, error) {
+	treeEntry, err := reader.getTreeEntry(ctx, repository, dirpath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred getting directory '%s' from repository '%s'", dirpath, repository)
+	}
+	if !treeEntry.fileInfo.IsDirectory() {
+		return nil, stacktrace.NewError("expected '%s' in repository '%s' to be a directory, but it's a file", dirpath, repository)
+	}
+
+	commitSHA, err := reader.ResolveRef(ctx, repository)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred resolving the ref of repository '%s'", repository)
+	}
+
+	lsTreeArgs := []string{"ls-tree", "-l", "-z", commitSHA}
+	cleanDirpath := cleanRepositoryPath(dirpath)
+	if cleanDirpath != "" {
+		// the trailing slash lists the directory content instead of the directory itself
+		lsTreeArgs = append(lsTreeArgs, "--", cleanDirpath+rootPath)
+	}
+	lsTreeOutput, err := reader.runGitCommand(ctx, repository, lsTreeArgs...)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred listing directory '%s' from repository '%s'", dirpath, repository)
+	}
+
+	treeEntries, err := parseLsTreeOutput(lsTreeOutput)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred parsing the content of directory '%s' from repository '%s'", dirpath, repository)
+	}
+
+	fileInfos := make([]*FileInfo, len(treeEntries))
+	for index, entry := range treeEntries {
+		fileInfos[index] = entry.fileInfo
+	}
+	return fileInfos, nil
+}
+
+func (reader *gitPackageSourceReader) Stat(ctx context.Context, repository *PackageRepository, filepath string) (*FileInfo, error) {
+	treeEntry, err := reader.getTreeEntry(ctx, repository, filepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred getting the information of '%s' from repository '%s'", filepath, repository)
+	}
+	return treeEntry.fileInfo, nil
+}
+
+func (reader *gitPackageSourceReader) ResolveRef(ctx context.Context, repository *PackageRepository) (string, error) {
+	resolvedRefKey := getRepositoryKey(repository) + "@" + repository.GetRefOrDefaultBranch()
+
+	reader.resolvedRefsMutex.RLock()
+	commitSHA, found := reader.resolvedRefs[resolvedRefKey]
+	reader.resolvedRefsMutex.RUnlock()
+	if found {
+		return commitSHA, nil
+	}
+
+	if err := reader.ensureRepositoryIsCloned(ctx, repository); err != nil {
+		return "", stacktrace.Propagate(err, "an error occurred getting a local clone of repository '%s'", repository)
+	}
+
+	commitSHA, err := reader.resolveRefInLocalRepository(ctx, repository)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "an error occurred resolving ref '%s' in repository '%s'", repository.GetRefOrDefaultBranch(), repository)
+	}
+
+	reader.resolvedRefsMutex.Lock()
+	reader.resolvedRefs[resolvedRefKey] = commitSHA
+	reader.resolvedRefsMutex.Unlock()
+
+	return commitSHA, nil
+}
+
+type gitTreeEntry struct {
+	fileInfo *FileInfo
+	objectId string
+}
+
+func (reader *gitPackageSourceReader) getTreeEntry(ctx context.Context, repository *PackageRepository, filepath string) (*gitTreeEntry, error) {
+	commitSHA, err := reader.ResolveRef(ctx, repository)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred resolving the ref of repository '%s'", repository)
+	}
+
+	cleanFilepath := cleanRepositoryPath(filepath)
+	if cleanFilepath == "" {
+		return &gitTreeEntry{fileInfo: newFileInfo(cleanFilepath, FileTypeDirectory, 0), objectId: commitSHA}, nil
+	}
+
+	lsTreeOutput, err := reader.runGitCommand(ctx, repository, "ls-tree", "-l", "-z", commitSHA, "--", cleanFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred getting '%s' from the git tree", cleanFilepath)
+	}
+
+	treeEntries, err := parseLsTreeOutput(lsTreeOutput)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred parsing the git tree entry of '%s'", cleanFilepath)
+	}
+	if len(treeEntries) == 0 {
+		return nil, newFileNotFoundError(repository, filepath)
+	}
+	return treeEntries[0], nil
+}
+
+func (reader *gitPackageSourceReader) ensureRepositoryIsCloned(ctx context.Context, repository *PackageRepository) error {
+	repositoryMutex := reader.getRepositoryMutex(repository)
+	repositoryMutex.Lock()
+	defer repositoryMutex.Unlock()
+
+	localRepositoryDirpath := reader.getLocalRepositoryDirpath(repository)
+	if _, err := os.Stat(localRepositoryDirpath); err == nil {
+		return nil
+	} else if !os.IsNotExist(err) {
+		return stacktrace.Propagate(err, "an error occurred checking for the local repository existence on '%s'", localRepositoryDirpath)
+	}
+
+	remoteURL := reader.getRemoteURL(repository)
+	logrus.Debugf("Cloning repository '%s' from '%s' into '%s'...", repository, remoteURL, localRepositoryDirpath)
+	if _, err := runGitCommand(ctx, "clone", "--bare", "--quiet", shallowCloneDepthArg, remoteURL, localRepositoryDirpath); err != nil {
+		if removeErr := os.RemoveAll(localRepositoryDirpath); removeErr != nil {
+			logrus.Warnf("an error occurred removing the partial clone of repository '%s' on '%s'. Error was:\n%v", repository, localRepositoryDirpath, removeErr.Error())
+		}
+		return stacktrace.Propagate(err, "an error occurred cloning repository '%s' from '%s'", repository, remoteURL)
+	}
+
+	reader.clonedRepositoriesMutex.Lock()
+	reader.clonedRepositories[getRepositoryKey(repository)] = true
+	reader.clonedRepositoriesMutex.Unlock()
+	logrus.Debugf("...repository '%s' cloned.", repository)
+	return nil
+}
+
+// resolveRefInLocalRepository returns the commit SHA of the ref, fetching it from the remote if the local repository
+// doesn't have it or wasn't cloned by this reader, as the ref could have moved since the repository was cloned. A
+// commit SHA can't move, so it's only fetched if it's missing
+func (reader *gitPackageSourceReader) resolveRefInLocalRepository(ctx context.Context, repository *PackageRepository) (string, error) {
+	repositoryMutex := reader.getRepositoryMutex(repository)
+	repositoryMutex.Lock()
+	defer repositoryMutex.Unlock()
+
+	ref := repository.GetRefOrDefaultBranch()
+	localCommitSHA, localErr := reader.revParseCommit(ctx, repository, ref)
+	if localErr == nil && (reader.isClonedRepository(repository) || localCommitSHA == ref) {
+		return localCommitSHA, nil
+	}
+
+	logrus.Debugf("Fetching ref '%s' of repository '%s'...", ref, repository)
+	if _, err := reader.runGitCommand(ctx, repository, "fetch", "--quiet", shallowCloneDepthArg, originRemoteName, ref); err != nil {
+		if localErr == nil && !IsFileNotFoundErr(err) {
+			logrus.Warnf("Ref '%s' of repository '%s' could not be fetched, the commit in the local repository is used. Error was:\n%v", ref, repository, err.Error())
+			return localCommitSHA, nil
+		}
+		return "", stacktrace.Propagate(err, "an error occurred fetching ref '%s' of repository '%s'", ref, repository)
+	}
+	commitSHA, err := reader.revParseCommit(ctx, repository, fetchHeadRef)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "an error occurred resolving the commit of ref '%s' after fetching it", ref)
+	}
+	return commitSHA, nil
+}
+
+func (reader *gitPackageSourceReader) revParseCommit(ctx context.Context, repository *PackageRepository, ref string) (string, error) {
+	revParseOutput, err := reader.runGitCommand(ctx, repository, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return "", stacktrace.Propagate(err, "an error occurred resolving ref '%s'", ref)
+	}
+	return strings.TrimSpace(string(revParseOutput)), nil
+}
+
+func (reader *gitPackageSourceReader) runGitCommand(ctx context.Context, repository *PackageRepository, args ...string) ([]byte, error) {
+	gitDirArgs := []string{"--git-dir", reader.getLocalRepositoryDirpath(repository)}
+	return runGitCommand(ctx, append(gitDirArgs, args...)...)
+}
+
+func (reader *gitPackageSourceReader) isClonedRepository(repository *PackageRepository) bool {
+	reader.clonedRepositoriesMutex.RLock()
+	defer reader.clonedRepositoriesMutex.RUnlock()
+	return reader.clonedRepositories[getRepositoryKey(repository)]
+}
+
+func (reader *gitPackageSourceReader) getRepositoryMutex(repository *PackageRepository) *sync.Mutex {
+	reader.repositoryMutexesMutex.Lock()
+	defer reader.repositoryMutexesMutex.Unlock()
+
+	repositoryKey := getRepositoryKey(repository)
+	repositoryMutex, found := reader.repositoryMutexes[repositoryKey]
+	if !found {
+		repositoryMutex = &sync.Mutex{}
+		reader.repositoryMutexes[repositoryKey] = repositoryMutex
+	}
+	return repositoryMutex
+}
+
+func (reader *gitPackageSourceReader) getLocalRepositoryDirpath(repository *PackageRepository) string {
+	return filepath.Join(reader.cacheDirpath, repository.GetOwner(), repository.GetName()+bareRepositoryDirnameSuffix)
+}
+
+func (reader *gitPackageSourceReader) getRemoteURL(repository *PackageRepository) string {
+	return fmt.Sprintf("%s/%s/%s", reader.remoteBaseURL, repository.GetOwner(), repository.GetName())
+}
+
+// getRepositoryKey returns the repository without its ref, so all the refs of a repository share the local clone
+func getRepositoryKey(repository *PackageRepository) string {
+	return path.Join(repository.GetOwner(), repository.GetName())
+}
+
+// runGitCommand runs git with the args, its error has the file not found code when git fails because the remote
+// repository or ref doesn't exist
+func runGitCommand(ctx context.Context, args ...string) ([]byte, error) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	gitCmd := exec.CommandContext(ctx, gitBinaryName, args...)
+	gitCmd.Env = append(os.Environ(), disableGitTerminalPromptEnvVar)
+	gitCmd.Stdout = stdout
+	gitCmd.Stderr = stderr
+	if err := gitCmd.Run(); err != nil {
+		gitOutput := strings.TrimSpace(stderr.String())
+		if gitNotFoundOutputRegex.MatchString(gitOutput) {
+			return nil, stacktrace.PropagateWithCode(err, fileNotFoundErrorCode, "an error occurred running 'git %s' because the repository or ref doesn't exist, the command output was:\n%s", strings.Join(args, " "), gitOutput)
+		}
+		return nil, stacktrace.Propagate(err, "an error occurred running 'git %s', the command output was:\n%s", strings.Join(args, " "), gitOutput)
+	}
+	return stdout.Bytes(), nil
+}
+
+func parseLsTreeOutput(lsTreeOutput []byte) ([]*gitTreeEntry, error) {
+	treeEntries := []*gitTreeEntry{}
+	for _, lsTreeEntry := range strings.Split(string(lsTreeOutput), lsTreeEntriesSeparator) {
+		if lsTreeEntry == "" {
+			continue
+		}
+		metadata, entryPath, found := strings.Cut(lsTreeEntry, lsTreeMetadataAndPathSeparator)
+		if !found {
+			return nil, stacktrace.NewError("unexpected git ls-tree entry '%s'", lsTreeEntry)
+		}
+		metadataFields := strings.Fields(metadata)
+		if len(metadataFields) != lsTreeNumberOfMetadataFields {
+			return nil, stacktrace.NewError("expected git ls-tree entry '%s' to have %d metadata fields, but it has %d", lsTreeEntry, lsTreeNumberOfMetadataFields, len(metadataFields))
+		}
+
+		objectId := metadataFields[lsTreeObjectFieldIndex]
+		if metadataFields[lsTreeTypeFieldIndex] == gitTreeObjectType {
+			treeEntries = append(treeEntries, &gitTreeEntry{fileInfo: newFileInfo(entryPath, FileTypeDirectory, 0), objectId: objectId})
+			continue
+		}
+
+		var size int64
+		if sizeStr := metadataFields[lsTreeSizeFieldIndex]; sizeStr != gitObjectSizeNone {
+			parsedSize, err := strconv.ParseInt(sizeStr, 10, 64)
+			if err != nil {
+				return nil, stacktrace.Propagate(err, "an error occurred parsing the size of git ls-tree entry '%s'", lsTreeEntry)
+			}
+			size = parsedSize
+		}
+		treeEntries = append(treeEntries, &gitTreeEntry{fileInfo: newFileInfo(entryPath, FileTypeFile, size), objectId: objectId})
+	}
+	return treeEntries, nil
+}
@@ -0,0 +1,150 @@
+package source
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	testRepositoryOwner = "foo"
+	testRepositoryName  = "bar"
+
+	testTag = "v1"
+)
+
+func TestGitPackageSourceReader_ReadFile(t *testing.T) {
+	remoteBaseDirpath := t.TempDir()
+	remoteDirpath := createTestRemoteRepository(t, remoteBaseDirpath)
+	commitTestFile(t, remoteDirpath, "kurtosis.yml", "name: github.com/foo/bar\n")
+	commitTestFile(t, remoteDirpath, "packages/postgres/main.star", "def run(plan):\n    pass\n")
+
+	reader := NewGitPackageSourceReader("file://"+remoteBaseDirpath, t.TempDir())
+	repository := NewPackageRepository(testRepositoryOwner, testRepositoryName, "")
+
+	fileContent, err := reader.ReadFile(context.Background(), repository, "kurtosis.yml")
+	require.NoError(t, err)
+	require.Equal(t, "name: github.com/foo/bar\n", string(fileContent))
+
+	fileInfos, err := reader.ListDirectory(context.Background(), repository, "/packages")
+	require.NoError(t, err)
+	require.Len(t, fileInfos, 1)
+	require.Equal(t, "packages/postgres", fileInfos[0].GetPath())
+	require.True(t, fileInfos[0].IsDirectory())
+
+	fileInfo, err := reader.Stat(context.Background(), repository, "packages/postgres/main.star")
+	require.NoError(t, err)
+	require.False(t, fileInfo.IsDirectory())
+
+	_, err = reader.ReadFile(context.Background(), repository, "kurtosis_package_icon.png")
+	require.Error(t, err)
+	require.True(t, IsFileNotFoundErr(err))
+}
+
+func TestGitPackageSourceReader_ResolveRef(t *testing.T) {
+	remoteBaseDirpath := t.TempDir()
+	remoteDirpath := createTestRemoteRepository(t, remoteBaseDirpath)
+	commitTestFile(t, remoteDirpath, "kurtosis.yml", "name: github.com/foo/bar\n")
+	tagCommitSHA := runTestGitCommand(t, remoteDirpath, "rev-parse", "HEAD")
+	runTestGitCommand(t, remoteDirpath, "tag", testTag)
+	commitTestFile(t, remoteDirpath, "kurtosis.yml", "name: github.com/foo/bar\ndescription: Runs Postgres\n")
+	headCommitSHA := runTestGitCommand(t, remoteDirpath, "rev-parse", "HEAD")
+
+	reader := NewGitPackageSourceReader("file://"+remoteBaseDirpath, t.TempDir())
+
+	commitSHA, err := reader.ResolveRef(context.Background(), NewPackageRepository(testRepositoryOwner, testRepositoryName, ""))
+	require.NoError(t, err)
+	require.Equal(t, headCommitSHA, commitSHA)
+
+	taggedRepository := NewPackageRepository(testRepositoryOwner, testRepositoryName, testTag)
+	commitSHA, err = reader.ResolveRef(context.Background(), taggedRepository)
+	require.NoError(t, err)
+	require.Equal(t, tagCommitSHA, commitSHA)
+
+	fileContent, err := reader.ReadFile(context.Background(), taggedRepository, "kurtosis.yml")
+	require.NoError(t, err)
+	require.Equal(t, "name: github.com/foo/bar\n", string(fileContent))
+}
+
+func TestGitPackageSourceReader_ResolveRef_NotFound(t *testing.T) {
+	remoteBaseDirpath := t.TempDir()
+	remoteDirpath := createTestRemoteRepository(t, remoteBaseDirpath)
+	commitTestFile(t, remoteDirpath, "kurtosis.yml", "name: github.com/foo/bar\n")
+
+	reader := NewGitPackageSourceReader("file://"+remoteBaseDirpath, t.TempDir())
+
+	_, err := reader.ResolveRef(context.Background(), NewPackageRepository(testRepositoryOwner, testRepositoryName, "missing-tag"))
+	require.Error(t, err)
+	require.True(t, IsFileNotFoundErr(err))
+
+	_, err = reader.ResolveRef(context.Background(), NewPackageRepository(testRepositoryOwner, "missing-repository", ""))
+	require.Error(t, err)
+	require.True(t, IsFileNotFoundErr(err))
+}
+
+func TestGitPackageSourceReader_ResolveRef_CloneError(t *testing.T) {
+	requireGit(t)
+	reader := NewGitPackageSourceReader("unknown-protocol://"+t.TempDir(), t.TempDir())
+
+	_, err := reader.ResolveRef(context.Background(), NewPackageRepository(testRepositoryOwner, testRepositoryName, ""))
+	require.Error(t, err)
+	require.False(t, IsFileNotFoundErr(err))
+}
+
+func TestGitPackageSourceReader_ResolveRef_FetchesCachedRepository(t *testing.T) {
+	remoteBaseDirpath := t.TempDir()
+	remoteDirpath := createTestRemoteRepository(t, remoteBaseDirpath)
+	commitTestFile(t, remoteDirpath, "kurtosis.yml", "name: github.com/foo/bar\n")
+	runTestGitCommand(t, remoteDirpath, "tag", testTag)
+	cacheDirpath := t.TempDir()
+
+	_, err := NewGitPackageSourceReader("file://"+remoteBaseDirpath, cacheDirpath).ResolveRef(context.Background(), NewPackageRepository(testRepositoryOwner, testRepositoryName, testTag))
+	require.NoError(t, err)
+
+	// the tag is moved after the repository was cloned in the cache
+	commitTestFile(t, remoteDirpath, "kurtosis.yml", "name: github.com/foo/bar\ndescription: Runs Postgres\n")
+	runTestGitCommand(t, remoteDirpath, "tag", "--force", testTag)
+	movedTagCommitSHA := runTestGitCommand(t, remoteDirpath, "rev-parse", "HEAD")
+
+	reader := NewGitPackageSourceReader("file://"+remoteBaseDirpath, cacheDirpath)
+	for _, ref := range []string{"", testTag} {
+		commitSHA, err := reader.ResolveRef(context.Background(), NewPackageRepository(testRepositoryOwner, testRepositoryName, ref))
+		require.NoError(t, err)
+		require.Equal(t, movedTagCommitSHA, commitSHA)
+	}
+}
+
+// createTestRemoteRepository creates an empty repository to clone from '<remote base dirpath>/foo/bar'
+func createTestRemoteRepository(t *testing.T, remoteBaseDirpath string) string {
+	requireGit(t)
+	remoteDirpath := filepath.Join(remoteBaseDirpath, testRepositoryOwner, testRepositoryName)
+	require.NoError(t, os.MkdirAll(remoteDirpath, 0755))
+	runTestGitCommand(t, remoteDirpath, "init", "--quiet")
+	return remoteDirpath
+}
+
+// commitTestFile writes the file in the repository and commits it
+func commitTestFile(t *testing.T, repositoryDirpath string, relativeFilepath string, content string) {
+	absoluteFilepath := filepath.Join(repositoryDirpath, relativeFilepath)
+	require.NoError(t, os.MkdirAll(filepath.Dir(absoluteFilepath), 0755))
+	require.NoError(t, os.WriteFile(absoluteFilepath, []byte(content), 0644))
+	runTestGitCommand(t, repositoryDirpath, "add", "--all")
+	runTestGitCommand(t, repositoryDirpath, "-c", "user.name=test", "-c", "user.email=test@kurtosis.com", "commit", "--quiet", "--message", "update "+relativeFilepath)
+}
+
+// runTestGitCommand runs git in the repository and returns its output without the trailing newline
+func runTestGitCommand(t *testing.T, repositoryDirpath string, args ...string) string {
+	output, err := runGitCommand(context.Background(), append([]string{"-C", repositoryDirpath}, args...)...)
+	require.NoError(t, err)
+	return strings.TrimSpace(string(output))
+}
+
+func requireGit(t *testing.T) {
+	if _, err := exec.LookPath(gitBinaryName); err != nil {
+		t.Skipf("the '%s' binary is required to test the git package source reader", gitBinaryName)
+	}
+}
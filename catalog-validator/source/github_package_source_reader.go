@@ -0,0 +1,114 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/go-github/v54/github"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+const (
+	gitHubDirContentType = "dir"
+
+	rateLimitExceededErrMsg = "GitHub API rate limit exceeded."
+	// the commit SHA can't be compared with anything because this reader doesn't cache the resolved SHAs
+	noLastCommitSHA = ""
+)
+
+// gitHubPackageSourceReader reads the package repositories content using the GitHub contents API
+type gitHubPackageSourceReader struct {
+	gitHubClient *github.Client
+}
+
+func NewGitHubPackageSourceReader(gitHubClient *github.Client) *gitHubPackageSourceReader {
+	return &gitHubPackageSourceReader{gitHubClient: gitHubClient}
+}
+
+func (reader *gitHubPackageSourceReader) ReadFile(ctx context.Context, repository *PackageRepository, filepath string) ([]byte, error) {
+	fileContentResult, _, err := reader.getContents(ctx, repository, filepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred getting the content of file '%s' from repository '%s'", filepath, repository)
+	}
+	if fileContentResult == nil {
+		return nil, stacktrace.NewError("expected '%s' in repository '%s' to be a file, but it's a directory", filepath, repository)
+	}
+
+	rawFileContentStr, err := fileContentResult.GetContent()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred getting the '%s' base 64 file content from repository '%s'", filepath, repository)
+	}
+
+	return []byte(rawFileContentStr), nil
+}
+
+func (reader *gitHubPackageSourceReader) ListDirectory(ctx context.Context, repository *PackageRepository, dirpath string) ([]*FileInfo, error) {
+	_, directoryContentResult, err := reader.getContents(ctx, repository, dirpath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred getting the content of directory '%s' from repository '%s'", dirpath, repository)
+	}
+	if directoryContentResult == nil {
+		return nil, stacktrace.NewError("expected '%s' in repository '%s' to be a directory, but it's a file", dirpath, repository)
+	}
+
+	fileInfos := make([]*FileInfo, len(directoryContentResult))
+	for index, content := range directoryContentResult {
+		fileInfos[index] = newFileInfoFromRepositoryContent(content)
+	}
+	return fileInfos, nil
+}
+
+func (reader *gitHubPackageSourceReader) Stat(ctx context.Context, repository *PackageRepository, filepath string) (*FileInfo, error) {
+	fileContentResult, _, err := reader.getContents(ctx, repository, filepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred getting the information of '%s' from repository '%s'", filepath, repository)
+	}
+	if fileContentResult == nil {
+		return newFileInfo(cleanRepositoryPath(filepath), FileTypeDirectory, 0), nil
+	}
+	return newFileInfoFromRepositoryContent(fileContentResult), nil
+}
+
+func (reader *gitHubPackageSourceReader) ResolveRef(ctx context.Context, repository *PackageRepository) (string, error) {
+	commitSHA, resp, err := reader.gitHubClient.Repositories.GetCommitSHA1(ctx, repository.GetOwner(), repository.GetName(), repository.GetRefOrDefaultBranch(), noLastCommitSHA)
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnprocessableEntity) {
+			return "", stacktrace.NewErrorWithCode(fileNotFoundErrorCode, "ref '%s' does not exist in repository '%s'", repository.GetRefOrDefaultBranch(), repository)
+		}
+		return "", stacktrace.Propagate(err, "%s", getGitHubErrMsg(err, fmt.Sprintf("an error occurred resolving ref '%s' in repository '%s'", repository.GetRefOrDefaultBranch(), repository)))
+	}
+	return commitSHA, nil
+}
+
+func (reader *gitHubPackageSourceReader) getContents(ctx context.Context, repository *PackageRepository, filepath string) (*github.RepositoryContent, []*github.RepositoryContent, error) {
+	repoGetContentOpts := &github.RepositoryContentGetOptions{
+		Ref: repository.GetRef(),
+	}
+
+	fileContentResult, directoryContentResult, resp, err := reader.gitHubClient.Repositories.GetContents(ctx, repository.GetOwner(), repository.GetName(), filepath, repoGetContentOpts)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, nil, newFileNotFoundError(repository, filepath)
+		}
+		return nil, nil, stacktrace.Propagate(err, "%s", getGitHubErrMsg(err, fmt.Sprintf("an error occurred reading content of '%s' in repository '%s'", filepath, repository)))
+	}
+	return fileContentResult, directoryContentResult, nil
+}
+
+func getGitHubErrMsg(err error, defaultErrMsg string) string {
+	if errors.Is(err, &github.RateLimitError{}) {
+		logrus.Errorf("%s Error is:\n%v", rateLimitExceededErrMsg, err.Error())
+		return rateLimitExceededErrMsg
+	}
+	return defaultErrMsg
+}
+
+func newFileInfoFromRepositoryContent(content *github.RepositoryContent) *FileInfo {
+	fileType := FileTypeFile
+	if content.GetType() == gitHubDirContentType {
+		fileType = FileTypeDirectory
+	}
+	return newFileInfo(content.GetPath(), fileType, int64(content.GetSize()))
+}
@@ -0,0 +1,146 @@
+package source
+
+import (
+	"context"
+	"github.com/kurtosis-tech/stacktrace"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// InMemoryPackageSourceReader is a PackageSourceReader that serves files previously added to it, it allows checking
+// the rules without any network access. Directories don't need to be added, they exist as long as they contain a file
+type InMemoryPackageSourceReader struct {
+	mutex *sync.RWMutex
+
+	// files is indexed by repository (without ref) and then by the file path relative to the repository root
+	files map[string]map[string][]byte
+
+	// refs is indexed by repository (without ref) and then by ref, the values are the commit SHAs
+	refs map[string]map[string]string
+}
+
+func NewInMemoryPackageSourceReader() *InMemoryPackageSourceReader {
+	return &InMemoryPackageSourceReader{
+		mutex: &sync.RWMutex{},
+		files: map[string]map[string][]byte{},
+		refs:  map[string]map[string]string{},
+	}
+}
+
+// AddFile stores the file content, overriding the previous one if the file was already added
+func (reader *InMemoryPackageSourceReader) AddFile(repositoryOwner string, repositoryName string, filepath string, content []byte) {
+	reader.mutex.Lock()
+	defer reader.mutex.Unlock()
+
+	repositoryKey := getInMemoryRepositoryKey(repositoryOwner, repositoryName)
+	if _, found := reader.files[repositoryKey]; !found {
+		reader.files[repositoryKey] = map[string][]byte{}
+	}
+	reader.files[repositoryKey][cleanRepositoryPath(filepath)] = content
+}
+
+// AddRef sets the commit SHA returned when the ref is resolved
+func (reader *InMemoryPackageSourceReader) AddRef(repositoryOwner string, repositoryName string, ref string, commitSHA string) {
+	reader.mutex.Lock()
+	defer reader.mutex.Unlock()
+
+	repositoryKey := getInMemoryRepositoryKey(repositoryOwner, repositoryName)
+	if _, found := reader.refs[repositoryKey]; !found {
+		reader.refs[repositoryKey] = map[string]string{}
+	}
+	reader.refs[repositoryKey][ref] = commitSHA
+}
+
+func (reader *InMemoryPackageSourceReader) ReadFile(_ context.Context, repository *PackageRepository, filepath string) ([]byte, error) {
+	reader.mutex.RLock()
+	defer reader.mutex.RUnlock()
+
+	content, found := reader.getRepositoryFiles(repository)[cleanRepositoryPath(filepath)]
+	if !found {
+		return nil, newFileNotFoundError(repository, filepath)
+	}
+	return content, nil
+}
+
+func (reader *InMemoryPackageSourceReader) ListDirectory(_ context.Context, repository *PackageRepository, dirpath string) ([]*FileInfo, error) {
+	reader.mutex.RLock()
+	defer reader.mutex.RUnlock()
+
+	cleanDirpath := cleanRepositoryPath(dirpath)
+	if _, found := reader.getRepositoryFiles(repository)[cleanDirpath]; found {
+		return nil, stacktrace.NewError("expected '%s' in repository '%s' to be a directory, but it's a file", dirpath, repository)
+	}
+
+	fileInfosByName := map[string]*FileInfo{}
+	for filepath, content := range reader.getRepositoryFiles(repository) {
+		relativeFilepath, isInDir := getPathRelativeToDir(filepath, cleanDirpath)
+		if !isInDir {
+			continue
+		}
+		childName, _, isNested := strings.Cut(relativeFilepath, rootPath)
+		if isNested {
+			fileInfosByName[childName] = newFileInfo(path.Join(cleanDirpath, childName), FileTypeDirectory, 0)
+			continue
+		}
+		fileInfosByName[childName] = newFileInfo(filepath, FileTypeFile, int64(len(content)))
+	}
+	if len(fileInfosByName) == 0 {
+		return nil, newFileNotFoundError(repository, dirpath)
+	}
+
+	childNames := make([]string, 0, len(fileInfosByName))
+	for childName := range fileInfosByName {
+		childNames = append(childNames, childName)
+	}
+	sort.Strings(childNames)
+
+	fileInfos := make([]*FileInfo, len(childNames))
+	for index, childName := range childNames {
+		fileInfos[index] = fileInfosByName[childName]
+	}
+	return fileInfos, nil
+}
+
+func (reader *InMemoryPackageSourceReader) Stat(ctx context.Context, repository *PackageRepository, filepath string) (*FileInfo, error) {
+	cleanFilepath := cleanRepositoryPath(filepath)
+
+	reader.mutex.RLock()
+	content, found := reader.getRepositoryFiles(repository)[cleanFilepath]
+	reader.mutex.RUnlock()
+	if found {
+		return newFileInfo(cleanFilepath, FileTypeFile, int64(len(content))), nil
+	}
+
+	if _, err := reader.ListDirectory(ctx, repository, filepath); err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred getting the information of '%s' in repository '%s'", filepath, repository)
+	}
+	return newFileInfo(cleanFilepath, FileTypeDirectory, 0), nil
+}
+
+func (reader *InMemoryPackageSourceReader) ResolveRef(_ context.Context, repository *PackageRepository) (string, error) {
+	reader.mutex.RLock()
+	defer reader.mutex.RUnlock()
+
+	commitSHA, found := reader.refs[getInMemoryRepositoryKey(repository.GetOwner(), repository.GetName())][repository.GetRefOrDefaultBranch()]
+	if !found {
+		return "", stacktrace.NewErrorWithCode(fileNotFoundErrorCode, "ref '%s' does not exist in repository '%s'", repository.GetRefOrDefaultBranch(), repository)
+	}
+	return commitSHA, nil
+}
+
+func (reader *InMemoryPackageSourceReader) getRepositoryFiles(repository *PackageRepository) map[string][]byte {
+	return reader.files[getInMemoryRepositoryKey(repository.GetOwner(), repository.GetName())]
+}
+
+func getInMemoryRepositoryKey(repositoryOwner string, repositoryName string) string {
+	return path.Join(repositoryOwner, repositoryName)
+}
+
+func getPathRelativeToDir(filepath string, dirpath string) (string, bool) {
+	if dirpath == "" {
+		return filepath, true
+	}
+	return strings.CutPrefix(filepath, dirpath+rootPath)
+}
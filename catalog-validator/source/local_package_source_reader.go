@@ -0,0 +1,125 @@
+package source
+
+import (
+	"context"
+	"github.com/kurtosis-tech/stacktrace"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// localGitDirname is the entry of a git working tree root, a directory in a clone or a file in a linked worktree
+	localGitDirname = ".git"
+
+	gitRevParseRefNotFoundExitCode = 1
+)
+
+// localPackageSourceReader reads the package repositories from a local directory where each repository
+// is checked out on '<root dirpath>/<repository owner>/<repository name>', e.g. a working tree of the package
+// 'github.com/kurtosis-tech/postgres-package' is expected on '<root dirpath>/kurtosis-tech/postgres-package'.
+// The directories are a snapshot of a single version of the repository so the repository ref is ignored to read the
+// files, but it's resolved to a commit if the directory is a git working tree, so the recorded ref-sha can be checked
+type localPackageSourceReader struct {
+	rootDirpath string
+}
+
+func NewLocalPackageSourceReader(rootDirpath string) *localPackageSourceReader {
+	return &localPackageSourceReader{rootDirpath: rootDirpath}
+}
+
+func (reader *localPackageSourceReader) ReadFile(_ context.Context, repository *PackageRepository, filepath string) ([]byte, error) {
+	localFilepath := reader.getLocalPath(repository, filepath)
+
+	fileContent, err := os.ReadFile(localFilepath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, newFileNotFoundError(repository, filepath)
+		}
+		return nil, stacktrace.Propagate(err, "an error occurred reading file '%s'", localFilepath)
+	}
+	return fileContent, nil
+}
+
+func (reader *localPackageSourceReader) ListDirectory(_ context.Context, repository *PackageRepository, dirpath string) ([]*FileInfo, error) {
+	localDirpath := reader.getLocalPath(repository, dirpath)
+
+	dirEntries, err := os.ReadDir(localDirpath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, newFileNotFoundError(repository, dirpath)
+		}
+		return nil, stacktrace.Propagate(err, "an error occurred listing directory '%s'", localDirpath)
+	}
+
+	fileInfos := make([]*FileInfo, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		dirEntryInfo, err := dirEntry.Info()
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "an error occurred getting the information of '%s' in directory '%s'", dirEntry.Name(), localDirpath)
+		}
+		fileInfos = append(fileInfos, newFileInfoFromOsFileInfo(path.Join(cleanRepositoryPath(dirpath), dirEntry.Name()), dirEntryInfo))
+	}
+	return fileInfos, nil
+}
+
+func (reader *localPackageSourceReader) Stat(_ context.Context, repository *PackageRepository, filepath string) (*FileInfo, error) {
+	localFilepath := reader.getLocalPath(repository, filepath)
+
+	osFileInfo, err := os.Stat(localFilepath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, newFileNotFoundError(repository, filepath)
+		}
+		return nil, stacktrace.Propagate(err, "an error occurred getting the information of '%s'", localFilepath)
+	}
+	return newFileInfoFromOsFileInfo(cleanRepositoryPath(filepath), osFileInfo), nil
+}
+
+// ResolveRef returns the commit SHA of the ref in the local clone if the repository directory is a git working tree,
+// the ref is returned unchanged otherwise because the directory is not versioned
+func (reader *localPackageSourceReader) ResolveRef(ctx context.Context, repository *PackageRepository) (string, error) {
+	repositoryDirpath := filepath.Join(reader.rootDirpath, repository.GetOwner(), repository.GetName())
+	if _, err := os.Stat(repositoryDirpath); err != nil {
+		if os.IsNotExist(err) {
+			return "", stacktrace.NewErrorWithCode(fileNotFoundErrorCode, "repository '%s' was not found in '%s'", repository, reader.rootDirpath)
+		}
+		return "", stacktrace.Propagate(err, "an error occurred checking for repository '%s' existence on '%s'", repository, repositoryDirpath)
+	}
+
+	// the '.git' entry is checked instead of asking git, so a directory that isn't a clone but is inside another
+	// working tree (e.g. the catalog repository) isn't resolved with the refs of that other repository
+	gitDirpath := filepath.Join(repositoryDirpath, localGitDirname)
+	if _, err := os.Stat(gitDirpath); err != nil {
+		if os.IsNotExist(err) {
+			return repository.GetRefOrDefaultBranch(), nil
+		}
+		return "", stacktrace.Propagate(err, "an error occurred checking if repository '%s' is a git working tree on '%s'", repository, gitDirpath)
+	}
+
+	ref := repository.GetRefOrDefaultBranch()
+	revParseOutput, err := runGitCommand(ctx, "-C", repositoryDirpath, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		// 'git rev-parse --verify --quiet' exits with code 1 and no output when the ref doesn't exist, the other
+		// failures (e.g. a broken clone) exit with another code
+		if exitErr, isExitErr := stacktrace.RootCause(err).(*exec.ExitError); isExitErr && exitErr.ExitCode() == gitRevParseRefNotFoundExitCode {
+			return "", stacktrace.NewErrorWithCode(fileNotFoundErrorCode, "ref '%s' does not exist in the local clone of repository '%s' on '%s'", ref, repository, repositoryDirpath)
+		}
+		return "", stacktrace.Propagate(err, "an error occurred resolving ref '%s' in the local clone of repository '%s' on '%s'", ref, repository, repositoryDirpath)
+	}
+	return strings.TrimSpace(string(revParseOutput)), nil
+}
+
+// getLocalPath translates a path relative to the repository root to a local path
+func (reader *localPackageSourceReader) getLocalPath(repository *PackageRepository, repositoryPath string) string {
+	return filepath.Join(reader.rootDirpath, repository.GetOwner(), repository.GetName(), filepath.FromSlash(cleanRepositoryPath(repositoryPath)))
+}
+
+func newFileInfoFromOsFileInfo(repositoryPath string, osFileInfo os.FileInfo) *FileInfo {
+	if osFileInfo.IsDir() {
+		return newFileInfo(repositoryPath, FileTypeDirectory, 0)
+	}
+	return newFileInfo(repositoryPath, FileTypeFile, osFileInfo.Size())
+}
@@ -0,0 +1,48 @@
+package source
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalPackageSourceReader_ResolveRef(t *testing.T) {
+	rootDirpath := t.TempDir()
+	repositoryDirpath := createTestRemoteRepository(t, rootDirpath)
+	commitTestFile(t, repositoryDirpath, "kurtosis.yml", "name: github.com/foo/bar\n")
+	tagCommitSHA := runTestGitCommand(t, repositoryDirpath, "rev-parse", "HEAD")
+	runTestGitCommand(t, repositoryDirpath, "tag", testTag)
+	commitTestFile(t, repositoryDirpath, "kurtosis.yml", "name: github.com/foo/bar\ndescription: Runs Postgres\n")
+	headCommitSHA := runTestGitCommand(t, repositoryDirpath, "rev-parse", "HEAD")
+
+	reader := NewLocalPackageSourceReader(rootDirpath)
+
+	commitSHA, err := reader.ResolveRef(context.Background(), NewPackageRepository(testRepositoryOwner, testRepositoryName, ""))
+	require.NoError(t, err)
+	require.Equal(t, headCommitSHA, commitSHA)
+
+	commitSHA, err = reader.ResolveRef(context.Background(), NewPackageRepository(testRepositoryOwner, testRepositoryName, testTag))
+	require.NoError(t, err)
+	require.Equal(t, tagCommitSHA, commitSHA)
+
+	_, err = reader.ResolveRef(context.Background(), NewPackageRepository(testRepositoryOwner, testRepositoryName, "missing-tag"))
+	require.Error(t, err)
+	require.True(t, IsFileNotFoundErr(err))
+
+	_, err = reader.ResolveRef(context.Background(), NewPackageRepository(testRepositoryOwner, "missing-repository", ""))
+	require.Error(t, err)
+	require.True(t, IsFileNotFoundErr(err))
+}
+
+func TestLocalPackageSourceReader_ResolveRef_NotGitWorkingTree(t *testing.T) {
+	rootDirpath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(rootDirpath, testRepositoryOwner, testRepositoryName), 0755))
+
+	reader := NewLocalPackageSourceReader(rootDirpath)
+
+	ref, err := reader.ResolveRef(context.Background(), NewPackageRepository(testRepositoryOwner, testRepositoryName, testTag))
+	require.NoError(t, err)
+	require.Equal(t, testTag, ref)
+}
@@ -0,0 +1,74 @@
+package source
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+	"path"
+	"strings"
+)
+
+const (
+	defaultBranchRef = "HEAD"
+
+	rootPath = "/"
+)
+
+// PackageRepository identifies the GitHub repository where a package lives and the ref (branch, tag or commit SHA)
+// that has to be read, an empty ref means the repository default branch
+type PackageRepository struct {
+	owner string
+	name  string
+	ref   string
+}
+
+func NewPackageRepository(owner string, name string, ref string) *PackageRepository {
+	return &PackageRepository{owner: owner, name: name, ref: ref}
+}
+
+// NewPackageRepositoryFromPackageData returns the repository of a package in the catalog, at the ref set in its
+// catalog entry
+func NewPackageRepositoryFromPackageData(packageData packageData) *PackageRepository {
+	return NewPackageRepository(packageData.GetRepositoryOwner(), packageData.GetRepositoryName(), packageData.GetRef())
+}
+
+func (repository *PackageRepository) GetOwner() string {
+	return repository.owner
+}
+
+func (repository *PackageRepository) GetName() string {
+	return repository.name
+}
+
+func (repository *PackageRepository) GetRef() string {
+	return repository.ref
+}
+
+// GetRefOrDefaultBranch returns the ref to read, falling back to HEAD when there is no ref to read the default branch
+func (repository *PackageRepository) GetRefOrDefaultBranch() string {
+	if repository.ref == "" {
+		return defaultBranchRef
+	}
+	return repository.ref
+}
+
+func (repository *PackageRepository) String() string {
+	repositoryStr := fmt.Sprintf("%s/%s", repository.owner, repository.name)
+	if repository.ref != "" {
+		repositoryStr = fmt.Sprintf("%s@%s", repositoryStr, repository.ref)
+	}
+	return repositoryStr
+}
+
+// packageData is the subset of the catalog package data needed to find its repository
+type packageData interface {
+	GetPackageName() types.PackageName
+	GetRepositoryOwner() string
+	GetRepositoryName() string
+	GetRef() string
+}
+
+// cleanRepositoryPath returns the path relative to the repository root without any leading slash, the path is
+// cleaned as an absolute one so it can't go outside the repository
+func cleanRepositoryPath(filepath string) string {
+	return strings.TrimPrefix(path.Clean(rootPath+filepath), rootPath)
+}
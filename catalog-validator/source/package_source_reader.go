@@ -0,0 +1,38 @@
+package source
+
+import (
+	"context"
+	"github.com/kurtosis-tech/stacktrace"
+)
+
+const (
+	// fileNotFoundErrorCode is attached to the errors returned when the requested file or directory does not exist,
+	// it's preserved by stacktrace.Propagate so callers can still detect it after wrapping the error
+	fileNotFoundErrorCode stacktrace.ErrorCode = iota + 1
+)
+
+// PackageSourceReader gives access to the content of the repositories where the catalog packages live,
+// all the paths received are relative to the repository root.
+// Implementations have to be safe to use from several goroutines at the same time
+type PackageSourceReader interface {
+	// ReadFile returns the content of the file, an error checkable with IsFileNotFoundErr is returned if it does not exist
+	ReadFile(ctx context.Context, repository *PackageRepository, filepath string) ([]byte, error)
+
+	// ListDirectory returns the files and directories that are direct children of the directory
+	ListDirectory(ctx context.Context, repository *PackageRepository, dirpath string) ([]*FileInfo, error)
+
+	// Stat returns the information of a file or a directory
+	Stat(ctx context.Context, repository *PackageRepository, filepath string) (*FileInfo, error)
+
+	// ResolveRef returns the commit SHA that the repository ref points to
+	ResolveRef(ctx context.Context, repository *PackageRepository) (string, error)
+}
+
+// IsFileNotFoundErr returns true if the error was returned by a PackageSourceReader because the file does not exist
+func IsFileNotFoundErr(err error) bool {
+	return err != nil && stacktrace.GetCode(err) == fileNotFoundErrorCode
+}
+
+func newFileNotFoundError(repository *PackageRepository, filepath string) error {
+	return stacktrace.NewErrorWithCode(fileNotFoundErrorCode, "file '%s' does not exist in repository '%s'", filepath, repository)
+}
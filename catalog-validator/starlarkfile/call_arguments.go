@@ -0,0 +1,91 @@
+package starlarkfile
+
+import (
+	"go.starlark.net/syntax"
+)
+
+// StringArgument is a string literal passed as an argument to a function call
+type StringArgument struct {
+	functionName string
+	value        string
+
+	line   int
+	column int
+}
+
+// FindStringArguments returns the string literals passed as the argument to every call of the function in the file,
+// both as a builtin like 'import_module(...)' and as a method like 'plan.upload_files(...)'. The argument is looked up
+// by its position and by its keyword, the values that aren't string literals are skipped because they can't be known
+// without running the file
+func (file *File) FindStringArguments(functionName string, argumentIdx int, argumentName string) []*StringArgument {
+	stringArguments := []*StringArgument{}
+	syntax.Walk(file.syntaxFile, func(node syntax.Node) bool {
+		callExpr, isCallExpr := node.(*syntax.CallExpr)
+		if !isCallExpr || getCalledFunctionName(callExpr) != functionName {
+			return true
+		}
+		argumentValue := findCallArgument(callExpr, argumentIdx, argumentName)
+		stringLiteral, isLiteral := argumentValue.(*syntax.Literal)
+		if !isLiteral || stringLiteral.Token != syntax.STRING {
+			return true
+		}
+		stringArguments = append(stringArguments, &StringArgument{
+			functionName: functionName,
+			value:        stringLiteral.Value.(string),
+			line:         int(stringLiteral.TokenPos.Line),
+			column:       int(stringLiteral.TokenPos.Col),
+		})
+		return true
+	})
+	return stringArguments
+}
+
+func (stringArgument *StringArgument) GetFunctionName() string {
+	return stringArgument.functionName
+}
+
+func (stringArgument *StringArgument) GetValue() string {
+	return stringArgument.value
+}
+
+func (stringArgument *StringArgument) GetLine() int {
+	return stringArgument.line
+}
+
+func (stringArgument *StringArgument) GetColumn() int {
+	return stringArgument.column
+}
+
+// getCalledFunctionName returns the name of the function or method called, or an empty string if it isn't called by name
+func getCalledFunctionName(callExpr *syntax.CallExpr) string {
+	switch calledFunction := callExpr.Fn.(type) {
+	case *syntax.Ident:
+		return calledFunction.Name
+	case *syntax.DotExpr:
+		return calledFunction.Name.Name
+	}
+	return ""
+}
+
+// findCallArgument returns the value of the argument passed by position or by keyword, or nil if it isn't passed
+func findCallArgument(callExpr *syntax.CallExpr, argumentIdx int, argumentName string) syntax.Expr {
+	positionalArgumentIdx := 0
+	for _, argument := range callExpr.Args {
+		keywordArgument, isBinaryExpr := argument.(*syntax.BinaryExpr)
+		if isBinaryExpr && keywordArgument.Op == syntax.EQ {
+			if keyword, isIdent := keywordArgument.X.(*syntax.Ident); isIdent && keyword.Name == argumentName {
+				return keywordArgument.Y
+			}
+			continue
+		}
+		if _, isUnaryExpr := argument.(*syntax.UnaryExpr); isUnaryExpr {
+			// the *args and **kwargs arguments can't be read without running the file
+			continue
+		}
+		if positionalArgumentIdx == argumentIdx {
+			return argument
+		}
+		positionalArgumentIdx++
+	}
+	return nil
+}
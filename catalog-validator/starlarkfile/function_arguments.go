@@ -0,0 +1,323 @@
+package starlarkfile
+
+import (
+	"go.starlark.net/syntax"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DocstringArgsSectionHeader starts the block documenting the function arguments in the docstring, e.g.:
+	// """Deploys the network
+	//
+	// Args:
+	//     participants (list[json]): the nodes of the network
+	//     network_id (string): the id of the network
+	// """
+	DocstringArgsSectionHeader = "Args:"
+
+	starlarkTrueValue  = "True"
+	starlarkFalseValue = "False"
+	starlarkNoneValue  = "None"
+)
+
+var (
+	// docstringArgumentRegexp matches the first line of a documented argument, like 'name (type): description'
+	docstringArgumentRegexp = regexp.MustCompile(`^(?P<name>[a-zA-Z_][a-zA-Z0-9_]*)\s*(?:\((?P<type>[^)]*)\))?\s*:(?P<description>.*)$`)
+
+	// typeCommentRegexp matches the comment after an argument of the signature declaring its type, like
+	// 'name, # type: string'. Like in the package indexer, only the letters of the type are read
+	typeCommentRegexp = regexp.MustCompile(`#\s*type\s*:\s*(?P<type>[a-zA-Z]*)`)
+)
+
+// SignatureArgument is an argument declared in the signature of a function
+type SignatureArgument struct {
+	name string
+
+	// isVariadic is true for the *args and **kwargs arguments
+	isVariadic bool
+
+	hasDefaultValue bool
+
+	// defaultValue is the source of the default value if it's a string, int, float, bool or None literal, empty otherwise
+	defaultValue string
+
+	// defaultValueType is the type of the default value, empty if it has none or if it can't be told statically
+	defaultValueType string
+
+	// commentedType is the type declared in the comment after the argument, empty if there isn't a type comment
+	commentedType string
+
+	line   int
+	column int
+}
+
+// DocumentedArgument is an argument documented in the "Args:" block of the docstring of a function
+type DocumentedArgument struct {
+	name string
+
+	// documentedType is the type between parenthesis after the name, empty if it's not documented
+	documentedType string
+
+	description string
+
+	line   int
+	column int
+}
+
+// FunctionArguments are the arguments of a function, the ones in its signature and the ones in its docstring
+type FunctionArguments struct {
+	signatureArguments  []*SignatureArgument
+	documentedArguments []*DocumentedArgument
+}
+
+// ExtractFunctionArguments returns the arguments declared in the signature of the function, with their type comments,
+// and the ones documented in the "Args:" block of its docstring, following the Kurtosis convention the package indexer
+// uses. The function has to be parsed with the comments for the type comments to be read
+func ExtractFunctionArguments(function *syntax.DefStmt) *FunctionArguments {
+	signatureArguments := []*SignatureArgument{}
+	for _, param := range function.Params {
+		if signatureArgument := newSignatureArgument(param); signatureArgument != nil {
+			signatureArguments = append(signatureArguments, signatureArgument)
+		}
+	}
+	return &FunctionArguments{
+		signatureArguments:  signatureArguments,
+		documentedArguments: extractDocumentedArguments(function),
+	}
+}
+
+func (functionArguments *FunctionArguments) GetSignatureArguments() []*SignatureArgument {
+	return functionArguments.signatureArguments
+}
+
+func (functionArguments *FunctionArguments) GetDocumentedArguments() []*DocumentedArgument {
+	return functionArguments.documentedArguments
+}
+
+// FindSignatureArgument returns the argument with the name in the signature, or nil if there isn't any
+func (functionArguments *FunctionArguments) FindSignatureArgument(name string) *SignatureArgument {
+	for _, signatureArgument := range functionArguments.signatureArguments {
+		if signatureArgument.name == name {
+			return signatureArgument
+		}
+	}
+	return nil
+}
+
+// FindDocumentedArgument returns the argument with the name in the docstring, or nil if there isn't any
+func (functionArguments *FunctionArguments) FindDocumentedArgument(name string) *DocumentedArgument {
+	for _, documentedArgument := range functionArguments.documentedArguments {
+		if documentedArgument.name == name {
+			return documentedArgument
+		}
+	}
+	return nil
+}
+
+func (signatureArgument *SignatureArgument) GetName() string {
+	return signatureArgument.name
+}
+
+func (signatureArgument *SignatureArgument) IsVariadic() bool {
+	return signatureArgument.isVariadic
+}
+
+// IsRequired returns true if the argument has to be passed, that is if it has no default value and isn't variadic
+func (signatureArgument *SignatureArgument) IsRequired() bool {
+	return !signatureArgument.hasDefaultValue && !signatureArgument.isVariadic
+}
+
+func (signatureArgument *SignatureArgument) HasDefaultValue() bool {
+	return signatureArgument.hasDefaultValue
+}
+
+func (signatureArgument *SignatureArgument) GetDefaultValue() string {
+	return signatureArgument.defaultValue
+}
+
+func (signatureArgument *SignatureArgument) GetDefaultValueType() string {
+	return signatureArgument.defaultValueType
+}
+
+func (signatureArgument *SignatureArgument) GetCommentedType() string {
+	return signatureArgument.commentedType
+}
+
+func (signatureArgument *SignatureArgument) GetLine() int {
+	return signatureArgument.line
+}
+
+func (signatureArgument *SignatureArgument) GetColumn() int {
+	return signatureArgument.column
+}
+
+func (documentedArgument *DocumentedArgument) GetName() string {
+	return documentedArgument.name
+}
+
+func (documentedArgument *DocumentedArgument) GetDocumentedType() string {
+	return documentedArgument.documentedType
+}
+
+func (documentedArgument *DocumentedArgument) GetDescription() string {
+	return documentedArgument.description
+}
+
+func (documentedArgument *DocumentedArgument) GetLine() int {
+	return documentedArgument.line
+}
+
+func (documentedArgument *DocumentedArgument) GetColumn() int {
+	return documentedArgument.column
+}
+
+// newSignatureArgument returns the argument declared by the function parameter, which is either 'name',
+// 'name=default', '*name' or '**name'. It returns nil for the bare '*' separating the keyword-only arguments
+func newSignatureArgument(param syntax.Expr) *SignatureArgument {
+	paramPosition, _ := param.Span()
+	signatureArgument := &SignatureArgument{
+		name:             "",
+		isVariadic:       false,
+		hasDefaultValue:  false,
+		defaultValue:     "",
+		defaultValueType: "",
+		commentedType:    getCommentedType(param),
+		line:             int(paramPosition.Line),
+		column:           int(paramPosition.Col),
+	}
+	switch typedParam := param.(type) {
+	case *syntax.Ident:
+		signatureArgument.name = typedParam.Name
+	case *syntax.BinaryExpr:
+		paramName, isIdent := typedParam.X.(*syntax.Ident)
+		if !isIdent {
+			return nil
+		}
+		signatureArgument.name = paramName.Name
+		signatureArgument.hasDefaultValue = true
+		signatureArgument.defaultValue, signatureArgument.defaultValueType = getDefaultValueAndType(typedParam.Y)
+	case *syntax.UnaryExpr:
+		paramName, isIdent := typedParam.X.(*syntax.Ident)
+		if !isIdent {
+			return nil
+		}
+		signatureArgument.name = paramName.Name
+		signatureArgument.isVariadic = true
+	default:
+		return nil
+	}
+	return signatureArgument
+}
+
+// getCommentedType returns the type declared in the comment on the same line after the parameter, or an empty string
+// if there isn't a type comment
+func getCommentedType(param syntax.Expr) string {
+	paramComments := param.Comments()
+	if paramComments == nil {
+		return ""
+	}
+	for _, comment := range paramComments.Suffix {
+		if typeCommentMatch := typeCommentRegexp.FindStringSubmatch(comment.Text); typeCommentMatch != nil {
+			return typeCommentMatch[typeCommentRegexp.SubexpIndex("type")]
+		}
+	}
+	return ""
+}
+
+// getDefaultValueAndType returns the source and the type of the default value when they can be told without running
+// the function, the type is named like in the docstrings
+func getDefaultValueAndType(defaultValueExpr syntax.Expr) (string, string) {
+	switch typedDefaultValue := defaultValueExpr.(type) {
+	case *syntax.Literal:
+		switch typedDefaultValue.Token {
+		case syntax.STRING, syntax.BYTES:
+			return typedDefaultValue.Raw, "string"
+		case syntax.INT:
+			return typedDefaultValue.Raw, "int"
+		case syntax.FLOAT:
+			return typedDefaultValue.Raw, "float"
+		}
+	case *syntax.Ident:
+		switch typedDefaultValue.Name {
+		case starlarkTrueValue, starlarkFalseValue:
+			return typedDefaultValue.Name, "bool"
+		case starlarkNoneValue:
+			return typedDefaultValue.Name, ""
+		}
+	case *syntax.ListExpr:
+		return "", "list"
+	case *syntax.DictExpr:
+		return "", "dict"
+	}
+	return "", ""
+}
+
+// extractDocumentedArguments returns the arguments in the "Args:" block of the function docstring, which is the string
+// literal the function body starts with. Each argument starts at the indentation of the first one, the more indented
+// lines continue its description and the block ends at the first line indented like the header
+func extractDocumentedArguments(function *syntax.DefStmt) []*DocumentedArgument {
+	documentedArguments := []*DocumentedArgument{}
+	docstring := getDocstring(function)
+	if docstring == nil {
+		return documentedArguments
+	}
+
+	docstringLines := strings.Split(docstring.Value.(string), "\n")
+	argsSectionHeaderIndentation := -1
+	argumentIndentation := -1
+	var currentArgument *DocumentedArgument
+	for lineIdx, docstringLine := range docstringLines {
+		trimmedLine := strings.TrimSpace(docstringLine)
+		lineIndentation := len(docstringLine) - len(strings.TrimLeft(docstringLine, " \t"))
+		if argsSectionHeaderIndentation < 0 {
+			if trimmedLine == DocstringArgsSectionHeader {
+				argsSectionHeaderIndentation = lineIndentation
+			}
+			continue
+		}
+		if trimmedLine == "" {
+			continue
+		}
+		if lineIndentation <= argsSectionHeaderIndentation {
+			break
+		}
+		if argumentIndentation < 0 {
+			argumentIndentation = lineIndentation
+		}
+		if lineIndentation > argumentIndentation && currentArgument != nil {
+			currentArgument.description = strings.TrimSpace(currentArgument.description + " " + trimmedLine)
+			continue
+		}
+		argumentMatch := docstringArgumentRegexp.FindStringSubmatch(trimmedLine)
+		if argumentMatch == nil {
+			currentArgument = nil
+			continue
+		}
+		currentArgument = &DocumentedArgument{
+			name:           argumentMatch[docstringArgumentRegexp.SubexpIndex("name")],
+			documentedType: strings.TrimSpace(argumentMatch[docstringArgumentRegexp.SubexpIndex("type")]),
+			description:    strings.TrimSpace(argumentMatch[docstringArgumentRegexp.SubexpIndex("description")]),
+			line:           int(docstring.TokenPos.Line) + lineIdx,
+			column:         lineIndentation + 1,
+		}
+		documentedArguments = append(documentedArguments, currentArgument)
+	}
+	return documentedArguments
+}
+
+// getDocstring returns the string literal the function body starts with, or nil if it doesn't start with one
+func getDocstring(function *syntax.DefStmt) *syntax.Literal {
+	if len(function.Body) == 0 {
+		return nil
+	}
+	firstStatement, isExprStatement := function.Body[0].(*syntax.ExprStmt)
+	if !isExprStatement {
+		return nil
+	}
+	docstring, isLiteral := firstStatement.X.(*syntax.Literal)
+	if !isLiteral || docstring.Token != syntax.STRING {
+		return nil
+	}
+	return docstring
+}
@@ -0,0 +1,200 @@
+package starlarkfile
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const (
+	testFunctionName = "run"
+)
+
+// testSignatureArgument is the data of a signature argument compared by the tests, without its position
+type testSignatureArgument struct {
+	name             string
+	isVariadic       bool
+	isRequired       bool
+	defaultValue     string
+	defaultValueType string
+	commentedType    string
+}
+
+// testDocumentedArgument is the data of a documented argument compared by the tests, with its position
+type testDocumentedArgument struct {
+	name           string
+	documentedType string
+	description    string
+	line           int
+	column         int
+}
+
+func TestExtractFunctionArguments_SignatureArguments(t *testing.T) {
+	testCases := []struct {
+		name                       string
+		functionContent            string
+		expectedSignatureArguments []*testSignatureArgument
+	}{
+		{
+			name:            "required arguments",
+			functionContent: "def run(plan, name):\n    pass\n",
+			expectedSignatureArguments: []*testSignatureArgument{
+				{name: "plan", isRequired: true},
+				{name: "name", isRequired: true},
+			},
+		},
+		{
+			name:            "default values",
+			functionContent: "def run(plan, name = \"postgres\", count = 3, ratio = 0.5, enabled = True, extra = None, ports = [], labels = {}, image = get_image()):\n    pass\n",
+			expectedSignatureArguments: []*testSignatureArgument{
+				{name: "plan", isRequired: true},
+				{name: "name", defaultValue: "\"postgres\"", defaultValueType: "string"},
+				{name: "count", defaultValue: "3", defaultValueType: "int"},
+				{name: "ratio", defaultValue: "0.5", defaultValueType: "float"},
+				{name: "enabled", defaultValue: "True", defaultValueType: "bool"},
+				{name: "extra", defaultValue: "None"},
+				{name: "ports", defaultValueType: "list"},
+				{name: "labels", defaultValueType: "dict"},
+				{name: "image"},
+			},
+		},
+		{
+			name:            "variadic arguments",
+			functionContent: "def run(plan, *args, **kwargs):\n    pass\n",
+			expectedSignatureArguments: []*testSignatureArgument{
+				{name: "plan", isRequired: true},
+				{name: "args", isVariadic: true},
+				{name: "kwargs", isVariadic: true},
+			},
+		},
+		{
+			name:            "keyword-only arguments",
+			functionContent: "def run(plan, *, name, count = 1):\n    pass\n",
+			expectedSignatureArguments: []*testSignatureArgument{
+				{name: "plan", isRequired: true},
+				{name: "name", isRequired: true},
+				{name: "count", defaultValue: "1", defaultValueType: "int"},
+			},
+		},
+		{
+			name:            "type comments",
+			functionContent: "def run(\n    plan,\n    name, # type: string\n    count = 3,  #type:int\n    ports = [], # type: list[int]\n    labels = {}, # the labels of the service\n    enabled = True):  # type: bool\n    pass\n",
+			expectedSignatureArguments: []*testSignatureArgument{
+				{name: "plan", isRequired: true},
+				{name: "name", isRequired: true, commentedType: "string"},
+				{name: "count", defaultValue: "3", defaultValueType: "int", commentedType: "int"},
+				{name: "ports", defaultValueType: "list", commentedType: "list"},
+				{name: "labels", defaultValueType: "dict"},
+				{name: "enabled", defaultValue: "True", defaultValueType: "bool", commentedType: "bool"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			functionArguments := getTestFunctionArguments(t, testCase.functionContent)
+			signatureArguments := []*testSignatureArgument{}
+			for _, signatureArgument := range functionArguments.GetSignatureArguments() {
+				signatureArguments = append(signatureArguments, &testSignatureArgument{
+					name:             signatureArgument.GetName(),
+					isVariadic:       signatureArgument.IsVariadic(),
+					isRequired:       signatureArgument.IsRequired(),
+					defaultValue:     signatureArgument.GetDefaultValue(),
+					defaultValueType: signatureArgument.GetDefaultValueType(),
+					commentedType:    signatureArgument.GetCommentedType(),
+				})
+			}
+			require.Equal(t, testCase.expectedSignatureArguments, signatureArguments)
+		})
+	}
+}
+
+func TestExtractFunctionArguments_SignatureArgumentPosition(t *testing.T) {
+	functionArguments := getTestFunctionArguments(t, "def run(plan,\n        name = \"postgres\"):\n    pass\n")
+	nameArgument := functionArguments.FindSignatureArgument("name")
+	require.NotNil(t, nameArgument)
+	require.Equal(t, 2, nameArgument.GetLine())
+	require.Equal(t, 9, nameArgument.GetColumn())
+	require.Nil(t, functionArguments.FindSignatureArgument("missing"))
+}
+
+func TestExtractFunctionArguments_DocumentedArguments(t *testing.T) {
+	testCases := []struct {
+		name                        string
+		functionContent             string
+		expectedDocumentedArguments []*testDocumentedArgument
+	}{
+		{
+			name:                        "no docstring",
+			functionContent:             "def run(plan, name):\n    pass\n",
+			expectedDocumentedArguments: []*testDocumentedArgument{},
+		},
+		{
+			name:                        "docstring without args block",
+			functionContent:             "def run(plan, name):\n    \"\"\"Runs the service\n\n    Returns:\n        the service\n    \"\"\"\n    pass\n",
+			expectedDocumentedArguments: []*testDocumentedArgument{},
+		},
+		{
+			name:            "arguments with and without type",
+			functionContent: "def run(plan, name, count):\n    \"\"\"Runs the service\n\n    Args:\n        name (string): the name of the service\n        count: how many services to run\n    \"\"\"\n    pass\n",
+			expectedDocumentedArguments: []*testDocumentedArgument{
+				{name: "name", documentedType: "string", description: "the name of the service", line: 5, column: 9},
+				{name: "count", documentedType: "", description: "how many services to run", line: 6, column: 9},
+			},
+		},
+		{
+			name:            "continuation lines",
+			functionContent: "def run(plan, participants, network_id):\n    \"\"\"Runs the network\n\n    Args:\n        participants (list[json]): the nodes of the network,\n            each one with its client\n\n            and its image\n        network_id (string): the id of the network\n    \"\"\"\n    pass\n",
+			expectedDocumentedArguments: []*testDocumentedArgument{
+				{name: "participants", documentedType: "list[json]", description: "the nodes of the network, each one with its client and its image", line: 5, column: 9},
+				{name: "network_id", documentedType: "string", description: "the id of the network", line: 9, column: 9},
+			},
+		},
+		{
+			name:            "block ending at the next section",
+			functionContent: "def run(plan, name):\n    \"\"\"Runs the service\n\n    Args:\n      name (string): the name of the service\n    Returns:\n      service (string): the service\n    \"\"\"\n    pass\n",
+			expectedDocumentedArguments: []*testDocumentedArgument{
+				{name: "name", documentedType: "string", description: "the name of the service", line: 5, column: 7},
+			},
+		},
+		{
+			name:            "tab indentation",
+			functionContent: "def run(plan, name):\n\t\"\"\"Runs the service\n\n\tArgs:\n\t\tname (string): the name of the service\n\t\"\"\"\n\tpass\n",
+			expectedDocumentedArguments: []*testDocumentedArgument{
+				{name: "name", documentedType: "string", description: "the name of the service", line: 5, column: 3},
+			},
+		},
+		{
+			name:            "lines that aren't arguments",
+			functionContent: "def run(plan, name):\n    \"\"\"Runs the service\n\n    Args:\n        see the README for the arguments\n        name (string): the name of the service\n    \"\"\"\n    pass\n",
+			expectedDocumentedArguments: []*testDocumentedArgument{
+				{name: "name", documentedType: "string", description: "the name of the service", line: 6, column: 9},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			functionArguments := getTestFunctionArguments(t, testCase.functionContent)
+			documentedArguments := []*testDocumentedArgument{}
+			for _, documentedArgument := range functionArguments.GetDocumentedArguments() {
+				documentedArguments = append(documentedArguments, &testDocumentedArgument{
+					name:           documentedArgument.GetName(),
+					documentedType: documentedArgument.GetDocumentedType(),
+					description:    documentedArgument.GetDescription(),
+					line:           documentedArgument.GetLine(),
+					column:         documentedArgument.GetColumn(),
+				})
+			}
+			require.Equal(t, testCase.expectedDocumentedArguments, documentedArguments)
+		})
+	}
+}
+
+// getTestFunctionArguments returns the arguments of the run function of the Starlark file content
+func getTestFunctionArguments(t *testing.T, fileContent string) *FunctionArguments {
+	file, syntaxErr := ParseFile("main.star", []byte(fileContent))
+	require.Nil(t, syntaxErr)
+	function := file.FindTopLevelFunction(testFunctionName)
+	require.NotNil(t, function)
+	return ExtractFunctionArguments(function)
+}
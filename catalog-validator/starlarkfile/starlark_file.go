@@ -0,0 +1,63 @@
+package starlarkfile
+
+import (
+	"go.starlark.net/syntax"
+)
+
+// File is a parsed Starlark file of a package, it keeps the comments so the docstrings and type comments can be read
+type File struct {
+	filepath   string
+	syntaxFile *syntax.File
+}
+
+// SyntaxError is the first syntax error of a Starlark file, the parser stops on it
+type SyntaxError struct {
+	line    int
+	column  int
+	message string
+}
+
+// ParseFile returns the parsed Starlark file, or the syntax error with its position if the content isn't valid Starlark
+func ParseFile(filepath string, content []byte) (*File, *SyntaxError) {
+	syntaxFile, err := syntax.LegacyFileOptions().Parse(filepath, content, syntax.RetainComments)
+	if err != nil {
+		if syntaxErr, isSyntaxErr := err.(syntax.Error); isSyntaxErr {
+			return nil, &SyntaxError{line: int(syntaxErr.Pos.Line), column: int(syntaxErr.Pos.Col), message: syntaxErr.Msg}
+		}
+		return nil, &SyntaxError{line: 0, column: 0, message: err.Error()}
+	}
+	return &File{filepath: filepath, syntaxFile: syntaxFile}, nil
+}
+
+func (file *File) GetFilepath() string {
+	return file.filepath
+}
+
+// GetSyntaxFile returns the syntax tree of the file, to walk it with syntax.Walk
+func (file *File) GetSyntaxFile() *syntax.File {
+	return file.syntaxFile
+}
+
+// FindTopLevelFunction returns the function defined with the name at the top level of the file, or nil if there isn't any
+func (file *File) FindTopLevelFunction(functionName string) *syntax.DefStmt {
+	for _, statement := range file.syntaxFile.Stmts {
+		if defStatement, isDefStatement := statement.(*syntax.DefStmt); isDefStatement && defStatement.Name.Name == functionName {
+			return defStatement
+		}
+	}
+	return nil
+}
+
+// GetLine returns the line of the syntax error, starting at 1. It's zero if it's unknown
+func (syntaxError *SyntaxError) GetLine() int {
+	return syntaxError.line
+}
+
+// GetColumn returns the column of the syntax error, starting at 1. It's zero if it's unknown
+func (syntaxError *SyntaxError) GetColumn() int {
+	return syntaxError.column
+}
+
+func (syntaxError *SyntaxError) GetMessage() string {
+	return syntaxError.message
+}
@@ -2,22 +2,109 @@ package rules
 
 import (
 	"context"
-	"github.com/kurtosis-tech/kurtosis-package-indexer/server/github"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/source"
 	"github.com/kurtosis-tech/stacktrace"
 )
 
-func GetAll(ctx context.Context) ([]Rule, error) {
+var (
+	// ruleNamesWithoutParameters are the rules that don't have parameters, setting any in the rules config is rejected
+	ruleNamesWithoutParameters = []RuleName{
+		duplicatedPackageRuleName,
+		validPackageManifestRuleName,
+		runnablePackageRuleName,
+		validRunArgumentsRuleName,
+		resolvableLocatorsRuleName,
+	}
+)
 
-	gitHubClient, err := github.CreateGithubClient(ctx)
+// noRuleParameters is used by the rules without parameters, so setting any in the rules config is rejected
+type noRuleParameters struct{}
+
+// GetAll returns all the rules enabled in the rules config with their parameters, the ones that check the package
+// repositories content read it with the package source reader. The catalog change author is the GitHub user proposing
+// the catalog change, it's empty if it's unknown. The full catalog has all the packages in the catalog file, even the
+// ones that aren't validated, the rules looking across packages use the validated catalog if it's nil. The default
+// rules config is used if it's nil
+func GetAll(_ context.Context, packageSourceReader source.PackageSourceReader, catalogChangeAuthor string, fullCatalog catalog.PackageCatalog, rulesConfig *RulesConfig) ([]Rule, error) {
+	if rulesConfig == nil {
+		rulesConfig = NewDefaultRulesConfig()
+	}
+
+	for _, ruleName := range ruleNamesWithoutParameters {
+		if err := rulesConfig.decodeRuleParameters(ruleName, &noRuleParameters{}); err != nil {
+			return nil, stacktrace.Propagate(err, "an error occurred getting the parameters of rule '%s'", ruleName)
+		}
+	}
+	validPackageRuleObj, err := newValidPackageRuleFromConfig(packageSourceReader, rulesConfig)
 	if err != nil {
-		return nil, stacktrace.Propagate(err, "an error occurred creating the GitHub client")
+		return nil, stacktrace.Propagate(err, "an error occurred creating the '%s' rule", validPackageRuleName)
+	}
+	validPackageRefRuleObj, err := newValidPackageRefRuleFromConfig(packageSourceReader, rulesConfig)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred creating the '%s' rule", validPackageRefRuleName)
+	}
+	validPackageIconRuleObj, err := newValidPackageIconRuleFromConfig(packageSourceReader, rulesConfig)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred creating the '%s' rule", validPackageIconRuleName)
+	}
+	validPackageDescriptionRuleObj, err := newValidPackageDescriptionRuleFromConfig(packageSourceReader, rulesConfig)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred creating the '%s' rule", validPackageDescriptionRuleName)
+	}
+	// the locators are resolved once for both rules using them
+	packageLocatorsCacheObj := newPackageLocatorsCache(packageSourceReader)
+	packageDependenciesRuleObj, err := newPackageDependenciesRuleFromConfig(packageLocatorsCacheObj, fullCatalog, rulesConfig)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred creating the '%s' rule", packageDependenciesRuleName)
+	}
+	pinnedContainerImagesRuleObj, err := newPinnedContainerImagesRuleFromConfig(packageSourceReader, rulesConfig)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred creating the '%s' rule", pinnedContainerImagesRuleName)
+	}
+	removedPackageRuleObj, err := newRemovedPackageRuleFromConfig(catalogChangeAuthor, rulesConfig)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred creating the '%s' rule", removedPackageRuleName)
 	}
 
 	allRules := []Rule{
 		newDuplicatedPackageRule(),
-		newValidPackageRule(gitHubClient),
-		newValidPackageIconRule(gitHubClient),
+		validPackageRuleObj,
+		validPackageRefRuleObj,
+		newValidPackageManifestRule(packageSourceReader),
+		validPackageIconRuleObj,
+		validPackageDescriptionRuleObj,
+		newRunnablePackageRule(packageSourceReader),
+		newValidRunArgumentsRule(packageSourceReader),
+		newResolvableLocatorsRule(packageLocatorsCacheObj, fullCatalog),
+		packageDependenciesRuleObj,
+		pinnedContainerImagesRuleObj,
+		removedPackageRuleObj,
+	}
+
+	allRuleNames := map[RuleName]bool{}
+	for _, rule := range allRules {
+		allRuleNames[rule.GetName()] = true
+	}
+	for _, configuredRuleName := range rulesConfig.getRuleNames() {
+		if !allRuleNames[configuredRuleName] {
+			return nil, stacktrace.NewError("the rules config contains the rule '%s' but there isn't a rule with that name, the rules are: %v", configuredRuleName, getRuleNames(allRules))
+		}
+	}
+
+	enabledRules := []Rule{}
+	for _, rule := range allRules {
+		if rulesConfig.IsRuleEnabled(rule.GetName()) {
+			enabledRules = append(enabledRules, rule)
+		}
 	}
+	return enabledRules, nil
+}
 
-	return allRules, nil
+func getRuleNames(rules []Rule) []RuleName {
+	ruleNames := []RuleName{}
+	for _, rule := range rules {
+		ruleNames = append(ruleNames, rule.GetName())
+	}
+	return ruleNames
 }
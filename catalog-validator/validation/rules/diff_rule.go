@@ -0,0 +1,20 @@
+package rules
+
+import (
+	"context"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+)
+
+// DiffRule is a Rule that checks the changes made to the catalog instead of the packages in it, e.g. a policy about
+// who can remove a package. It's only checked when the catalog is compared with a base catalog
+type DiffRule interface {
+	Rule
+	// CheckDiff returns the failures of the catalog changes, grouped by the package name of the changed entry
+	CheckDiff(ctx context.Context, catalogDiff *catalog.CatalogDiff) *CheckResult
+}
+
+// checkNothingWithoutDiff is the Check of the diff rules, they don't find anything to check in a catalog alone
+func checkNothingWithoutDiff(diffRule DiffRule) *CheckResult {
+	return NewCheckResultFromFailures(diffRule.GetName(), map[types.PackageName][]*Failure{})
+}
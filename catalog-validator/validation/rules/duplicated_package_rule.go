@@ -2,12 +2,15 @@ package rules
 
 import (
 	"context"
-	"github.com/kurtosis-tech/kurtosis-package-indexer/server/catalog"
+	"fmt"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
 	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
 )
 
 const (
 	duplicatedPackageRuleName = "Duplicated package"
+
+	duplicatedPackageNameFailureCode FailureCode = "DUPLICATED_PACKAGE_NAME"
 )
 
 // duplicatedPackageRule checks that there is not duplicated packages name in the catalog
@@ -23,21 +26,40 @@ func (duplicatedPackageRule *duplicatedPackageRule) GetName() RuleName {
 	return RuleName(duplicatedPackageRule.name)
 }
 
+func (duplicatedPackageRule *duplicatedPackageRule) GetDescription() string {
+	return "checks that each package is only once in the catalog"
+}
+
+func (duplicatedPackageRule *duplicatedPackageRule) GetParameters() map[string]interface{} {
+	return map[string]interface{}{}
+}
+
+func (duplicatedPackageRule *duplicatedPackageRule) GetDefaultSeverity() Severity {
+	return SeverityError
+}
+
 func (duplicatedPackageRule *duplicatedPackageRule) Check(_ context.Context, catalog catalog.PackageCatalog) *CheckResult {
 
 	wasValidated := true
-	failures := map[types.PackageName][]string{}
+	failures := map[types.PackageName][]*Failure{}
 
-	packageNames := map[types.PackageName]bool{}
+	// firstPackageLines contains the line in the catalog file of the first entry of each package
+	firstPackageLines := map[types.PackageName]int{}
 
 	for _, packageData := range catalog {
 		packageName := packageData.GetPackageName()
-		if _, found := packageNames[packageName]; found {
-			failures[packageName] = []string{"duplicated name"}
+		if firstPackageLine, found := firstPackageLines[packageName]; found {
+			duplicatedPackageFailure := newFailure(
+				duplicatedPackageNameFailureCode,
+				"",
+				fmt.Sprintf("duplicated name, the package is already in the catalog at line %d", firstPackageLine),
+				"remove the duplicated entry from the package catalog",
+			).withCatalogPosition(packageData.GetCatalogLine(), packageData.GetCatalogColumn())
+			failures[packageName] = append(failures[packageName], duplicatedPackageFailure)
 			wasValidated = false
 			continue
 		}
-		packageNames[packageName] = true
+		firstPackageLines[packageName] = packageData.GetCatalogLine()
 	}
 
 	checkResult := newCheckResult(duplicatedPackageRule.GetName(), wasValidated, failures)
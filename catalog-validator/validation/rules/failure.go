@@ -0,0 +1,161 @@
+package rules
+
+import (
+	"github.com/kurtosis-tech/stacktrace"
+	"strings"
+)
+
+// FailureCode identifies the kind of failure, it's stable so tools can rely on it instead of on the failure message
+type FailureCode string
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+
+	// ruleSeverity is used by the failures that take the severity of the rule that found them
+	ruleSeverity Severity = ""
+
+	repositoryRootPath = "/"
+
+	// unknownPosition is used for the line and column when the failure can't point to a specific place in the file
+	unknownPosition = 0
+)
+
+// Failure is a problem found by a rule in a package
+type Failure struct {
+	code     FailureCode
+	severity Severity
+	message  string
+
+	// filepath is the offending file path, relative to the package repository root. It's empty when the failure isn't about a file
+	filepath string
+
+	// line and column start at 1, they are zero when the position in the file is unknown
+	line   int
+	column int
+
+	// remediation is a hint for the package author about how to fix the failure
+	remediation string
+
+	// catalogLine and catalogColumn are the position of the package entry in the catalog file, they are zero when
+	// the failure isn't about a single entry
+	catalogLine   int
+	catalogColumn int
+}
+
+// newFailure returns a failure with the severity of the rule that found it
+func newFailure(code FailureCode, filepath string, message string, remediation string) *Failure {
+	return &Failure{
+		code:          code,
+		severity:      ruleSeverity,
+		message:       message,
+		filepath:      strings.TrimPrefix(filepath, repositoryRootPath),
+		line:          unknownPosition,
+		column:        unknownPosition,
+		remediation:   remediation,
+		catalogLine:   unknownPosition,
+		catalogColumn: unknownPosition,
+	}
+}
+
+// withSeverity sets a default severity for the failure that replaces the default severity of the rule, the severity of
+// the rule overridden for a run still replaces it
+func (failure *Failure) withSeverity(severity Severity) *Failure {
+	failure.severity = severity
+	return failure
+}
+
+// withPosition sets the line and column in the file where the failure was found
+func (failure *Failure) withPosition(line int, column int) *Failure {
+	failure.line = line
+	failure.column = column
+	return failure
+}
+
+// withCatalogPosition sets the position of the package entry in the catalog file
+func (failure *Failure) withCatalogPosition(catalogLine int, catalogColumn int) *Failure {
+	failure.catalogLine = catalogLine
+	failure.catalogColumn = catalogColumn
+	return failure
+}
+
+// withRuleSeverity returns a copy of the failure that has the rule severity if the failure doesn't have its own severity
+func (failure *Failure) withRuleSeverity(severity Severity) *Failure {
+	failureCopy := *failure
+	if failureCopy.severity == ruleSeverity {
+		failureCopy.severity = severity
+	}
+	return &failureCopy
+}
+
+// withRuleSeverityOverride returns a copy of the failure that has the rule severity overridden for the run, even if the
+// failure has its own default severity
+func (failure *Failure) withRuleSeverityOverride(severity Severity) *Failure {
+	failureCopy := *failure
+	failureCopy.severity = severity
+	return &failureCopy
+}
+
+func (failure *Failure) GetCode() FailureCode {
+	return failure.code
+}
+
+func (failure *Failure) GetSeverity() Severity {
+	return failure.severity
+}
+
+func (failure *Failure) GetMessage() string {
+	return failure.message
+}
+
+func (failure *Failure) GetFilepath() string {
+	return failure.filepath
+}
+
+func (failure *Failure) GetLine() int {
+	return failure.line
+}
+
+func (failure *Failure) GetColumn() int {
+	return failure.column
+}
+
+func (failure *Failure) GetRemediation() string {
+	return failure.remediation
+}
+
+func (failure *Failure) GetCatalogLine() int {
+	return failure.catalogLine
+}
+
+func (failure *Failure) GetCatalogColumn() int {
+	return failure.catalogColumn
+}
+
+// HasPosition returns true if the line where the failure was found is known
+func (failure *Failure) HasPosition() bool {
+	return failure.line != unknownPosition
+}
+
+// HasCatalogPosition returns true if the line of the package entry in the catalog file is known
+func (failure *Failure) HasCatalogPosition() bool {
+	return failure.catalogLine != unknownPosition
+}
+
+// IsError returns true if the failure makes the catalog invalid
+func (failure *Failure) IsError() bool {
+	return failure.severity == SeverityError
+}
+
+// ParseSeverity returns the severity matching the string, or an error if it isn't a valid one
+func ParseSeverity(severityStr string) (Severity, error) {
+	for _, severity := range []Severity{SeverityError, SeverityWarning, SeverityInfo} {
+		if severityStr == string(severity) {
+			return severity, nil
+		}
+	}
+	return ruleSeverity, stacktrace.NewError("invalid severity '%s', the valid ones are '%s', '%s' and '%s'", severityStr, SeverityError, SeverityWarning, SeverityInfo)
+}
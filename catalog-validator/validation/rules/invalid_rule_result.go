@@ -8,13 +8,53 @@ import (
 type CheckResult struct {
 	ruleName     RuleName
 	wasValidated bool
-	failures     map[types.PackageName][]string
+	failures     map[types.PackageName][]*Failure
 }
 
-func newCheckResult(ruleName RuleName, wasValidated bool, failures map[types.PackageName][]string) *CheckResult {
+func newCheckResult(ruleName RuleName, wasValidated bool, failures map[types.PackageName][]*Failure) *CheckResult {
 	return &CheckResult{ruleName: ruleName, wasValidated: wasValidated, failures: failures}
 }
 
+// NewCheckResultFromFailures returns the result of a rule check, which was validated if none of the failures is an error
+func NewCheckResultFromFailures(ruleName RuleName, failures map[types.PackageName][]*Failure) *CheckResult {
+	wasValidated := true
+	for _, packageFailures := range failures {
+		for _, failure := range packageFailures {
+			if failure.IsError() {
+				wasValidated = false
+			}
+		}
+	}
+	return newCheckResult(ruleName, wasValidated, failures)
+}
+
+// WithRuleSeverity returns a copy of the check result where the failures without their own severity have the rule severity
+func (ruleReport *CheckResult) WithRuleSeverity(severity Severity) *CheckResult {
+	failures := map[types.PackageName][]*Failure{}
+	for packageName, packageFailures := range ruleReport.failures {
+		failuresWithSeverity := make([]*Failure, len(packageFailures))
+		for failureIndex, failure := range packageFailures {
+			failuresWithSeverity[failureIndex] = failure.withRuleSeverity(severity)
+		}
+		failures[packageName] = failuresWithSeverity
+	}
+	return NewCheckResultFromFailures(ruleReport.ruleName, failures)
+}
+
+// WithRuleSeverityOverride returns a copy of the check result where all the failures have the rule severity overridden
+// for the run, also the ones with their own default severity
+func (ruleReport *CheckResult) WithRuleSeverityOverride(severity Severity) *CheckResult {
+	failures := map[types.PackageName][]*Failure{}
+	for packageName, packageFailures := range ruleReport.failures {
+		failuresWithSeverity := make([]*Failure, len(packageFailures))
+		for failureIndex, failure := range packageFailures {
+			failuresWithSeverity[failureIndex] = failure.withRuleSeverityOverride(severity)
+		}
+		failures[packageName] = failuresWithSeverity
+	}
+	return NewCheckResultFromFailures(ruleReport.ruleName, failures)
+}
+
 func (ruleReport *CheckResult) GetRuleName() RuleName {
 	return ruleReport.ruleName
 }
@@ -23,11 +63,11 @@ func (ruleReport *CheckResult) WasValidated() bool {
 	return ruleReport.wasValidated
 }
 
-func (ruleReport *CheckResult) GetFailures() map[types.PackageName][]string {
+func (ruleReport *CheckResult) GetFailures() map[types.PackageName][]*Failure {
 	return ruleReport.failures
 }
 
-func (ruleReport *CheckResult) GetFailuresForPackage(packageName types.PackageName) ([]string, error) {
+func (ruleReport *CheckResult) GetFailuresForPackage(packageName types.PackageName) ([]*Failure, error) {
 	failures, found := ruleReport.failures[packageName]
 	if !found {
 		return nil, stacktrace.NewError("Expected to find failures for package '%s' but nothing was found, this is a bug in the catalog", packageName)
@@ -0,0 +1,200 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/dependencygraph"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/source"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
+
+const (
+	packageDependenciesRuleName = "Package dependencies"
+
+	// defaultMaxDependencyDepth is the longest chain of dependencies a package can have, a package depending on a
+	// package without dependencies has a depth of 1
+	defaultMaxDependencyDepth = 5
+
+	dependencyChainSeparator = " -> "
+
+	dependencyCycleFailureCode        FailureCode = "DEPENDENCY_CYCLE"
+	dependencyNotInCatalogFailureCode FailureCode = "DEPENDENCY_NOT_IN_CATALOG"
+	dependencyChainTooDeepFailureCode FailureCode = "DEPENDENCY_CHAIN_TOO_DEEP"
+)
+
+// packageDependenciesRuleParameters are the parameters of the rule that can be set in the rules config
+type packageDependenciesRuleParameters struct {
+	MaxDepth int `yaml:"max-depth"`
+}
+
+// packageDependenciesRule checks the dependencies between the catalog packages, which are the other packages their
+// locators point to, by checking if:
+// 1- the package isn't in a cycle of dependencies
+// 2- the packages it depends on are in the catalog, it's a warning because they still run
+// 3- its longest chain of dependencies isn't deeper than maxDepth
+// The dependencies are looked up in the full catalog, so the new packages can depend on the ones already in it
+type packageDependenciesRule struct {
+	name                 string
+	packageLocatorsCache *packageLocatorsCache
+
+	// fullCatalog is the catalog with all the packages, the checked catalog is used if it's nil
+	fullCatalog catalog.PackageCatalog
+
+	maxDepth int
+}
+
+func newPackageDependenciesRule(packageLocatorsCache *packageLocatorsCache, fullCatalog catalog.PackageCatalog, maxDepth int) *packageDependenciesRule {
+	return &packageDependenciesRule{name: packageDependenciesRuleName, packageLocatorsCache: packageLocatorsCache, fullCatalog: fullCatalog, maxDepth: maxDepth}
+}
+
+// newPackageDependenciesRuleFromConfig returns the rule with the max depth set in the rules config, or the default one
+func newPackageDependenciesRuleFromConfig(packageLocatorsCache *packageLocatorsCache, fullCatalog catalog.PackageCatalog, rulesConfig *RulesConfig) (*packageDependenciesRule, error) {
+	parameters := &packageDependenciesRuleParameters{MaxDepth: defaultMaxDependencyDepth}
+	if err := rulesConfig.decodeRuleParameters(packageDependenciesRuleName, parameters); err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred getting the parameters of rule '%s'", packageDependenciesRuleName)
+	}
+	if parameters.MaxDepth < 1 {
+		return nil, stacktrace.NewError("expected the 'max-depth' parameter of rule '%s' to be at least 1, but it's '%d'", packageDependenciesRuleName, parameters.MaxDepth)
+	}
+	return newPackageDependenciesRule(packageLocatorsCache, fullCatalog, parameters.MaxDepth), nil
+}
+
+func (packageDependenciesRule *packageDependenciesRule) GetName() RuleName {
+	return RuleName(packageDependenciesRule.name)
+}
+
+func (packageDependenciesRule *packageDependenciesRule) GetDescription() string {
+	return "checks that the packages aren't in a cycle of dependencies, that their dependencies are in the catalog and that their dependency chains aren't too deep"
+}
+
+func (packageDependenciesRule *packageDependenciesRule) GetParameters() map[string]interface{} {
+	return map[string]interface{}{
+		"max-depth": packageDependenciesRule.maxDepth,
+	}
+}
+
+func (packageDependenciesRule *packageDependenciesRule) GetDefaultSeverity() Severity {
+	return SeverityError
+}
+
+func (packageDependenciesRule *packageDependenciesRule) Check(ctx context.Context, catalogToCheck catalog.PackageCatalog) *CheckResult {
+	fullCatalog := packageDependenciesRule.fullCatalog
+	if fullCatalog == nil {
+		fullCatalog = catalogToCheck
+	}
+	graph := buildDependencyGraph(ctx, packageDependenciesRule.packageLocatorsCache, fullCatalog, catalogToCheck)
+
+	failures := map[types.PackageName][]*Failure{}
+	checkedPackageNames := map[types.PackageName]bool{}
+	for _, packageData := range catalogToCheck {
+		packageName := packageData.GetPackageName()
+		if checkedPackageNames[packageName] {
+			continue
+		}
+		checkedPackageNames[packageName] = true
+		logrus.Debugf("Checking the dependencies of package '%s'...", packageName)
+		packageFailures := packageDependenciesRule.checkPackageDependencies(graph, packageName)
+		for _, failure := range packageFailures {
+			failure.withCatalogPosition(packageData.GetCatalogLine(), packageData.GetCatalogColumn())
+		}
+		if len(packageFailures) > 0 {
+			failures[packageName] = packageFailures
+		} else {
+			logrus.Debugf("...dependencies of package '%s' successfully validated.", packageName)
+		}
+	}
+	return NewCheckResultFromFailures(packageDependenciesRule.GetName(), failures)
+}
+
+func (packageDependenciesRule *packageDependenciesRule) checkPackageDependencies(graph *dependencygraph.DependencyGraph, packageName types.PackageName) []*Failure {
+	failures := []*Failure{}
+	if cycle := graph.GetCycle(packageName); len(cycle) > 0 {
+		failures = append(failures, newFailure(
+			dependencyCycleFailureCode,
+			"",
+			fmt.Sprintf("the package is in a cycle of dependencies: %s", formatDependencyChain(cycle)),
+			"remove one of the dependencies of the cycle, e.g. by moving the shared code to another package",
+		))
+	}
+	for _, dependency := range graph.GetDependencies(packageName) {
+		if graph.IsInCatalog(dependency) {
+			continue
+		}
+		failures = append(failures, newFailure(
+			dependencyNotInCatalogFailureCode,
+			"",
+			fmt.Sprintf("the package depends on package '%s', which isn't in the catalog", dependency),
+			"add the dependency to the catalog, or depend on a package of the catalog instead",
+		).withSeverity(SeverityWarning))
+	}
+	if longestChain := graph.GetLongestDependencyChain(packageName); len(longestChain)-1 > packageDependenciesRule.maxDepth {
+		failures = append(failures, newFailure(
+			dependencyChainTooDeepFailureCode,
+			"",
+			fmt.Sprintf("the package has a chain of '%d' dependencies but the max depth is '%d': %s", len(longestChain)-1, packageDependenciesRule.maxDepth, formatDependencyChain(longestChain)),
+			"depend directly on the packages deep in the chain, or merge some of them",
+		))
+	}
+	return failures
+}
+
+// BuildDependencyGraph returns the graph with all the catalog packages and the dependencies of the packages reachable
+// from the starting ones, the dependencies of a package are the other packages its locators point to. The locators
+// that don't resolve are skipped, the Resolvable locators rule reports them
+func BuildDependencyGraph(ctx context.Context, packageSourceReader source.PackageSourceReader, packageCatalog catalog.PackageCatalog, startingPackages catalog.PackageCatalog) *dependencygraph.DependencyGraph {
+	return buildDependencyGraph(ctx, newPackageLocatorsCache(packageSourceReader), packageCatalog, startingPackages)
+}
+
+// buildDependencyGraph builds the graph with the locators of the cache, so the packages already resolved by the
+// Resolvable locators rule aren't read again
+func buildDependencyGraph(ctx context.Context, packageLocatorsCache *packageLocatorsCache, packageCatalog catalog.PackageCatalog, startingPackages catalog.PackageCatalog) *dependencygraph.DependencyGraph {
+	graph := dependencygraph.NewDependencyGraph()
+
+	// the package names are compared ignoring the case, like GitHub does with the repository owners and names
+	catalogPackages := map[string]*catalog.CatalogPackage{}
+	for _, catalogPackage := range packageCatalog {
+		graph.AddPackage(catalogPackage.GetPackageName(), true)
+		catalogPackages[strings.ToLower(string(catalogPackage.GetPackageName()))] = catalogPackage
+	}
+
+	visitedPackageNames := map[types.PackageName]bool{}
+	pendingPackages := []*catalog.CatalogPackage{}
+	for _, startingPackage := range startingPackages {
+		graph.AddPackage(startingPackage.GetPackageName(), true)
+		pendingPackages = append(pendingPackages, startingPackage)
+	}
+	for len(pendingPackages) > 0 {
+		packageData := pendingPackages[0]
+		pendingPackages = pendingPackages[1:]
+		packageName := packageData.GetPackageName()
+		if visitedPackageNames[packageName] {
+			continue
+		}
+		visitedPackageNames[packageName] = true
+
+		logrus.Debugf("Getting the dependencies of package '%s'", packageName)
+		for _, dependency := range packageLocatorsCache.getPackageLocators(ctx, packageCatalog, packageData).dependencies {
+			dependencyPackage, isInCatalog := catalogPackages[strings.ToLower(string(dependency))]
+			if !isInCatalog {
+				graph.AddDependency(packageName, dependency)
+				continue
+			}
+			graph.AddDependency(packageName, dependencyPackage.GetPackageName())
+			pendingPackages = append(pendingPackages, dependencyPackage)
+		}
+	}
+	return graph
+}
+
+// formatDependencyChain returns the chain of packages as 'a -> b -> c'
+func formatDependencyChain(chain []types.PackageName) string {
+	chainStrs := []string{}
+	for _, packageName := range chain {
+		chainStrs = append(chainStrs, string(packageName))
+	}
+	return strings.Join(chainStrs, dependencyChainSeparator)
+}
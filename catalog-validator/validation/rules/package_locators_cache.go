@@ -0,0 +1,70 @@
+package rules
+
+import (
+	"context"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/source"
+	"strings"
+	"sync"
+)
+
+const (
+	packageLocatorsCacheKeyRefSeparator = "@"
+)
+
+// packageLocatorsCache keeps the resolved locators of each package, so the Resolvable locators and the Package
+// dependencies rules read the package files only once. It's shared by the rules created together, which check the same
+// catalog, so the packages are keyed by their name and ref only
+type packageLocatorsCache struct {
+	packageSourceReader source.PackageSourceReader
+
+	// entriesMutex guards the entries map, the mutex of each entry is held while its locators are resolved so a package
+	// checked by both rules at the same time is resolved once
+	entriesMutex *sync.Mutex
+	entries      map[string]*packageLocatorsCacheEntry
+}
+
+type packageLocatorsCacheEntry struct {
+	mutex           *sync.Mutex
+	packageLocators *packageLocators
+}
+
+func newPackageLocatorsCache(packageSourceReader source.PackageSourceReader) *packageLocatorsCache {
+	return &packageLocatorsCache{
+		packageSourceReader: packageSourceReader,
+		entriesMutex:        &sync.Mutex{},
+		entries:             map[string]*packageLocatorsCacheEntry{},
+	}
+}
+
+// getPackageLocators returns the resolved locators of the package, resolving them if they aren't in the cache yet. The
+// failures are copies, so the caller can set their positions and severities
+func (cache *packageLocatorsCache) getPackageLocators(ctx context.Context, packageCatalog catalog.PackageCatalog, packageData PackageData) *packageLocators {
+	entry := cache.getEntry(packageData)
+	entry.mutex.Lock()
+	defer entry.mutex.Unlock()
+	if entry.packageLocators == nil {
+		entry.packageLocators = resolvePackageLocators(ctx, cache.packageSourceReader, packageCatalog, packageData)
+	}
+
+	failures := []*Failure{}
+	for _, failure := range entry.packageLocators.failures {
+		failureCopy := *failure
+		failures = append(failures, &failureCopy)
+	}
+	return &packageLocators{failures: failures, dependencies: entry.packageLocators.dependencies}
+}
+
+func (cache *packageLocatorsCache) getEntry(packageData PackageData) *packageLocatorsCacheEntry {
+	// the package names are compared ignoring the case, like GitHub does with the repository owners and names
+	key := strings.ToLower(string(packageData.GetPackageName())) + packageLocatorsCacheKeyRefSeparator + packageData.GetRef()
+
+	cache.entriesMutex.Lock()
+	defer cache.entriesMutex.Unlock()
+	entry, found := cache.entries[key]
+	if !found {
+		entry = &packageLocatorsCacheEntry{mutex: &sync.Mutex{}, packageLocators: nil}
+		cache.entries[key] = entry
+	}
+	return entry
+}
@@ -0,0 +1,61 @@
+package rules
+
+import (
+	"context"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+)
+
+// PackageData is the information of a package in the catalog
+type PackageData interface {
+	GetPackageName() types.PackageName
+	GetRepositoryOwner() string
+	GetRepositoryName() string
+	GetRepositoryPackageRootPath() string
+	// GetRef and GetRefSHA return the ref the package is read at and the commit SHA recorded for it, they are empty
+	// if the catalog entry doesn't set them
+	GetRef() string
+	GetRefSHA() string
+	// GetCatalogLine and GetCatalogColumn return the position of the package entry in the catalog file
+	GetCatalogLine() int
+	GetCatalogColumn() int
+}
+
+// PackageRule is a Rule that checks each package independently of the rest of the catalog,
+// which allows the validator to check several packages at the same time
+type PackageRule interface {
+	Rule
+	// CheckPackage returns the package failures, an empty list means the package passed the rule
+	CheckPackage(ctx context.Context, packageData PackageData) []*Failure
+}
+
+// CheckPackageInCatalog checks the package rule for the package, the failures that aren't already located in the
+// catalog file are located at the package entry
+func CheckPackageInCatalog(ctx context.Context, packageRule PackageRule, packageData PackageData) []*Failure {
+	packageFailures := packageRule.CheckPackage(ctx, packageData)
+	for _, failure := range packageFailures {
+		if !failure.HasCatalogPosition() {
+			failure.withCatalogPosition(packageData.GetCatalogLine(), packageData.GetCatalogColumn())
+		}
+	}
+	return packageFailures
+}
+
+// checkEachPackage checks the package rule over all the catalog packages, one after the other
+func checkEachPackage(ctx context.Context, packageRule PackageRule, catalog catalog.PackageCatalog) *CheckResult {
+	failures := map[types.PackageName][]*Failure{}
+	checkedPackageNames := map[types.PackageName]bool{}
+
+	for _, packageData := range catalog {
+		// only the first entry of a duplicated package is checked, even if it has no failures
+		if checkedPackageNames[packageData.GetPackageName()] {
+			continue
+		}
+		checkedPackageNames[packageData.GetPackageName()] = true
+		if packageFailures := CheckPackageInCatalog(ctx, packageRule, packageData); len(packageFailures) > 0 {
+			failures[packageData.GetPackageName()] = packageFailures
+		}
+	}
+
+	return NewCheckResultFromFailures(packageRule.GetName(), failures)
+}
@@ -0,0 +1,37 @@
+package rules
+
+import (
+	"context"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const (
+	testRepositoryOwner = "foo"
+	testRepositoryName  = "bar"
+	testPackageName     = "github.com/foo/bar"
+)
+
+// checkTestPackage checks the package rule for the package of the catalog file content, which has to contain only one
+// package
+func checkTestPackage(t *testing.T, packageRule PackageRule, catalogYaml string) []*Failure {
+	packageCatalog, err := catalog.GetPackageCatalogFromYamlFileContent([]byte(catalogYaml))
+	require.NoError(t, err)
+	require.Len(t, packageCatalog, 1)
+	return packageRule.CheckPackage(context.Background(), packageCatalog[0])
+}
+
+// getTestCatalogYaml returns the content of a catalog file with only the package
+func getTestCatalogYaml(packageName string) string {
+	return "packages:\n  - name: \"" + packageName + "\"\n"
+}
+
+// getFailureCodes returns the codes of the failures, in the same order
+func getFailureCodes(failures []*Failure) []FailureCode {
+	failureCodes := []FailureCode{}
+	for _, failure := range failures {
+		failureCodes = append(failureCodes, failure.GetCode())
+	}
+	return failureCodes
+}
@@ -0,0 +1,231 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/imagereference"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/source"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/starlarkfile"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/consts"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"path"
+	"strings"
+)
+
+const (
+	pinnedContainerImagesRuleName = "Pinned container images"
+
+	serviceConfigFunctionName = "ServiceConfig"
+	runShFunctionName         = "run_sh"
+	runPythonFunctionName     = "run_python"
+
+	// keywordOnlyArgumentIdx is the argument position of the image arguments that can only be passed by keyword
+	keywordOnlyArgumentIdx = -1
+
+	imageReferenceInvalidFailureCode    FailureCode = "IMAGE_REFERENCE_INVALID"
+	imageNotPinnedFailureCode           FailureCode = "IMAGE_NOT_PINNED"
+	imageRegistryNotAllowedFailureCode  FailureCode = "IMAGE_REGISTRY_NOT_ALLOWED"
+	starlarkFilesNotReadableFailureCode FailureCode = "STARLARK_FILES_NOT_READABLE"
+)
+
+var (
+	// imageFunctions are the functions whose image argument is checked, with the position and the keyword of the argument
+	imageFunctions = []*functionArgument{
+		{name: serviceConfigFunctionName, argumentIdx: 0, argumentName: "image"},
+		{name: runShFunctionName, argumentIdx: keywordOnlyArgumentIdx, argumentName: "image"},
+		{name: runPythonFunctionName, argumentIdx: keywordOnlyArgumentIdx, argumentName: "image"},
+	}
+)
+
+// pinnedContainerImagesRuleParameters are the parameters of the rule that can be set in the rules config
+type pinnedContainerImagesRuleParameters struct {
+	AllowedRegistries []string `yaml:"allowed-registries"`
+}
+
+// pinnedContainerImagesRule checks the container images the package runs, by finding the string literals passed as the
+// image of ServiceConfig, run_sh and run_python in every .star file of the package and checking if:
+// 1- the image is a valid OCI image reference, otherwise Kurtosis fails to pull it
+// 2- the image is pinned to a tag other than latest or to a digest, it's a warning because it still runs but it can
+// change on every push, so the package isn't reproducible
+// 3- the image registry is one of the allowed registries, when they are set. It's checked offline, without pulling it
+// The images built at runtime can't be known without running the package, so they are skipped
+type pinnedContainerImagesRule struct {
+	name                string
+	packageSourceReader source.PackageSourceReader
+
+	// allowedRegistries are the registries the images can be pulled from, any registry is allowed if it's empty
+	allowedRegistries []string
+}
+
+func newPinnedContainerImagesRule(packageSourceReader source.PackageSourceReader, allowedRegistries []string) *pinnedContainerImagesRule {
+	return &pinnedContainerImagesRule{name: pinnedContainerImagesRuleName, packageSourceReader: packageSourceReader, allowedRegistries: allowedRegistries}
+}
+
+// newPinnedContainerImagesRuleFromConfig returns the rule with the allowed registries set in the rules config, there
+// aren't any by default
+func newPinnedContainerImagesRuleFromConfig(packageSourceReader source.PackageSourceReader, rulesConfig *RulesConfig) (*pinnedContainerImagesRule, error) {
+	parameters := &pinnedContainerImagesRuleParameters{AllowedRegistries: nil}
+	if err := rulesConfig.decodeRuleParameters(pinnedContainerImagesRuleName, parameters); err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred getting the parameters of rule '%s'", pinnedContainerImagesRuleName)
+	}
+	for _, allowedRegistry := range parameters.AllowedRegistries {
+		if allowedRegistry == "" || strings.Contains(allowedRegistry, "/") {
+			return nil, stacktrace.NewError("expected the 'allowed-registries' parameter of rule '%s' to contain registry hostnames like '%s', but it contains '%s'", pinnedContainerImagesRuleName, imagereference.DefaultRegistry, allowedRegistry)
+		}
+	}
+	return newPinnedContainerImagesRule(packageSourceReader, parameters.AllowedRegistries), nil
+}
+
+func (pinnedContainerImagesRule *pinnedContainerImagesRule) GetName() RuleName {
+	return RuleName(pinnedContainerImagesRule.name)
+}
+
+func (pinnedContainerImagesRule *pinnedContainerImagesRule) GetDescription() string {
+	return "checks that the container images used in the .star files of the package are valid OCI references, pinned to a tag other than latest or to a digest, and from an allowed registry"
+}
+
+func (pinnedContainerImagesRule *pinnedContainerImagesRule) GetParameters() map[string]interface{} {
+	return map[string]interface{}{
+		"allowed-registries": pinnedContainerImagesRule.allowedRegistries,
+	}
+}
+
+func (pinnedContainerImagesRule *pinnedContainerImagesRule) GetDefaultSeverity() Severity {
+	return SeverityError
+}
+
+func (pinnedContainerImagesRule *pinnedContainerImagesRule) Check(ctx context.Context, catalog catalog.PackageCatalog) *CheckResult {
+	return checkEachPackage(ctx, pinnedContainerImagesRule, catalog)
+}
+
+func (pinnedContainerImagesRule *pinnedContainerImagesRule) CheckPackage(ctx context.Context, packageData PackageData) []*Failure {
+	packageName := packageData.GetPackageName()
+	logrus.Debugf("Checking if the container images of package '%s' are pinned...", packageName)
+	repository := source.NewPackageRepositoryFromPackageData(packageData)
+	packageRootPath := path.Join(repositoryRootPath, packageData.GetRepositoryPackageRootPath())
+
+	starlarkFilepaths, err := pinnedContainerImagesRule.findStarlarkFiles(ctx, repository, packageRootPath)
+	if err != nil {
+		return []*Failure{newFailure(
+			starlarkFilesNotReadableFailureCode,
+			packageRootPath,
+			fmt.Sprintf("the .star files of the package could not be listed. Error was:\n%s", err.Error()),
+			"",
+		)}
+	}
+
+	failures := []*Failure{}
+	for _, starlarkFilepath := range starlarkFilepaths {
+		starlarkFileContent, err := pinnedContainerImagesRule.packageSourceReader.ReadFile(ctx, repository, starlarkFilepath)
+		if err != nil {
+			failures = append(failures, newFailure(starlarkFilesNotReadableFailureCode, starlarkFilepath, fmt.Sprintf("the file could not be read. Error was:\n%s", err.Error()), ""))
+			continue
+		}
+		starlarkFile, syntaxErr := starlarkfile.ParseFile(starlarkFilepath, starlarkFileContent)
+		if syntaxErr != nil {
+			logrus.Debugf("Skipping the container images of file '%s' of package '%s' because it isn't valid Starlark", starlarkFilepath, packageName)
+			continue
+		}
+		for _, function := range imageFunctions {
+			for _, imageArgument := range starlarkFile.FindStringArguments(function.name, function.argumentIdx, function.argumentName) {
+				if failure := pinnedContainerImagesRule.checkImage(starlarkFilepath, imageArgument); failure != nil {
+					failures = append(failures, failure.withPosition(imageArgument.GetLine(), imageArgument.GetColumn()))
+				}
+			}
+		}
+	}
+
+	if len(failures) == 0 {
+		logrus.Debugf("...container images of package '%s' successfully validated.", packageName)
+	}
+	return failures
+}
+
+// checkImage returns a failure if the image passed to the function isn't valid, pinned or from an allowed registry
+func (pinnedContainerImagesRule *pinnedContainerImagesRule) checkImage(starlarkFilepath string, imageArgument *starlarkfile.StringArgument) *Failure {
+	imageReferenceStr := imageArgument.GetValue()
+	imageReference, err := imagereference.ParseImageReference(imageReferenceStr)
+	if err != nil {
+		return newFailure(
+			imageReferenceInvalidFailureCode,
+			starlarkFilepath,
+			fmt.Sprintf("the image '%s' passed to '%s' is not a valid image reference. Error was:\n%s", imageReferenceStr, imageArgument.GetFunctionName(), err.Error()),
+			"use a reference like 'registry/repository:tag', the repository can only contain lowercase letters",
+		)
+	}
+
+	if !pinnedContainerImagesRule.isRegistryAllowed(imageReference.GetRegistry()) {
+		return newFailure(
+			imageRegistryNotAllowedFailureCode,
+			starlarkFilepath,
+			fmt.Sprintf("the registry '%s' of the image '%s' is not one of the allowed registries: %s", imageReference.GetRegistry(), imageReferenceStr, strings.Join(pinnedContainerImagesRule.allowedRegistries, ", ")),
+			"push the image to an allowed registry, or ask the catalog maintainers to allow the registry",
+		)
+	}
+
+	if imageReference.GetDigest() != "" || (imageReference.GetTag() != "" && imageReference.GetTag() != imagereference.LatestTag) {
+		return nil
+	}
+	failureMsg := fmt.Sprintf("the image '%s' passed to '%s' isn't pinned, it doesn't set a tag so the '%s' tag is pulled", imageReferenceStr, imageArgument.GetFunctionName(), imagereference.LatestTag)
+	if imageReference.GetTag() == imagereference.LatestTag {
+		failureMsg = fmt.Sprintf("the image '%s' passed to '%s' isn't pinned, the '%s' tag points to a different image on every push", imageReferenceStr, imageArgument.GetFunctionName(), imagereference.LatestTag)
+	}
+	return newFailure(
+		imageNotPinnedFailureCode,
+		starlarkFilepath,
+		failureMsg,
+		"pin the image to a version tag like 'postgres:16.2', or to a digest like 'postgres@sha256:...', so the package runs the same image every time",
+	).withSeverity(SeverityWarning)
+}
+
+// isRegistryAllowed returns true if there aren't allowed registries or the registry is one of them, compared without case
+func (pinnedContainerImagesRule *pinnedContainerImagesRule) isRegistryAllowed(registry string) bool {
+	if len(pinnedContainerImagesRule.allowedRegistries) == 0 {
+		return true
+	}
+	for _, allowedRegistry := range pinnedContainerImagesRule.allowedRegistries {
+		if strings.EqualFold(allowedRegistry, registry) {
+			return true
+		}
+	}
+	return false
+}
+
+// findStarlarkFiles returns the paths of the .star files in the package directory and its subdirectories, the
+// subdirectories with their own kurtosis.yml file are other packages so they are skipped
+func (pinnedContainerImagesRule *pinnedContainerImagesRule) findStarlarkFiles(ctx context.Context, repository *source.PackageRepository, packageRootPath string) ([]string, error) {
+	starlarkFilepaths := []string{}
+	pendingDirpaths := []string{packageRootPath}
+	for len(pendingDirpaths) > 0 {
+		dirpath := pendingDirpaths[0]
+		pendingDirpaths = pendingDirpaths[1:]
+
+		fileInfos, err := pinnedContainerImagesRule.packageSourceReader.ListDirectory(ctx, repository, dirpath)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "an error occurred listing directory '%s' of repository '%s'", dirpath, repository)
+		}
+		if dirpath != packageRootPath && containsKurtosisYaml(fileInfos) {
+			continue
+		}
+		for _, fileInfo := range fileInfos {
+			fileInfoPath := path.Join(repositoryRootPath, fileInfo.GetPath())
+			if fileInfo.IsDirectory() {
+				pendingDirpaths = append(pendingDirpaths, fileInfoPath)
+			} else if path.Ext(fileInfoPath) == starlarkFileExtension {
+				starlarkFilepaths = append(starlarkFilepaths, fileInfoPath)
+			}
+		}
+	}
+	return starlarkFilepaths, nil
+}
+
+func containsKurtosisYaml(fileInfos []*source.FileInfo) bool {
+	for _, fileInfo := range fileInfos {
+		if !fileInfo.IsDirectory() && path.Base(fileInfo.GetPath()) == consts.DefaultKurtosisYamlFilename {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+const (
+	removedPackageRuleName = "Removed package"
+
+	// deprecatedSinceEntryField is the catalog entry field with the date, as 'YYYY-MM-DD', since the package is deprecated
+	deprecatedSinceEntryField = "deprecated-since"
+	deprecatedSinceDateLayout = "2006-01-02"
+
+	// defaultMinDeprecationPeriodDays is how long a package has to be deprecated before anyone can remove it from the catalog
+	defaultMinDeprecationPeriodDays = 30
+	dayDuration                     = 24 * time.Hour
+
+	packageRemovalNotAllowedFailureCode FailureCode = "PACKAGE_REMOVAL_NOT_ALLOWED"
+	invalidDeprecationDateFailureCode   FailureCode = "DEPRECATION_DATE_INVALID"
+)
+
+// removedPackageRuleParameters are the parameters of the rule that can be set in the rules config
+type removedPackageRuleParameters struct {
+	MinDeprecationPeriodDays int `yaml:"min-deprecation-period-days"`
+}
+
+// removedPackageRule checks that the packages removed or renamed in the catalog can be removed, which happens if:
+// 1- the catalog change author is the owner of the package repository
+// 2- or the package was deprecated in the base catalog at least minDeprecationPeriodDays ago
+type removedPackageRule struct {
+	name string
+
+	// catalogChangeAuthor is the GitHub user proposing the catalog change, it's empty if it's unknown
+	catalogChangeAuthor string
+
+	minDeprecationPeriodDays int
+}
+
+func newRemovedPackageRule(catalogChangeAuthor string, minDeprecationPeriodDays int) *removedPackageRule {
+	return &removedPackageRule{name: removedPackageRuleName, catalogChangeAuthor: catalogChangeAuthor, minDeprecationPeriodDays: minDeprecationPeriodDays}
+}
+
+// newRemovedPackageRuleFromConfig returns the rule with the deprecation period set in the rules config, or the default one
+func newRemovedPackageRuleFromConfig(catalogChangeAuthor string, rulesConfig *RulesConfig) (*removedPackageRule, error) {
+	parameters := &removedPackageRuleParameters{MinDeprecationPeriodDays: defaultMinDeprecationPeriodDays}
+	if err := rulesConfig.decodeRuleParameters(removedPackageRuleName, parameters); err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred getting the parameters of rule '%s'", removedPackageRuleName)
+	}
+	if parameters.MinDeprecationPeriodDays < 0 {
+		return nil, stacktrace.NewError("expected the 'min-deprecation-period-days' parameter of rule '%s' to not be negative, but it's '%d'", removedPackageRuleName, parameters.MinDeprecationPeriodDays)
+	}
+	return newRemovedPackageRule(catalogChangeAuthor, parameters.MinDeprecationPeriodDays), nil
+}
+
+func (removedPackageRule *removedPackageRule) GetName() RuleName {
+	return RuleName(removedPackageRule.name)
+}
+
+func (removedPackageRule *removedPackageRule) GetDescription() string {
+	return "checks that the packages removed or renamed in the catalog are removed by their repository owner or were deprecated long enough ago"
+}
+
+func (removedPackageRule *removedPackageRule) GetParameters() map[string]interface{} {
+	return map[string]interface{}{
+		"min-deprecation-period-days": removedPackageRule.minDeprecationPeriodDays,
+	}
+}
+
+func (removedPackageRule *removedPackageRule) GetDefaultSeverity() Severity {
+	return SeverityError
+}
+
+func (removedPackageRule *removedPackageRule) Check(_ context.Context, _ catalog.PackageCatalog) *CheckResult {
+	return checkNothingWithoutDiff(removedPackageRule)
+}
+
+func (removedPackageRule *removedPackageRule) CheckDiff(_ context.Context, catalogDiff *catalog.CatalogDiff) *CheckResult {
+	failures := map[types.PackageName][]*Failure{}
+
+	removedPackages := catalogDiff.GetRemovedPackages()
+	for _, renamedPackage := range catalogDiff.GetRenamedPackages() {
+		removedPackages = append(removedPackages, renamedPackage.GetBasePackage())
+	}
+
+	for _, removedPackage := range removedPackages {
+		packageName := removedPackage.GetPackageName()
+		logrus.Debugf("Checking if package '%s' can be removed from the catalog...", packageName)
+		if failure := removedPackageRule.checkRemovedPackage(removedPackage); failure != nil {
+			failures[packageName] = []*Failure{failure}
+			continue
+		}
+		logrus.Debugf("...package '%s' can be removed.", packageName)
+	}
+
+	return NewCheckResultFromFailures(removedPackageRule.GetName(), failures)
+}
+
+// checkRemovedPackage returns the failure of the removed package, or nil if it can be removed
+func (removedPackageRule *removedPackageRule) checkRemovedPackage(removedPackage *catalog.CatalogPackage) *Failure {
+	repositoryOwner := removedPackage.GetRepositoryOwner()
+	if removedPackageRule.catalogChangeAuthor != "" && strings.EqualFold(removedPackageRule.catalogChangeAuthor, repositoryOwner) {
+		return nil
+	}
+
+	deprecatedSinceStr, isDeprecated := removedPackage.GetEntryField(deprecatedSinceEntryField)
+	if isDeprecated {
+		deprecatedSince, err := time.Parse(deprecatedSinceDateLayout, deprecatedSinceStr)
+		if err != nil {
+			return newFailure(
+				invalidDeprecationDateFailureCode,
+				"",
+				fmt.Sprintf("the '%s' date '%s' of the package in the base catalog is not valid", deprecatedSinceEntryField, deprecatedSinceStr),
+				fmt.Sprintf("set '%s' as 'YYYY-MM-DD' in a previous change before removing the package", deprecatedSinceEntryField),
+			)
+		}
+		if time.Since(deprecatedSince) >= time.Duration(removedPackageRule.minDeprecationPeriodDays)*dayDuration {
+			return nil
+		}
+	}
+
+	removalNotAllowedMsg := fmt.Sprintf("the package was removed but the change author isn't the owner of its repository '%s', and it wasn't deprecated for at least %d days", repositoryOwner, removedPackageRule.minDeprecationPeriodDays)
+	if removedPackageRule.catalogChangeAuthor == "" {
+		removalNotAllowedMsg = fmt.Sprintf("the package was removed but the change author is unknown so it can't be compared with the owner of its repository '%s', and it wasn't deprecated for at least %d days", repositoryOwner, removedPackageRule.minDeprecationPeriodDays)
+	}
+	return newFailure(
+		packageRemovalNotAllowedFailureCode,
+		"",
+		removalNotAllowedMsg,
+		fmt.Sprintf("ask '%s' to remove the package, or set '%s: YYYY-MM-DD' in its catalog entry and remove it %d days later", repositoryOwner, deprecatedSinceEntryField, removedPackageRule.minDeprecationPeriodDays),
+	)
+}
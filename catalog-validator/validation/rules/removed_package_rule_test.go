@@ -0,0 +1,114 @@
+package rules
+
+import (
+	"context"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+const (
+	removedPackageTestMinDeprecationPeriodDays = 30
+)
+
+func TestRemovedPackageRule_CheckDiff(t *testing.T) {
+	longDeprecatedSince := time.Now().AddDate(0, 0, -removedPackageTestMinDeprecationPeriodDays-1).Format(deprecatedSinceDateLayout)
+	recentlyDeprecatedSince := time.Now().AddDate(0, 0, -removedPackageTestMinDeprecationPeriodDays+1).Format(deprecatedSinceDateLayout)
+
+	testCases := []struct {
+		name                 string
+		catalogChangeAuthor  string
+		baseCatalogYaml      string
+		newCatalogYaml       string
+		expectedFailureCodes []FailureCode
+	}{
+		{
+			name:                 "removed by the repository owner",
+			catalogChangeAuthor:  "foo",
+			baseCatalogYaml:      "packages:\n  - name: github.com/foo/bar\n",
+			newCatalogYaml:       "packages: []\n",
+			expectedFailureCodes: []FailureCode{},
+		},
+		{
+			name:                 "owner compared without case",
+			catalogChangeAuthor:  "FOO",
+			baseCatalogYaml:      "packages:\n  - name: github.com/Foo/bar\n",
+			newCatalogYaml:       "packages: []\n",
+			expectedFailureCodes: []FailureCode{},
+		},
+		{
+			name:                 "removed by another user",
+			catalogChangeAuthor:  "other",
+			baseCatalogYaml:      "packages:\n  - name: github.com/foo/bar\n",
+			newCatalogYaml:       "packages: []\n",
+			expectedFailureCodes: []FailureCode{packageRemovalNotAllowedFailureCode},
+		},
+		{
+			name:                 "removed by an unknown author",
+			catalogChangeAuthor:  "",
+			baseCatalogYaml:      "packages:\n  - name: github.com/foo/bar\n",
+			newCatalogYaml:       "packages: []\n",
+			expectedFailureCodes: []FailureCode{packageRemovalNotAllowedFailureCode},
+		},
+		{
+			name:                 "deprecated long enough ago",
+			catalogChangeAuthor:  "",
+			baseCatalogYaml:      "packages:\n  - name: github.com/foo/bar\n    deprecated-since: \"" + longDeprecatedSince + "\"\n",
+			newCatalogYaml:       "packages: []\n",
+			expectedFailureCodes: []FailureCode{},
+		},
+		{
+			name:                 "deprecated too recently",
+			catalogChangeAuthor:  "other",
+			baseCatalogYaml:      "packages:\n  - name: github.com/foo/bar\n    deprecated-since: \"" + recentlyDeprecatedSince + "\"\n",
+			newCatalogYaml:       "packages: []\n",
+			expectedFailureCodes: []FailureCode{packageRemovalNotAllowedFailureCode},
+		},
+		{
+			name:                 "invalid deprecation date",
+			catalogChangeAuthor:  "other",
+			baseCatalogYaml:      "packages:\n  - name: github.com/foo/bar\n    deprecated-since: \"31/01/2024\"\n",
+			newCatalogYaml:       "packages: []\n",
+			expectedFailureCodes: []FailureCode{invalidDeprecationDateFailureCode},
+		},
+		{
+			name:                 "invalid deprecation date removed by the repository owner",
+			catalogChangeAuthor:  "foo",
+			baseCatalogYaml:      "packages:\n  - name: github.com/foo/bar\n    deprecated-since: \"31/01/2024\"\n",
+			newCatalogYaml:       "packages: []\n",
+			expectedFailureCodes: []FailureCode{},
+		},
+		{
+			name:                 "renamed by another user",
+			catalogChangeAuthor:  "other",
+			baseCatalogYaml:      "packages:\n  - name: github.com/foo/bar\n",
+			newCatalogYaml:       "packages:\n  - name: github.com/foo/bar/app\n",
+			expectedFailureCodes: []FailureCode{packageRemovalNotAllowedFailureCode},
+		},
+		{
+			name:                 "added and changed packages",
+			catalogChangeAuthor:  "other",
+			baseCatalogYaml:      "packages:\n  - name: github.com/foo/bar\n",
+			newCatalogYaml:       "packages:\n  - name: github.com/foo/bar\n    ref: v1\n  - name: github.com/other/baz\n",
+			expectedFailureCodes: []FailureCode{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			baseCatalog, err := catalog.GetPackageCatalogFromYamlFileContent([]byte(testCase.baseCatalogYaml))
+			require.NoError(t, err)
+			newCatalog, err := catalog.GetPackageCatalogFromYamlFileContent([]byte(testCase.newCatalogYaml))
+			require.NoError(t, err)
+
+			removedPackageRule := newRemovedPackageRule(testCase.catalogChangeAuthor, removedPackageTestMinDeprecationPeriodDays)
+			checkResult := removedPackageRule.CheckDiff(context.Background(), catalog.NewCatalogDiff(baseCatalog, newCatalog))
+			failureCodes := []FailureCode{}
+			for _, packageFailures := range checkResult.GetFailures() {
+				failureCodes = append(failureCodes, getFailureCodes(packageFailures)...)
+			}
+			require.Equal(t, testCase.expectedFailureCodes, failureCodes)
+		})
+	}
+}
@@ -0,0 +1,423 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/locator"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/source"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/starlarkfile"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/consts"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	resolvableLocatorsRuleName = "Resolvable locators"
+
+	importModuleFunctionName = "import_module"
+	readFileFunctionName     = "read_file"
+	uploadFilesFunctionName  = "upload_files"
+
+	starlarkFileExtension = ".star"
+
+	// absoluteLocatorPrefix is the prefix of the locators pointing to a package by its repository, the other locators
+	// are relative to the file using them, or to the package root if they start with '/'
+	absoluteLocatorPrefix = "github.com/"
+
+	parentDirPath = ".."
+
+	locatorInvalidFailureCode          FailureCode = "LOCATOR_INVALID"
+	locatorFileNotFoundFailureCode     FailureCode = "LOCATOR_FILE_NOT_FOUND"
+	locatorFileReadFailedFailureCode   FailureCode = "LOCATOR_FILE_READ_FAILED"
+	locatorPackageNotFoundFailureCode  FailureCode = "LOCATOR_PACKAGE_NOT_FOUND"
+	starlarkFileSyntaxErrorFailureCode FailureCode = "STARLARK_FILE_SYNTAX_ERROR"
+)
+
+// functionArgument is an argument of a Starlark function, with its position and its keyword
+type functionArgument struct {
+	name         string
+	argumentIdx  int
+	argumentName string
+}
+
+var (
+	// locatorFunctions are the functions whose locators are resolved, only the modules imported with import_module
+	// are walked to find more locators
+	locatorFunctions = []*functionArgument{
+		{name: importModuleFunctionName, argumentIdx: 0, argumentName: "module_file"},
+		{name: readFileFunctionName, argumentIdx: 0, argumentName: "src"},
+		{name: uploadFilesFunctionName, argumentIdx: 0, argumentName: "src"},
+	}
+)
+
+// resolvableLocatorsRule checks if the locators used by the package resolve, by walking every .star file imported from
+// the main.star file and checking if each locator passed to import_module, read_file and upload_files either:
+// 1- points to a file or directory that exists in the same package
+// 2- points to another package, which has a kurtosis.yml file
+// The replace directives of the package kurtosis.yml file are applied before resolving the locators, the missing or
+// invalid main.star file is reported by the Runnable package rule. The other repositories are read at the ref of their
+// package in the full catalog, or at their default branch if they aren't in it
+type resolvableLocatorsRule struct {
+	name                 string
+	packageLocatorsCache *packageLocatorsCache
+
+	// fullCatalog is the catalog with all the packages, the checked catalog is used if it's nil
+	fullCatalog catalog.PackageCatalog
+}
+
+func newResolvableLocatorsRule(packageLocatorsCache *packageLocatorsCache, fullCatalog catalog.PackageCatalog) *resolvableLocatorsRule {
+	return &resolvableLocatorsRule{name: resolvableLocatorsRuleName, packageLocatorsCache: packageLocatorsCache, fullCatalog: fullCatalog}
+}
+
+func (resolvableLocatorsRule *resolvableLocatorsRule) GetName() RuleName {
+	return RuleName(resolvableLocatorsRule.name)
+}
+
+func (resolvableLocatorsRule *resolvableLocatorsRule) GetDescription() string {
+	return "checks that the locators passed to import_module, read_file and upload_files in the .star files reachable from main.star resolve to a file of the package or to another package, honoring the kurtosis.yml replace directives"
+}
+
+func (resolvableLocatorsRule *resolvableLocatorsRule) GetParameters() map[string]interface{} {
+	return map[string]interface{}{}
+}
+
+func (resolvableLocatorsRule *resolvableLocatorsRule) GetDefaultSeverity() Severity {
+	return SeverityError
+}
+
+func (resolvableLocatorsRule *resolvableLocatorsRule) Check(ctx context.Context, catalogToCheck catalog.PackageCatalog) *CheckResult {
+	if resolvableLocatorsRule.fullCatalog == nil {
+		return checkEachPackage(ctx, newResolvableLocatorsRule(resolvableLocatorsRule.packageLocatorsCache, catalogToCheck), catalogToCheck)
+	}
+	return checkEachPackage(ctx, resolvableLocatorsRule, catalogToCheck)
+}
+
+func (resolvableLocatorsRule *resolvableLocatorsRule) CheckPackage(ctx context.Context, packageData PackageData) []*Failure {
+	packageName := packageData.GetPackageName()
+	logrus.Debugf("Checking if the locators of package '%s' resolve...", packageName)
+	failures := resolvableLocatorsRule.packageLocatorsCache.getPackageLocators(ctx, resolvableLocatorsRule.fullCatalog, packageData).failures
+	if len(failures) == 0 {
+		logrus.Debugf("...locators of package '%s' successfully resolved.", packageName)
+	}
+	return failures
+}
+
+// packageLocators is the result of resolving the locators used by a package
+type packageLocators struct {
+	failures []*Failure
+
+	// dependencies are the names of the other packages the locators point to, sorted and without duplicates
+	dependencies []types.PackageName
+}
+
+// resolvePackageLocators resolves the locators used by the .star files reachable from the package main.star file, it
+// returns the failures of the locators that don't resolve and the packages the other locators point to. The other
+// repositories are read at the ref of their package in the catalog, which can be nil to read all of them at their
+// default branch
+func resolvePackageLocators(ctx context.Context, packageSourceReader source.PackageSourceReader, packageCatalog catalog.PackageCatalog, packageData PackageData) *packageLocators {
+	packageName := packageData.GetPackageName()
+	repository := source.NewPackageRepositoryFromPackageData(packageData)
+	packageRootPath := path.Join(repositoryRootPath, packageData.GetRepositoryPackageRootPath())
+	mainStarFilepath := path.Join(packageRootPath, consts.StarlarkMainDotStarFileName)
+
+	resolver := newLocatorResolver(packageSourceReader, packageCatalog, repository, packageRootPath)
+	resolver.readReplaceDirectives(ctx)
+
+	failures := []*Failure{}
+	dependencies := map[types.PackageName]bool{}
+	visitedFilepaths := map[string]bool{mainStarFilepath: true}
+	pendingFilepaths := []string{mainStarFilepath}
+	for len(pendingFilepaths) > 0 {
+		starlarkFilepath := pendingFilepaths[0]
+		pendingFilepaths = pendingFilepaths[1:]
+
+		starlarkFileContent, err := packageSourceReader.ReadFile(ctx, repository, starlarkFilepath)
+		if err != nil {
+			if starlarkFilepath == mainStarFilepath {
+				logrus.Debugf("Skipping the locators of package '%s' because its main.star file can't be read, the '%s' rule reports it", packageName, runnablePackageRuleName)
+			} else if source.IsFileNotFoundErr(err) {
+				failures = append(failures, newFailure(locatorFileNotFoundFailureCode, starlarkFilepath, "the imported file doesn't exist in the package", ""))
+			} else {
+				failures = append(failures, newFailure(locatorFileReadFailedFailureCode, starlarkFilepath, fmt.Sprintf("the imported file could not be read. Error was:\n%s", err.Error()), ""))
+			}
+			continue
+		}
+		starlarkFile, syntaxErr := starlarkfile.ParseFile(starlarkFilepath, starlarkFileContent)
+		if syntaxErr != nil {
+			if starlarkFilepath == mainStarFilepath {
+				logrus.Debugf("Skipping the locators of package '%s' because its main.star file isn't valid, the '%s' rule reports it", packageName, runnablePackageRuleName)
+			} else {
+				failures = append(failures, newFailure(
+					starlarkFileSyntaxErrorFailureCode,
+					starlarkFilepath,
+					fmt.Sprintf("the imported file is not valid Starlark: %s", syntaxErr.GetMessage()),
+					"fix the syntax error, running the package locally with 'kurtosis run' shows the same error",
+				).withPosition(syntaxErr.GetLine(), syntaxErr.GetColumn()))
+			}
+			continue
+		}
+
+		for _, function := range locatorFunctions {
+			for _, locatorArgument := range starlarkFile.FindStringArguments(function.name, function.argumentIdx, function.argumentName) {
+				resolvedFilepath, dependency, failure := resolver.resolve(ctx, starlarkFilepath, locatorArgument)
+				if failure != nil {
+					failures = append(failures, failure.withPosition(locatorArgument.GetLine(), locatorArgument.GetColumn()))
+					continue
+				}
+				if dependency != "" {
+					dependencies[dependency] = true
+					continue
+				}
+				isImportedStarlarkFile := function.name == importModuleFunctionName && path.Ext(resolvedFilepath) == starlarkFileExtension
+				if isImportedStarlarkFile && !visitedFilepaths[resolvedFilepath] {
+					visitedFilepaths[resolvedFilepath] = true
+					pendingFilepaths = append(pendingFilepaths, resolvedFilepath)
+				}
+			}
+		}
+	}
+
+	sortedDependencies := []types.PackageName{}
+	for dependency := range dependencies {
+		sortedDependencies = append(sortedDependencies, dependency)
+	}
+	sort.Slice(sortedDependencies, func(i, j int) bool {
+		return sortedDependencies[i] < sortedDependencies[j]
+	})
+	return &packageLocators{failures: failures, dependencies: sortedDependencies}
+}
+
+// replaceDirective replaces the packages starting with the replaced locator by the replacement, which is either another
+// locator or a path relative to the package root, like '../other-package'
+type replaceDirective struct {
+	replacedLocator string
+	replacement     string
+}
+
+// locatorResolver resolves the locators used by the files of a package, the paths are absolute paths in the package
+// repository, like '/path/to/package/main.star'
+type locatorResolver struct {
+	packageSourceReader source.PackageSourceReader
+
+	// packageCatalog has the refs the other repositories are read at, it can be nil
+	packageCatalog catalog.PackageCatalog
+
+	repository      *source.PackageRepository
+	packageRootPath string
+
+	// replaceDirectives are sorted from the longest replaced locator to the shortest, so the most specific one wins
+	replaceDirectives []*replaceDirective
+
+	// foundPackageRoots caches whether a kurtosis.yml file exists, indexed by repository with its ref and then by directory
+	foundPackageRoots map[string]map[string]bool
+}
+
+func newLocatorResolver(packageSourceReader source.PackageSourceReader, packageCatalog catalog.PackageCatalog, repository *source.PackageRepository, packageRootPath string) *locatorResolver {
+	return &locatorResolver{
+		packageSourceReader: packageSourceReader,
+		packageCatalog:      packageCatalog,
+		repository:          repository,
+		packageRootPath:     packageRootPath,
+		replaceDirectives:   []*replaceDirective{},
+		foundPackageRoots:   map[string]map[string]bool{},
+	}
+}
+
+// readReplaceDirectives reads the replace directives of the package kurtosis.yml file, the package keeps no replace
+// directives if the file is missing or invalid because the Valid package and Valid package manifest rules report it
+func (resolver *locatorResolver) readReplaceDirectives(ctx context.Context) {
+	kurtosisYamlFilepath := path.Join(resolver.packageRootPath, consts.DefaultKurtosisYamlFilename)
+	kurtosisYamlFileContent, err := resolver.packageSourceReader.ReadFile(ctx, resolver.repository, kurtosisYamlFilepath)
+	if err != nil {
+		return
+	}
+	kurtosisYaml := &struct {
+		Replace map[string]string `yaml:"replace"`
+	}{}
+	if err := yaml.Unmarshal(kurtosisYamlFileContent, kurtosisYaml); err != nil {
+		return
+	}
+	for replacedLocator, replacement := range kurtosisYaml.Replace {
+		resolver.replaceDirectives = append(resolver.replaceDirectives, &replaceDirective{replacedLocator: replacedLocator, replacement: replacement})
+	}
+	sort.Slice(resolver.replaceDirectives, func(i, j int) bool {
+		return len(resolver.replaceDirectives[i].replacedLocator) > len(resolver.replaceDirectives[j].replacedLocator)
+	})
+}
+
+// resolve returns the path of the file the locator points to if it's in the same package, or the name of the package
+// it points to if it's another package. A failure is returned if the locator doesn't resolve
+func (resolver *locatorResolver) resolve(ctx context.Context, currentFilepath string, locatorArgument *starlarkfile.StringArgument) (string, types.PackageName, *Failure) {
+	locatorStr := locatorArgument.GetValue()
+	functionName := locatorArgument.GetFunctionName()
+
+	targetRepository := resolver.repository
+	var targetPath string
+	if strings.HasPrefix(locatorStr, absoluteLocatorPrefix) {
+		replacedLocatorStr, isLocalReplacement := resolver.applyReplaceDirectives(locatorStr)
+		if isLocalReplacement {
+			targetPath = replacedLocatorStr
+		} else {
+			parsedLocator, err := locator.ParseLocator(replacedLocatorStr)
+			if err != nil {
+				return "", "", newFailure(
+					locatorInvalidFailureCode,
+					currentFilepath,
+					fmt.Sprintf("the locator '%s' passed to '%s' is invalid: %#s", replacedLocatorStr, functionName, err),
+					"use a locator like 'github.com/owner/repository/path/to/file'",
+				)
+			}
+			targetPath = path.Join(repositoryRootPath, parsedLocator.GetPath())
+			targetRepository = resolver.getTargetRepository(parsedLocator.GetOwner(), parsedLocator.GetRepositoryName(), targetPath)
+		}
+	} else if strings.HasPrefix(locatorStr, repositoryRootPath) {
+		targetPath = joinRepositoryPaths(resolver.packageRootPath, locatorStr)
+	} else {
+		targetPath = joinRepositoryPaths(path.Dir(currentFilepath), locatorStr)
+	}
+	if targetPath == "" {
+		return "", "", newFailure(
+			locatorInvalidFailureCode,
+			currentFilepath,
+			fmt.Sprintf("the locator '%s' passed to '%s' points outside of the repository", locatorStr, functionName),
+			"fix the path of the locator, relative locators are relative to the file using them and the ones starting with '/' to the package root",
+		)
+	}
+
+	isSameRepository := strings.EqualFold(targetRepository.GetOwner(), resolver.repository.GetOwner()) &&
+		strings.EqualFold(targetRepository.GetName(), resolver.repository.GetName())
+	isInPackage := isSameRepository && isPathInDirectory(targetPath, resolver.packageRootPath)
+	if !isSameRepository || !isInPackage && strings.HasPrefix(locatorStr, absoluteLocatorPrefix) {
+		dependency, failure := resolver.findDependencyPackage(ctx, currentFilepath, functionName, locatorStr, targetRepository, targetPath)
+		return "", dependency, failure
+	}
+	if !isInPackage {
+		return "", "", newFailure(
+			locatorInvalidFailureCode,
+			currentFilepath,
+			fmt.Sprintf("the relative locator '%s' passed to '%s' points outside of the package", locatorStr, functionName),
+			"use the locator of the other package, like 'github.com/owner/repository/path/to/file'",
+		)
+	}
+
+	if _, err := resolver.packageSourceReader.Stat(ctx, resolver.repository, targetPath); err != nil {
+		if source.IsFileNotFoundErr(err) {
+			return "", "", newFailure(
+				locatorFileNotFoundFailureCode,
+				currentFilepath,
+				fmt.Sprintf("the locator '%s' passed to '%s' points to '%s', which doesn't exist in the package", locatorStr, functionName, strings.TrimPrefix(targetPath, repositoryRootPath)),
+				"fix the path of the locator, relative locators are relative to the file using them and the ones starting with '/' to the package root",
+			)
+		}
+		return "", "", newFailure(
+			locatorFileReadFailedFailureCode,
+			currentFilepath,
+			fmt.Sprintf("the file the locator '%s' passed to '%s' points to could not be read. Error was:\n%s", locatorStr, functionName, err.Error()),
+			"",
+		)
+	}
+	return targetPath, "", nil
+}
+
+// applyReplaceDirectives returns the locator with the most specific replace directive applied, and true if the
+// replacement is a local path, in which case the path in the package repository is returned
+func (resolver *locatorResolver) applyReplaceDirectives(locatorStr string) (string, bool) {
+	for _, directive := range resolver.replaceDirectives {
+		locatorSuffix, found := strings.CutPrefix(locatorStr, directive.replacedLocator)
+		if !found || (locatorSuffix != "" && !strings.HasPrefix(locatorSuffix, "/")) {
+			continue
+		}
+		if strings.HasPrefix(directive.replacement, localReplacePathPrefix) {
+			return joinRepositoryPaths(resolver.packageRootPath, directive.replacement+locatorSuffix), true
+		}
+		return directive.replacement + locatorSuffix, false
+	}
+	return locatorStr, false
+}
+
+// getTargetRepository returns the repository an absolute locator points to. The package repository is read at the
+// package ref, and the other repositories at the ref of the most specific catalog package containing the path, or at
+// their default branch if the path isn't in any catalog package
+func (resolver *locatorResolver) getTargetRepository(repositoryOwner string, repositoryName string, targetPath string) *source.PackageRepository {
+	if strings.EqualFold(repositoryOwner, resolver.repository.GetOwner()) && strings.EqualFold(repositoryName, resolver.repository.GetName()) {
+		return resolver.repository
+	}
+
+	ref := ""
+	longestPackageRootPath := ""
+	for _, catalogPackage := range resolver.packageCatalog {
+		if !strings.EqualFold(repositoryOwner, catalogPackage.GetRepositoryOwner()) || !strings.EqualFold(repositoryName, catalogPackage.GetRepositoryName()) {
+			continue
+		}
+		catalogPackageRootPath := path.Join(repositoryRootPath, catalogPackage.GetRepositoryPackageRootPath())
+		if isPathInDirectory(targetPath, catalogPackageRootPath) && len(catalogPackageRootPath) > len(longestPackageRootPath) {
+			ref = catalogPackage.GetRef()
+			longestPackageRootPath = catalogPackageRootPath
+		}
+	}
+	return source.NewPackageRepository(repositoryOwner, repositoryName, ref)
+}
+
+// findDependencyPackage returns the name of the package the target path is in, whose root is the closest directory to
+// the target path with a kurtosis.yml file. A failure is returned if there isn't any, that is if it isn't in a package
+func (resolver *locatorResolver) findDependencyPackage(ctx context.Context, currentFilepath string, functionName string, locatorStr string, targetRepository *source.PackageRepository, targetPath string) (types.PackageName, *Failure) {
+	repositoryKey := strings.ToLower(targetRepository.String())
+	if _, found := resolver.foundPackageRoots[repositoryKey]; !found {
+		resolver.foundPackageRoots[repositoryKey] = map[string]bool{}
+	}
+	foundPackageRoots := resolver.foundPackageRoots[repositoryKey]
+
+	for dirpath := path.Dir(targetPath); ; dirpath = path.Dir(dirpath) {
+		isPackageRoot, found := foundPackageRoots[dirpath]
+		if !found {
+			_, err := resolver.packageSourceReader.Stat(ctx, targetRepository, path.Join(dirpath, consts.DefaultKurtosisYamlFilename))
+			if err != nil && !source.IsFileNotFoundErr(err) {
+				return "", newFailure(
+					locatorFileReadFailedFailureCode,
+					currentFilepath,
+					fmt.Sprintf("the package of the locator '%s' passed to '%s' could not be checked. Error was:\n%s", locatorStr, functionName, err.Error()),
+					"",
+				)
+			}
+			isPackageRoot = err == nil
+			foundPackageRoots[dirpath] = isPackageRoot
+		}
+		if isPackageRoot {
+			return getPackageName(targetRepository, dirpath), nil
+		}
+		if dirpath == repositoryRootPath {
+			break
+		}
+	}
+	return "", newFailure(
+		locatorPackageNotFoundFailureCode,
+		currentFilepath,
+		fmt.Sprintf("the locator '%s' passed to '%s' doesn't point to a package, no '%s' file was found in '%s' nor in its parent directories", locatorStr, functionName, consts.DefaultKurtosisYamlFilename, targetRepository),
+		fmt.Sprintf("fix the locator, or add a 'replace' directive to the '%s' file if the package moved", consts.DefaultKurtosisYamlFilename),
+	)
+}
+
+// getPackageName returns the name of the package whose root is the directory of the repository
+func getPackageName(repository *source.PackageRepository, packageRootPath string) types.PackageName {
+	return types.PackageName(absoluteLocatorPrefix + path.Join(repository.GetOwner(), repository.GetName(), packageRootPath))
+}
+
+// joinRepositoryPaths returns the path relative to the directory as an absolute path in the repository, or an empty
+// path if it goes outside the repository
+func joinRepositoryPaths(dirpath string, relativePath string) string {
+	joinedPath := path.Join(strings.TrimPrefix(dirpath, repositoryRootPath), relativePath)
+	if joinedPath == parentDirPath || strings.HasPrefix(joinedPath, parentDirPath+repositoryRootPath) {
+		return ""
+	}
+	return path.Join(repositoryRootPath, joinedPath)
+}
+
+// isPathInDirectory returns true if the path is the directory or is inside it, both have to be clean absolute paths
+func isPathInDirectory(filepath string, dirpath string) bool {
+	return dirpath == repositoryRootPath || filepath == dirpath || strings.HasPrefix(filepath, dirpath+"/")
+}
@@ -0,0 +1,225 @@
+package rules
+
+import (
+	"context"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/source"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const (
+	locatorsTestCatalogYaml = `packages:
+  - name: "github.com/foo/bar/app"
+  - name: "github.com/other/dep"
+    ref: "v2"
+`
+
+	locatorsTestKurtosisYaml = `name: github.com/foo/bar/app
+replace:
+  github.com/foo/old: github.com/other/dep
+  github.com/foo/shared: ../shared
+`
+)
+
+// statRecordingPackageSourceReader is an in-memory source that records the repositories, with their ref, of the
+// files whose information is read
+type statRecordingPackageSourceReader struct {
+	*source.InMemoryPackageSourceReader
+	statRepositories map[string]bool
+}
+
+func (reader *statRecordingPackageSourceReader) Stat(ctx context.Context, repository *source.PackageRepository, filepath string) (*source.FileInfo, error) {
+	reader.statRepositories[repository.String()] = true
+	return reader.InMemoryPackageSourceReader.Stat(ctx, repository, filepath)
+}
+
+// readCountingPackageSourceReader is an in-memory source that counts the reads of each file, by repository and path
+type readCountingPackageSourceReader struct {
+	*source.InMemoryPackageSourceReader
+	readCounts map[string]int
+}
+
+func (reader *readCountingPackageSourceReader) ReadFile(ctx context.Context, repository *source.PackageRepository, filepath string) ([]byte, error) {
+	reader.readCounts[repository.String()+filepath]++
+	return reader.InMemoryPackageSourceReader.ReadFile(ctx, repository, filepath)
+}
+
+func TestResolvableLocatorsRule_CheckPackage(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		mainStarContent      string
+		expectedFailureCodes []FailureCode
+		expectedDependencies []types.PackageName
+	}{
+		{
+			name:                 "relative locators",
+			mainStarContent:      "helpers = import_module(\"./lib/helpers.star\")\nconfig = read_file(src = \"config/params.json\")\n",
+			expectedFailureCodes: []FailureCode{},
+			expectedDependencies: []types.PackageName{},
+		},
+		{
+			name:                 "relative locator to a missing file",
+			mainStarContent:      "config = read_file(\"./config/missing.json\")\n",
+			expectedFailureCodes: []FailureCode{locatorFileNotFoundFailureCode},
+			expectedDependencies: []types.PackageName{},
+		},
+		{
+			name:                 "locators relative to the package root",
+			mainStarContent:      "config = read_file(\"/config/params.json\")\nfiles = upload_files(\"/config/missing.json\")\n",
+			expectedFailureCodes: []FailureCode{locatorFileNotFoundFailureCode},
+			expectedDependencies: []types.PackageName{},
+		},
+		{
+			name:                 "locators of files imported by other files",
+			mainStarContent:      "missing_config = import_module(\"./lib/missing_config.star\")\nbroken = import_module(\"./lib/broken.star\")\n",
+			expectedFailureCodes: []FailureCode{locatorFileNotFoundFailureCode, starlarkFileSyntaxErrorFailureCode},
+			expectedDependencies: []types.PackageName{},
+		},
+		{
+			name:                 "absolute locators",
+			mainStarContent:      "helpers = import_module(\"github.com/foo/bar/app/lib/helpers.star\")\ndep = import_module(\"github.com/other/dep/dep.star\")\n",
+			expectedFailureCodes: []FailureCode{},
+			expectedDependencies: []types.PackageName{"github.com/other/dep"},
+		},
+		{
+			name:                 "absolute locators that aren't in a package",
+			mainStarContent:      "missing = import_module(\"github.com/other/missing/lib.star\")\ninvalid = import_module(\"github.com/other\")\n",
+			expectedFailureCodes: []FailureCode{locatorPackageNotFoundFailureCode, locatorInvalidFailureCode},
+			expectedDependencies: []types.PackageName{},
+		},
+		{
+			name:                 "replaced locators",
+			mainStarContent:      "dep = import_module(\"github.com/foo/old/dep.star\")\nshared = import_module(\"github.com/foo/shared/util.star\")\n",
+			expectedFailureCodes: []FailureCode{},
+			expectedDependencies: []types.PackageName{"github.com/foo/bar/shared", "github.com/other/dep"},
+		},
+		{
+			name:                 "locators out of the package",
+			mainStarContent:      "shared = import_module(\"../shared/util.star\")\nsecrets = read_file(\"../../secrets.json\")\n",
+			expectedFailureCodes: []FailureCode{locatorInvalidFailureCode, locatorInvalidFailureCode},
+			expectedDependencies: []types.PackageName{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			packageSourceReader := getLocatorsTestPackageSourceReader(testCase.mainStarContent)
+			packageCatalog := getLocatorsTestCatalog(t)
+
+			locatorsRule := newResolvableLocatorsRule(newPackageLocatorsCache(packageSourceReader), packageCatalog)
+			failures := locatorsRule.CheckPackage(context.Background(), packageCatalog[0])
+			require.Equal(t, testCase.expectedFailureCodes, getFailureCodes(failures))
+
+			dependencies := resolvePackageLocators(context.Background(), packageSourceReader, packageCatalog, packageCatalog[0]).dependencies
+			require.Equal(t, testCase.expectedDependencies, dependencies)
+		})
+	}
+}
+
+func TestResolvableLocatorsRule_CheckPackage_OtherRepositoryAtCatalogRef(t *testing.T) {
+	packageSourceReader := &statRecordingPackageSourceReader{
+		InMemoryPackageSourceReader: getLocatorsTestPackageSourceReader("dep = import_module(\"github.com/other/dep/dep.star\")\nunknown = import_module(\"github.com/unknown/dep/dep.star\")\n"),
+		statRepositories:            map[string]bool{},
+	}
+	packageSourceReader.AddFile("unknown", "dep", "kurtosis.yml", []byte("name: github.com/unknown/dep\n"))
+	packageCatalog := getLocatorsTestCatalog(t)
+
+	locatorsRule := newResolvableLocatorsRule(newPackageLocatorsCache(packageSourceReader), packageCatalog)
+	failures := locatorsRule.CheckPackage(context.Background(), packageCatalog[0])
+	require.Empty(t, failures)
+	require.Equal(t, map[string]bool{"other/dep@v2": true, "unknown/dep": true}, packageSourceReader.statRepositories)
+}
+
+func TestResolvableLocatorsRule_CheckPackage_ReadFailed(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		mainStarContent      string
+		failingFilename      string
+		expectedFailureCodes []FailureCode
+	}{
+		{
+			name:                 "imported file",
+			mainStarContent:      "helpers = import_module(\"./lib/helpers.star\")\n",
+			failingFilename:      "helpers.star",
+			expectedFailureCodes: []FailureCode{locatorFileReadFailedFailureCode},
+		},
+		{
+			name:                 "file of the package",
+			mainStarContent:      "config = read_file(\"./config/params.json\")\n",
+			failingFilename:      "params.json",
+			expectedFailureCodes: []FailureCode{locatorFileReadFailedFailureCode},
+		},
+		{
+			name:                 "manifest of another package",
+			mainStarContent:      "dep = import_module(\"github.com/other/dep/dep.star\")\n",
+			failingFilename:      "kurtosis.yml",
+			expectedFailureCodes: []FailureCode{locatorFileReadFailedFailureCode},
+		},
+		{
+			name:                 "main.star file",
+			mainStarContent:      "helpers = import_module(\"./lib/helpers.star\")\n",
+			failingFilename:      "main.star",
+			expectedFailureCodes: []FailureCode{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			packageSourceReader := &readFailingPackageSourceReader{
+				InMemoryPackageSourceReader: getLocatorsTestPackageSourceReader(testCase.mainStarContent),
+				failingFilename:             testCase.failingFilename,
+			}
+			packageCatalog := getLocatorsTestCatalog(t)
+
+			locatorsRule := newResolvableLocatorsRule(newPackageLocatorsCache(packageSourceReader), packageCatalog)
+			failures := locatorsRule.CheckPackage(context.Background(), packageCatalog[0])
+			require.Equal(t, testCase.expectedFailureCodes, getFailureCodes(failures))
+		})
+	}
+}
+
+func TestResolvableLocatorsRule_LocatorsSharedWithPackageDependenciesRule(t *testing.T) {
+	packageSourceReader := &readCountingPackageSourceReader{
+		InMemoryPackageSourceReader: getLocatorsTestPackageSourceReader("dep = import_module(\"github.com/other/dep/dep.star\")\nbroken = import_module(\"./lib/broken.star\")\n"),
+		readCounts:                  map[string]int{},
+	}
+	packageCatalog := getLocatorsTestCatalog(t)
+	packageLocatorsCache := newPackageLocatorsCache(packageSourceReader)
+
+	locatorsResult := newResolvableLocatorsRule(packageLocatorsCache, packageCatalog).Check(context.Background(), packageCatalog)
+	dependenciesResult := newPackageDependenciesRule(packageLocatorsCache, packageCatalog, defaultMaxDependencyDepth).Check(context.Background(), packageCatalog)
+	require.Equal(t, []FailureCode{starlarkFileSyntaxErrorFailureCode}, getFailureCodes(locatorsResult.GetFailures()["github.com/foo/bar/app"]))
+	require.Empty(t, dependenciesResult.GetFailures())
+	require.Equal(t, 1, packageSourceReader.readCounts["foo/bar/app/main.star"])
+	require.Equal(t, 1, packageSourceReader.readCounts["foo/bar/app/lib/broken.star"])
+
+	// checking again returns the same failures without reading the files again
+	locatorsAgainResult := newResolvableLocatorsRule(packageLocatorsCache, packageCatalog).Check(context.Background(), packageCatalog)
+	require.Equal(t, locatorsResult.GetFailures(), locatorsAgainResult.GetFailures())
+	require.Equal(t, 1, packageSourceReader.readCounts["foo/bar/app/main.star"])
+}
+
+// getLocatorsTestPackageSourceReader returns a source with the 'github.com/foo/bar/app' package using the main.star
+// file content, the 'github.com/foo/bar/shared' package in the same repository and the 'github.com/other/dep' package
+func getLocatorsTestPackageSourceReader(mainStarContent string) *source.InMemoryPackageSourceReader {
+	packageSourceReader := source.NewInMemoryPackageSourceReader()
+	packageSourceReader.AddFile("foo", "bar", "app/kurtosis.yml", []byte(locatorsTestKurtosisYaml))
+	packageSourceReader.AddFile("foo", "bar", "app/main.star", []byte(mainStarContent))
+	packageSourceReader.AddFile("foo", "bar", "app/lib/helpers.star", []byte("config = read_file(\"../config/params.json\")\n"))
+	packageSourceReader.AddFile("foo", "bar", "app/lib/missing_config.star", []byte("config = read_file(\"../config/missing.json\")\n"))
+	packageSourceReader.AddFile("foo", "bar", "app/lib/broken.star", []byte("def run(plan:\n"))
+	packageSourceReader.AddFile("foo", "bar", "app/config/params.json", []byte("{}"))
+	packageSourceReader.AddFile("foo", "bar", "shared/kurtosis.yml", []byte("name: github.com/foo/bar/shared\n"))
+	packageSourceReader.AddFile("foo", "bar", "shared/util.star", []byte(""))
+	packageSourceReader.AddFile("other", "dep", "kurtosis.yml", []byte("name: github.com/other/dep\n"))
+	packageSourceReader.AddFile("other", "dep", "dep.star", []byte(""))
+	return packageSourceReader
+}
+
+func getLocatorsTestCatalog(t *testing.T) catalog.PackageCatalog {
+	packageCatalog, err := catalog.GetPackageCatalogFromYamlFileContent([]byte(locatorsTestCatalogYaml))
+	require.NoError(t, err)
+	return packageCatalog
+}
@@ -2,12 +2,18 @@ package rules
 
 import (
 	"context"
-	"github.com/kurtosis-tech/kurtosis-package-indexer/server/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
 )
 
 type RuleName string
 
 type Rule interface {
 	GetName() RuleName
+	// GetDescription returns what the rule checks, in a sentence
+	GetDescription() string
+	// GetParameters returns the value of each parameter of the rule by its name in the rules config, it's empty if the rule doesn't have parameters
+	GetParameters() map[string]interface{}
+	// GetDefaultSeverity returns the severity of the rule failures that don't set their own severity, it can be overridden on each run
+	GetDefaultSeverity() Severity
 	Check(ctx context.Context, catalog catalog.PackageCatalog) *CheckResult
 }
@@ -0,0 +1,53 @@
+package rules
+
+import (
+	"github.com/kurtosis-tech/stacktrace"
+	"path"
+)
+
+// SelectRules returns the rules whose name matches any of the only patterns, all of them if there aren't only patterns,
+// without the ones whose name matches any of the skip patterns. The patterns are globs like 'Valid package*', with the
+// syntax of path.Match. It fails if a pattern is invalid or if it doesn't match any rule, which is usually a typo
+func SelectRules(rules []Rule, onlyPatterns []string, skipPatterns []string) ([]Rule, error) {
+	for _, pattern := range append(append([]string{}, onlyPatterns...), skipPatterns...) {
+		isMatchingAnyRule, err := isMatchingAnyRuleName(rules, pattern)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "an error occurred matching the rule names with the pattern '%s'", pattern)
+		}
+		if !isMatchingAnyRule {
+			return nil, stacktrace.NewError("the pattern '%s' doesn't match any rule, the rules are: %v", pattern, getRuleNames(rules))
+		}
+	}
+
+	selectedRules := []Rule{}
+	for _, rule := range rules {
+		// the patterns were already validated, so matching them can't fail
+		isOnlyRule := len(onlyPatterns) == 0 || isRuleNameMatchingAny(rule.GetName(), onlyPatterns)
+		isSkippedRule := isRuleNameMatchingAny(rule.GetName(), skipPatterns)
+		if isOnlyRule && !isSkippedRule {
+			selectedRules = append(selectedRules, rule)
+		}
+	}
+	return selectedRules, nil
+}
+
+func isMatchingAnyRuleName(rules []Rule, pattern string) (bool, error) {
+	isMatchingAnyRule := false
+	for _, rule := range rules {
+		isMatching, err := path.Match(pattern, string(rule.GetName()))
+		if err != nil {
+			return false, stacktrace.Propagate(err, "an error occurred matching the rule name '%s'", rule.GetName())
+		}
+		isMatchingAnyRule = isMatchingAnyRule || isMatching
+	}
+	return isMatchingAnyRule, nil
+}
+
+func isRuleNameMatchingAny(ruleName RuleName, patterns []string) bool {
+	for _, pattern := range patterns {
+		if isMatching, _ := path.Match(pattern, string(ruleName)); isMatching {
+			return true
+		}
+	}
+	return false
+}
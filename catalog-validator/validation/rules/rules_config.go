@@ -0,0 +1,183 @@
+package rules
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v3"
+	"io"
+)
+
+const (
+	rulesConfigSchemaFilename = "rules-config-schema.json"
+)
+
+var (
+	//go:embed rules-config-schema.json
+	rulesConfigSchemaContent string
+
+	// rulesConfigSchema is the JSON schema the rules config files have to follow, it's kept in sync with the rule
+	// parameters by the tests
+	rulesConfigSchema = jsonschema.MustCompileString(rulesConfigSchemaFilename, rulesConfigSchemaContent)
+)
+
+// rulesConfigFileContent is the content of the rules config file, e.g.:
+// rules:
+//
+//	"Valid package icon":
+//	  severity: "warning"
+//	  parameters:
+//	    min-size: 64
+//	    max-size: 2048
+//	"Removed package":
+//	  enabled: false
+type rulesConfigFileContent struct {
+	Rules map[string]*ruleConfigFileEntry `yaml:"rules"`
+}
+
+type ruleConfigFileEntry struct {
+	Enabled    *bool     `yaml:"enabled"`
+	Severity   string    `yaml:"severity"`
+	Parameters yaml.Node `yaml:"parameters"`
+}
+
+// RulesConfig enables or disables the rules, overrides their severity and sets their parameters. The rules that aren't
+// in the config are enabled, with their default severity and parameters
+type RulesConfig struct {
+	ruleConfigs map[RuleName]*ruleConfig
+}
+
+type ruleConfig struct {
+	isEnabled bool
+
+	// severity is empty if the rule keeps its default severity
+	severity Severity
+
+	// parameters is the YAML content of the rule parameters, it's decoded by each rule into its own parameters
+	parameters yaml.Node
+}
+
+// NewDefaultRulesConfig returns the config with all the rules enabled, with their default severity and parameters
+func NewDefaultRulesConfig() *RulesConfig {
+	return &RulesConfig{ruleConfigs: map[RuleName]*ruleConfig{}}
+}
+
+// ReadRulesConfigFileContent returns the rules config in the config file content, which has to follow the rules config
+// schema, so the unknown rules, fields and parameters and the values out of range are rejected. The parameters that
+// depend on each other are validated when the rules are created
+func ReadRulesConfigFileContent(fileContent []byte) (*RulesConfig, error) {
+	if err := validateRulesConfigFileContent(fileContent); err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred validating the rules config file content against its schema")
+	}
+
+	rulesConfigFileContentObj := &rulesConfigFileContent{}
+	if err := decodeYamlStrictly(fileContent, rulesConfigFileContentObj); err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred unmarshalling the rules config file content")
+	}
+
+	rulesConfig := NewDefaultRulesConfig()
+	for ruleNameStr, ruleConfigEntry := range rulesConfigFileContentObj.Rules {
+		if ruleConfigEntry == nil {
+			return nil, stacktrace.NewError("expected the config of rule '%s' to have at least one of the 'enabled', 'severity' and 'parameters' fields, but it's empty", ruleNameStr)
+		}
+		ruleConfigObj := &ruleConfig{isEnabled: true, severity: ruleSeverity, parameters: ruleConfigEntry.Parameters}
+		if ruleConfigEntry.Enabled != nil {
+			ruleConfigObj.isEnabled = *ruleConfigEntry.Enabled
+		}
+		if ruleConfigEntry.Severity != "" {
+			severity, err := ParseSeverity(ruleConfigEntry.Severity)
+			if err != nil {
+				return nil, stacktrace.Propagate(err, "an error occurred parsing the severity of rule '%s'", ruleNameStr)
+			}
+			ruleConfigObj.severity = severity
+		}
+		rulesConfig.ruleConfigs[RuleName(ruleNameStr)] = ruleConfigObj
+	}
+	return rulesConfig, nil
+}
+
+// IsRuleEnabled returns false if the rule was disabled in the config
+func (rulesConfig *RulesConfig) IsRuleEnabled(ruleName RuleName) bool {
+	ruleConfigObj, found := rulesConfig.ruleConfigs[ruleName]
+	return !found || ruleConfigObj.isEnabled
+}
+
+// GetRuleSeverityOverrides returns the severity of the rules whose severity was overridden in the config
+func (rulesConfig *RulesConfig) GetRuleSeverityOverrides() map[RuleName]Severity {
+	ruleSeverityOverrides := map[RuleName]Severity{}
+	for ruleName, ruleConfigObj := range rulesConfig.ruleConfigs {
+		if ruleConfigObj.severity != ruleSeverity {
+			ruleSeverityOverrides[ruleName] = ruleConfigObj.severity
+		}
+	}
+	return ruleSeverityOverrides
+}
+
+// getRuleNames returns the names of the rules in the config
+func (rulesConfig *RulesConfig) getRuleNames() []RuleName {
+	ruleNames := []RuleName{}
+	for ruleName := range rulesConfig.ruleConfigs {
+		ruleNames = append(ruleNames, ruleName)
+	}
+	return ruleNames
+}
+
+// decodeRuleParameters decodes the parameters of the rule into the parameters object, which keeps its default values
+// for the parameters that aren't set. The unknown parameters are rejected
+func (rulesConfig *RulesConfig) decodeRuleParameters(ruleName RuleName, parameters interface{}) error {
+	ruleConfigObj, found := rulesConfig.ruleConfigs[ruleName]
+	if !found || ruleConfigObj.parameters.IsZero() {
+		return nil
+	}
+
+	// the node is encoded again because only the decoder can reject the unknown fields
+	parametersContent, err := yaml.Marshal(&ruleConfigObj.parameters)
+	if err != nil {
+		return stacktrace.Propagate(err, "an error occurred marshalling the parameters of rule '%s'", ruleName)
+	}
+	if err := decodeYamlStrictly(parametersContent, parameters); err != nil {
+		return stacktrace.Propagate(err, "an error occurred decoding the parameters of rule '%s'", ruleName)
+	}
+	return nil
+}
+
+// validateRulesConfigFileContent validates the config file content against the rules config schema, an empty file is
+// valid as it doesn't change any rule
+func validateRulesConfigFileContent(fileContent []byte) error {
+	var yamlValue interface{}
+	if err := yaml.Unmarshal(fileContent, &yamlValue); err != nil {
+		return stacktrace.Propagate(err, "an error occurred unmarshalling the rules config file content")
+	}
+	if yamlValue == nil {
+		return nil
+	}
+
+	// the YAML value is converted to JSON so it has the types expected by the schema validator
+	jsonContent, err := json.Marshal(yamlValue)
+	if err != nil {
+		return stacktrace.Propagate(err, "an error occurred converting the rules config file content to JSON")
+	}
+	var jsonValue interface{}
+	jsonDecoder := json.NewDecoder(bytes.NewReader(jsonContent))
+	jsonDecoder.UseNumber()
+	if err := jsonDecoder.Decode(&jsonValue); err != nil {
+		return stacktrace.Propagate(err, "an error occurred unmarshalling the rules config file content converted to JSON")
+	}
+	if err := rulesConfigSchema.Validate(jsonValue); err != nil {
+		return stacktrace.Propagate(err, "the rules config file content doesn't follow the schema in '%s'", rulesConfigSchemaFilename)
+	}
+	return nil
+}
+
+// decodeYamlStrictly decodes the YAML content into the object, failing if the content has fields the object doesn't
+func decodeYamlStrictly(yamlContent []byte, obj interface{}) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(yamlContent))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(obj); err != nil && !errors.Is(err, io.EOF) {
+		return stacktrace.Propagate(err, "an error occurred decoding the YAML content")
+	}
+	return nil
+}
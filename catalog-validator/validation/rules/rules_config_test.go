@@ -0,0 +1,204 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/source"
+	"github.com/stretchr/testify/require"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const (
+	ruleWithoutParametersSchemaRef = "#/definitions/ruleWithoutParameters"
+)
+
+var (
+	// ruleParameters are the parameters decoded from the rules config by each rule
+	ruleParameters = map[RuleName]interface{}{
+		duplicatedPackageRuleName:       noRuleParameters{},
+		validPackageRuleName:            validPackageRuleParameters{},
+		validPackageRefRuleName:         validPackageRefRuleParameters{},
+		validPackageManifestRuleName:    noRuleParameters{},
+		validPackageIconRuleName:        validPackageIconRuleParameters{},
+		validPackageDescriptionRuleName: validPackageDescriptionRuleParameters{},
+		runnablePackageRuleName:         noRuleParameters{},
+		validRunArgumentsRuleName:       noRuleParameters{},
+		resolvableLocatorsRuleName:      noRuleParameters{},
+		packageDependenciesRuleName:     packageDependenciesRuleParameters{},
+		pinnedContainerImagesRuleName:   pinnedContainerImagesRuleParameters{},
+		removedPackageRuleName:          removedPackageRuleParameters{},
+	}
+
+	// jsonSchemaTypes are the JSON schema types of the Go kinds used in the rule parameters
+	jsonSchemaTypes = map[reflect.Kind]string{
+		reflect.Bool:  "boolean",
+		reflect.Int:   "integer",
+		reflect.Slice: "array",
+	}
+)
+
+// jsonSchema is the subset of a JSON schema needed to compare the rules config schema with the rule parameters, the
+// properties are kept raw as they can be boolean schemas
+type jsonSchema struct {
+	Ref        string                     `json:"$ref"`
+	Type       string                     `json:"type"`
+	Properties map[string]json.RawMessage `json:"properties"`
+}
+
+func TestRulesConfigSchema_MatchesRuleParameters(t *testing.T) {
+	allRules, err := GetAll(context.Background(), source.NewInMemoryPackageSourceReader(), "", nil, nil)
+	require.NoError(t, err)
+	allRuleNames := []string{}
+	for _, rule := range allRules {
+		allRuleNames = append(allRuleNames, string(rule.GetName()))
+		require.Contains(t, ruleParameters, rule.GetName(), "the parameters of rule '%s' aren't in the test", rule.GetName())
+		require.ElementsMatch(t, getParameterNames(ruleParameters[rule.GetName()]), getMapKeys(rule.GetParameters()), "the parameters listed by rule '%s' aren't the ones in its rules config", rule.GetName())
+	}
+	require.Len(t, ruleParameters, len(allRules))
+
+	rulesConfigSchema := readJsonSchema(t, []byte(rulesConfigSchemaContent))
+	rulesSchema := readJsonSchema(t, rulesConfigSchema.Properties["rules"])
+	require.ElementsMatch(t, allRuleNames, getMapKeys(rulesSchema.Properties), "the rules in the schema aren't all the rules")
+
+	for ruleName, parameters := range ruleParameters {
+		ruleSchema := readJsonSchema(t, rulesSchema.Properties[string(ruleName)])
+		parametersType := reflect.TypeOf(parameters)
+		if parametersType.NumField() == 0 {
+			require.Equal(t, ruleWithoutParametersSchemaRef, ruleSchema.Ref, "rule '%s' doesn't have parameters", ruleName)
+			continue
+		}
+
+		parametersSchema := readJsonSchema(t, ruleSchema.Properties["parameters"])
+		require.ElementsMatch(t, getParameterNames(parameters), getMapKeys(parametersSchema.Properties), "the parameters of rule '%s' in the schema aren't its parameters", ruleName)
+		for fieldIndex := 0; fieldIndex < parametersType.NumField(); fieldIndex++ {
+			field := parametersType.Field(fieldIndex)
+			parameterName := getYamlFieldName(field)
+			parameterSchema := readJsonSchema(t, parametersSchema.Properties[parameterName])
+			require.Equal(t, jsonSchemaTypes[field.Type.Kind()], parameterSchema.Type, "the type of parameter '%s' of rule '%s' in the schema isn't its type", parameterName, ruleName)
+		}
+	}
+}
+
+func TestReadRulesConfigFileContent(t *testing.T) {
+	rulesConfig, err := ReadRulesConfigFileContent([]byte(`rules:
+  "Valid package icon":
+    severity: "warning"
+    parameters:
+      min-size: 64
+  "Removed package":
+    enabled: false
+`))
+	require.NoError(t, err)
+
+	allRules, err := GetAll(context.Background(), source.NewInMemoryPackageSourceReader(), "", nil, rulesConfig)
+	require.NoError(t, err)
+	require.Len(t, allRules, len(ruleParameters)-1)
+	require.Equal(t, map[RuleName]Severity{validPackageIconRuleName: SeverityWarning}, rulesConfig.GetRuleSeverityOverrides())
+}
+
+func TestReadRulesConfigFileContent_EmptyConfig(t *testing.T) {
+	rulesConfig, err := ReadRulesConfigFileContent([]byte(""))
+	require.NoError(t, err)
+	require.Empty(t, rulesConfig.GetRuleSeverityOverrides())
+}
+
+func TestReadRulesConfigFileContent_InvalidConfig(t *testing.T) {
+	testCases := []struct {
+		name              string
+		rulesConfigYaml   string
+		isInvalidFileData bool
+	}{
+		{
+			name:              "unknown field",
+			rulesConfigYaml:   "rules:\n  \"Valid package icon\":\n    disabled: true\n",
+			isInvalidFileData: true,
+		},
+		{
+			name:              "invalid severity",
+			rulesConfigYaml:   "rules:\n  \"Valid package icon\":\n    severity: fatal\n",
+			isInvalidFileData: true,
+		},
+		{
+			name:              "unknown rule",
+			rulesConfigYaml:   "rules:\n  \"Valid package logo\":\n    enabled: false\n",
+			isInvalidFileData: true,
+		},
+		{
+			name:              "unknown parameter",
+			rulesConfigYaml:   "rules:\n  \"Valid package icon\":\n    parameters:\n      min-width: 64\n",
+			isInvalidFileData: true,
+		},
+		{
+			name:              "parameters of a rule without parameters",
+			rulesConfigYaml:   "rules:\n  \"Valid package manifest\":\n    parameters:\n      strict: true\n",
+			isInvalidFileData: true,
+		},
+		{
+			name:              "empty rule config",
+			rulesConfigYaml:   "rules:\n  \"Valid package icon\": {}\n",
+			isInvalidFileData: true,
+		},
+		{
+			name:              "parameter out of range",
+			rulesConfigYaml:   "rules:\n  \"Valid package icon\":\n    parameters:\n      min-size: 0\n",
+			isInvalidFileData: true,
+		},
+		{
+			name:              "parameter with the wrong type",
+			rulesConfigYaml:   "rules:\n  \"Package dependencies\":\n    parameters:\n      max-depth: \"5\"\n",
+			isInvalidFileData: true,
+		},
+		{
+			name:              "min parameter bigger than the max one",
+			rulesConfigYaml:   "rules:\n  \"Valid package icon\":\n    parameters:\n      min-size: 512\n      max-size: 256\n",
+			isInvalidFileData: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			rulesConfig, err := ReadRulesConfigFileContent([]byte(testCase.rulesConfigYaml))
+			if testCase.isInvalidFileData {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			_, err = GetAll(context.Background(), source.NewInMemoryPackageSourceReader(), "", nil, rulesConfig)
+			require.Error(t, err)
+		})
+	}
+}
+
+func readJsonSchema(t *testing.T, jsonSchemaContent []byte) *jsonSchema {
+	require.NotEmpty(t, jsonSchemaContent)
+	schema := &jsonSchema{}
+	require.NoError(t, json.Unmarshal(jsonSchemaContent, schema))
+	return schema
+}
+
+// getParameterNames returns the names of the parameters in the rules config, which are the YAML names of the fields
+func getParameterNames(parameters interface{}) []string {
+	parametersType := reflect.TypeOf(parameters)
+	parameterNames := []string{}
+	for fieldIndex := 0; fieldIndex < parametersType.NumField(); fieldIndex++ {
+		parameterNames = append(parameterNames, getYamlFieldName(parametersType.Field(fieldIndex)))
+	}
+	return parameterNames
+}
+
+func getYamlFieldName(field reflect.StructField) string {
+	yamlFieldName, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	return yamlFieldName
+}
+
+func getMapKeys[V any](values map[string]V) []string {
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
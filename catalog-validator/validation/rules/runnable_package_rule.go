@@ -0,0 +1,135 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/source"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/starlarkfile"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/consts"
+	"github.com/sirupsen/logrus"
+	"go.starlark.net/syntax"
+	"path"
+)
+
+const (
+	runnablePackageRuleName = "Runnable package"
+
+	// runFunctionName is the function Kurtosis calls to run a package, its first parameter is the plan
+	runFunctionName          = "run"
+	runFunctionPlanParamName = "plan"
+
+	mainStarNotFoundFailureCode       FailureCode = "MAIN_STAR_NOT_FOUND"
+	mainStarReadFailedFailureCode     FailureCode = "MAIN_STAR_READ_FAILED"
+	mainStarSyntaxErrorFailureCode    FailureCode = "MAIN_STAR_SYNTAX_ERROR"
+	runFunctionNotFoundFailureCode    FailureCode = "RUN_FUNCTION_NOT_FOUND"
+	runFunctionInvalidPlanFailureCode FailureCode = "RUN_FUNCTION_INVALID_PLAN_PARAMETER"
+)
+
+// runnablePackageRule checks if Kurtosis can run the package by checking if:
+// 1- the package root contains the main.star file
+// 2- the main.star file is valid Starlark
+// 3- the main.star file defines a top-level run function
+// 4- the first parameter of the run function is plan
+type runnablePackageRule struct {
+	name                string
+	packageSourceReader source.PackageSourceReader
+}
+
+func newRunnablePackageRule(packageSourceReader source.PackageSourceReader) *runnablePackageRule {
+	return &runnablePackageRule{name: runnablePackageRuleName, packageSourceReader: packageSourceReader}
+}
+
+func (runnablePackageRule *runnablePackageRule) GetName() RuleName {
+	return RuleName(runnablePackageRule.name)
+}
+
+func (runnablePackageRule *runnablePackageRule) GetDescription() string {
+	return "checks that the package root has a valid main.star file defining a top-level 'run' function whose first parameter is 'plan'"
+}
+
+func (runnablePackageRule *runnablePackageRule) GetParameters() map[string]interface{} {
+	return map[string]interface{}{}
+}
+
+func (runnablePackageRule *runnablePackageRule) GetDefaultSeverity() Severity {
+	return SeverityError
+}
+
+func (runnablePackageRule *runnablePackageRule) Check(ctx context.Context, catalog catalog.PackageCatalog) *CheckResult {
+	return checkEachPackage(ctx, runnablePackageRule, catalog)
+}
+
+func (runnablePackageRule *runnablePackageRule) CheckPackage(ctx context.Context, packageData PackageData) []*Failure {
+	packageName := packageData.GetPackageName()
+	logrus.Debugf("Checking if package '%s' is runnable...", packageName)
+	repository := source.NewPackageRepositoryFromPackageData(packageData)
+	mainStarFilepath := getMainStarFilepath(packageData)
+
+	mainStarFileContent, err := runnablePackageRule.packageSourceReader.ReadFile(ctx, repository, mainStarFilepath)
+	if err != nil && source.IsFileNotFoundErr(err) {
+		return []*Failure{newFailure(
+			mainStarNotFoundFailureCode,
+			mainStarFilepath,
+			fmt.Sprintf("the package does not contain the '%s' file, Kurtosis runs the package from it", consts.StarlarkMainDotStarFileName),
+			fmt.Sprintf("add a '%s' file with a 'def %s(%s):' function next to the '%s' file", consts.StarlarkMainDotStarFileName, runFunctionName, runFunctionPlanParamName, consts.DefaultKurtosisYamlFilename),
+		)}
+	} else if err != nil {
+		return []*Failure{newFailure(
+			mainStarReadFailedFailureCode,
+			mainStarFilepath,
+			fmt.Sprintf("the '%s' file could not be read. Error was:\n%s", consts.StarlarkMainDotStarFileName, err.Error()),
+			"",
+		)}
+	}
+
+	mainStarFile, syntaxErr := starlarkfile.ParseFile(mainStarFilepath, mainStarFileContent)
+	if syntaxErr != nil {
+		return []*Failure{newFailure(
+			mainStarSyntaxErrorFailureCode,
+			mainStarFilepath,
+			fmt.Sprintf("the '%s' file is not valid Starlark: %s", consts.StarlarkMainDotStarFileName, syntaxErr.GetMessage()),
+			"fix the syntax error, running the package locally with 'kurtosis run' shows the same error",
+		).withPosition(syntaxErr.GetLine(), syntaxErr.GetColumn())}
+	}
+
+	runFunction := mainStarFile.FindTopLevelFunction(runFunctionName)
+	if runFunction == nil {
+		return []*Failure{newFailure(
+			runFunctionNotFoundFailureCode,
+			mainStarFilepath,
+			fmt.Sprintf("the '%s' file does not define a top-level '%s' function", consts.StarlarkMainDotStarFileName, runFunctionName),
+			fmt.Sprintf("define the function at the top level of the file, like 'def %s(%s, ...):'", runFunctionName, runFunctionPlanParamName),
+		)}
+	}
+
+	if failure := checkRunFunctionPlanParam(mainStarFilepath, runFunction); failure != nil {
+		return []*Failure{failure}
+	}
+
+	logrus.Debugf("...package '%s' is runnable.", packageName)
+	return []*Failure{}
+}
+
+// getMainStarFilepath returns the path of the main.star file in the package repository
+func getMainStarFilepath(packageData PackageData) string {
+	return path.Join(packageData.GetRepositoryPackageRootPath(), consts.StarlarkMainDotStarFileName)
+}
+
+// checkRunFunctionPlanParam returns a failure if the first parameter of the run function isn't a plain 'plan' parameter
+func checkRunFunctionPlanParam(mainStarFilepath string, runFunction *syntax.DefStmt) *Failure {
+	failurePosition := runFunction.Name.NamePos
+	if len(runFunction.Params) > 0 {
+		firstParam, isIdent := runFunction.Params[0].(*syntax.Ident)
+		if isIdent && firstParam.Name == runFunctionPlanParamName {
+			return nil
+		}
+		failurePosition, _ = runFunction.Params[0].Span()
+	}
+	return newFailure(
+		runFunctionInvalidPlanFailureCode,
+		mainStarFilepath,
+		fmt.Sprintf("the first parameter of the '%s' function has to be '%s', without a default value", runFunctionName, runFunctionPlanParamName),
+		fmt.Sprintf("define the function like 'def %s(%s, ...):', Kurtosis passes the plan as its first argument", runFunctionName, runFunctionPlanParamName),
+	).withPosition(int(failurePosition.Line), int(failurePosition.Col))
+}
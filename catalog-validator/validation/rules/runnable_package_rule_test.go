@@ -0,0 +1,119 @@
+package rules
+
+import (
+	"context"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/source"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/consts"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/stretchr/testify/require"
+	"path"
+	"testing"
+)
+
+// readFailingPackageSourceReader is an in-memory source that fails to read the files with the name, and their
+// information, with an error other than the file not being found, like the GitHub API being unreachable
+type readFailingPackageSourceReader struct {
+	*source.InMemoryPackageSourceReader
+	failingFilename string
+}
+
+func (reader *readFailingPackageSourceReader) ReadFile(ctx context.Context, repository *source.PackageRepository, filepath string) ([]byte, error) {
+	if path.Base(filepath) == reader.failingFilename {
+		return nil, stacktrace.NewError("an error occurred reading file '%s' of repository '%s'", filepath, repository)
+	}
+	return reader.InMemoryPackageSourceReader.ReadFile(ctx, repository, filepath)
+}
+
+func (reader *readFailingPackageSourceReader) Stat(ctx context.Context, repository *source.PackageRepository, filepath string) (*source.FileInfo, error) {
+	if path.Base(filepath) == reader.failingFilename {
+		return nil, stacktrace.NewError("an error occurred getting the information of file '%s' of repository '%s'", filepath, repository)
+	}
+	return reader.InMemoryPackageSourceReader.Stat(ctx, repository, filepath)
+}
+
+func TestRunnablePackageRule_CheckPackage(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		mainStarContent      string
+		expectedFailureCodes []FailureCode
+		expectedLine         int
+		expectedColumn       int
+	}{
+		{
+			name:                 "runnable package",
+			mainStarContent:      "def run(plan, name = \"postgres\"):\n    plan.print(name)\n",
+			expectedFailureCodes: []FailureCode{},
+		},
+		{
+			name:                 "syntax error",
+			mainStarContent:      "def run(plan):\n    plan.print(\"hello\"\n",
+			expectedFailureCodes: []FailureCode{mainStarSyntaxErrorFailureCode},
+			expectedLine:         3,
+			expectedColumn:       1,
+		},
+		{
+			name:                 "no run function",
+			mainStarContent:      "def main(plan):\n    pass\n",
+			expectedFailureCodes: []FailureCode{runFunctionNotFoundFailureCode},
+		},
+		{
+			name:                 "run function that isn't at the top level",
+			mainStarContent:      "def main(plan):\n    def run(plan):\n        pass\n    return run\n",
+			expectedFailureCodes: []FailureCode{runFunctionNotFoundFailureCode},
+		},
+		{
+			name:                 "plan isn't the first parameter",
+			mainStarContent:      "def run(args, plan):\n    pass\n",
+			expectedFailureCodes: []FailureCode{runFunctionInvalidPlanFailureCode},
+			expectedLine:         1,
+			expectedColumn:       9,
+		},
+		{
+			name:                 "plan with a default value",
+			mainStarContent:      "def run(plan = None):\n    pass\n",
+			expectedFailureCodes: []FailureCode{runFunctionInvalidPlanFailureCode},
+			expectedLine:         1,
+			expectedColumn:       9,
+		},
+		{
+			name:                 "run function without parameters",
+			mainStarContent:      "def run():\n    pass\n",
+			expectedFailureCodes: []FailureCode{runFunctionInvalidPlanFailureCode},
+			expectedLine:         1,
+			expectedColumn:       5,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			packageSourceReader := source.NewInMemoryPackageSourceReader()
+			packageSourceReader.AddFile(testRepositoryOwner, testRepositoryName, consts.StarlarkMainDotStarFileName, []byte(testCase.mainStarContent))
+
+			failures := checkTestPackage(t, newRunnablePackageRule(packageSourceReader), getTestCatalogYaml(testPackageName))
+			require.Equal(t, testCase.expectedFailureCodes, getFailureCodes(failures))
+			for _, failure := range failures {
+				require.Equal(t, consts.StarlarkMainDotStarFileName, failure.GetFilepath())
+				require.Equal(t, testCase.expectedLine, failure.GetLine())
+				require.Equal(t, testCase.expectedColumn, failure.GetColumn())
+			}
+		})
+	}
+}
+
+func TestRunnablePackageRule_CheckPackage_MissingMainStar(t *testing.T) {
+	packageSourceReader := source.NewInMemoryPackageSourceReader()
+	packageSourceReader.AddFile(testRepositoryOwner, testRepositoryName, consts.DefaultKurtosisYamlFilename, []byte("name: "+testPackageName+"\n"))
+
+	failures := checkTestPackage(t, newRunnablePackageRule(packageSourceReader), getTestCatalogYaml(testPackageName))
+	require.Equal(t, []FailureCode{mainStarNotFoundFailureCode}, getFailureCodes(failures))
+}
+
+func TestRunnablePackageRule_CheckPackage_ReadFailed(t *testing.T) {
+	packageSourceReader := &readFailingPackageSourceReader{
+		InMemoryPackageSourceReader: source.NewInMemoryPackageSourceReader(),
+		failingFilename:             consts.StarlarkMainDotStarFileName,
+	}
+
+	failures := checkTestPackage(t, newRunnablePackageRule(packageSourceReader), getTestCatalogYaml(testPackageName))
+	require.Equal(t, []FailureCode{mainStarReadFailedFailureCode}, getFailureCodes(failures))
+}
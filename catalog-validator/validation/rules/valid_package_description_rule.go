@@ -0,0 +1,291 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/source"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/yamlnode"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/consts"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	validPackageDescriptionRuleName = "Valid package description"
+	defaultMinDescriptionLength     = 20
+	defaultMaxDescriptionLength     = 2000
+
+	descriptionMissingFailureCode         FailureCode = "DESCRIPTION_MISSING"
+	descriptionTooShortFailureCode        FailureCode = "DESCRIPTION_TOO_SHORT"
+	descriptionTooLongFailureCode         FailureCode = "DESCRIPTION_TOO_LONG"
+	descriptionPlaceholderFailureCode     FailureCode = "DESCRIPTION_PLACEHOLDER"
+	descriptionInvalidMarkdownFailureCode FailureCode = "DESCRIPTION_INVALID_MARKDOWN"
+	descriptionRawHtmlFailureCode         FailureCode = "DESCRIPTION_RAW_HTML"
+
+	markdownCodeFence = "```"
+
+	// markdownLinkDestinationStart is between the text and the destination of a link, e.g. '[docs](https://docs.kurtosis.com)'
+	markdownLinkDestinationStart = "]("
+)
+
+var (
+	// defaultDescriptionPlaceholders are the texts of the description templates, compared without case
+	defaultDescriptionPlaceholders = []string{
+		"enter description here",
+		"add a description",
+		"package description",
+		"description goes here",
+		"lorem ipsum",
+		"todo",
+	}
+
+	// markdownCodeSpanRegex matches the inline code, whose content isn't Markdown nor HTML
+	markdownCodeSpanRegex = regexp.MustCompile("`[^`\n]*`")
+
+	// htmlTagRegex matches the HTML tags and comments, the Markdown autolinks like '<https://kurtosis.com>' aren't matched
+	htmlTagRegex = regexp.MustCompile(`<!--|</?[A-Za-z][A-Za-z0-9-]*(?:\s[^<>]*)?/?>`)
+
+	// markdownLinkDestinationRegex matches the rest of a closed link after its '](', which is the URL, that can be
+	// between '<>' or contain balanced parentheses, and an optional title between quotes or parentheses, e.g.
+	// 'https://docs.kurtosis.com "Kurtosis docs")'
+	markdownLinkDestinationRegex = regexp.MustCompile(`^\s*(?:<[^<>\n]*>|(?:[^\s()]|\([^\s()]*\))*)(?:\s+(?:"[^"]*"|'[^']*'|\([^()]*\)))?\s*\)`)
+
+	// markdownEmptyLinkRegex matches the links without URL, e.g. '[docs]()'
+	markdownEmptyLinkRegex = regexp.MustCompile(`\]\(\s*\)`)
+)
+
+// validPackageDescriptionRuleParameters are the parameters of the rule that can be set in the rules config
+type validPackageDescriptionRuleParameters struct {
+	MinLength    int      `yaml:"min-length"`
+	MaxLength    int      `yaml:"max-length"`
+	Placeholders []string `yaml:"placeholders"`
+}
+
+// validPackageDescriptionRule checks if the description shown in the catalog UI is useful by checking if:
+// 1- the kurtosis.yml file has a description
+// 2- the description length, in characters, is between minLength and maxLength
+// 3- the description doesn't contain a template placeholder like 'Enter description here'
+// 4- the description is valid Markdown, without unclosed code blocks nor broken links
+// 5- the description doesn't contain raw HTML, which the catalog UI doesn't render
+type validPackageDescriptionRule struct {
+	name                string
+	packageSourceReader source.PackageSourceReader
+	minLength           int
+	maxLength           int
+	placeholders        []string
+
+	// placeholderRegexes match each placeholder as whole words without case, they are in the same order as placeholders
+	placeholderRegexes []*regexp.Regexp
+}
+
+func newValidPackageDescriptionRule(packageSourceReader source.PackageSourceReader, minLength int, maxLength int, placeholders []string) *validPackageDescriptionRule {
+	placeholderRegexes := make([]*regexp.Regexp, len(placeholders))
+	for placeholderIdx, placeholder := range placeholders {
+		placeholderRegexes[placeholderIdx] = newPlaceholderRegex(placeholder)
+	}
+	return &validPackageDescriptionRule{name: validPackageDescriptionRuleName, packageSourceReader: packageSourceReader, minLength: minLength, maxLength: maxLength, placeholders: placeholders, placeholderRegexes: placeholderRegexes}
+}
+
+// newValidPackageDescriptionRuleFromConfig returns the rule with the length bounds and placeholders set in the rules
+// config, or the default ones
+func newValidPackageDescriptionRuleFromConfig(packageSourceReader source.PackageSourceReader, rulesConfig *RulesConfig) (*validPackageDescriptionRule, error) {
+	parameters := &validPackageDescriptionRuleParameters{
+		MinLength:    defaultMinDescriptionLength,
+		MaxLength:    defaultMaxDescriptionLength,
+		Placeholders: defaultDescriptionPlaceholders,
+	}
+	if err := rulesConfig.decodeRuleParameters(validPackageDescriptionRuleName, parameters); err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred getting the parameters of rule '%s'", validPackageDescriptionRuleName)
+	}
+	if parameters.MinLength < 1 || parameters.MinLength > parameters.MaxLength {
+		return nil, stacktrace.NewError("expected the 'min-length' parameter of rule '%s' to be positive and not bigger than 'max-length', but they are '%d' and '%d'", validPackageDescriptionRuleName, parameters.MinLength, parameters.MaxLength)
+	}
+	for _, placeholder := range parameters.Placeholders {
+		if strings.TrimSpace(placeholder) == "" {
+			return nil, stacktrace.NewError("expected the 'placeholders' parameter of rule '%s' to contain texts, but one of them is empty", validPackageDescriptionRuleName)
+		}
+	}
+	return newValidPackageDescriptionRule(packageSourceReader, parameters.MinLength, parameters.MaxLength, parameters.Placeholders), nil
+}
+
+func (validPackageDescriptionRule *validPackageDescriptionRule) GetName() RuleName {
+	return RuleName(validPackageDescriptionRule.name)
+}
+
+func (validPackageDescriptionRule *validPackageDescriptionRule) GetDescription() string {
+	return "checks that the kurtosis.yml description shown in the catalog UI is present, within the length bounds, not a template placeholder, valid Markdown and without raw HTML"
+}
+
+func (validPackageDescriptionRule *validPackageDescriptionRule) GetParameters() map[string]interface{} {
+	return map[string]interface{}{
+		"min-length":   validPackageDescriptionRule.minLength,
+		"max-length":   validPackageDescriptionRule.maxLength,
+		"placeholders": validPackageDescriptionRule.placeholders,
+	}
+}
+
+func (validPackageDescriptionRule *validPackageDescriptionRule) GetDefaultSeverity() Severity {
+	return SeverityError
+}
+
+func (validPackageDescriptionRule *validPackageDescriptionRule) Check(ctx context.Context, catalog catalog.PackageCatalog) *CheckResult {
+	return checkEachPackage(ctx, validPackageDescriptionRule, catalog)
+}
+
+func (validPackageDescriptionRule *validPackageDescriptionRule) CheckPackage(ctx context.Context, packageData PackageData) []*Failure {
+	packageName := packageData.GetPackageName()
+	logrus.Debugf("Checking if the description of package '%s' is valid...", packageName)
+	repository := source.NewPackageRepositoryFromPackageData(packageData)
+	kurtosisYamlFilepath := path.Join(packageData.GetRepositoryPackageRootPath(), consts.DefaultKurtosisYamlFilename)
+
+	kurtosisYamlFileContent, err := validPackageDescriptionRule.packageSourceReader.ReadFile(ctx, repository, kurtosisYamlFilepath)
+	if err != nil {
+		logrus.Debugf("the '%s' file of package '%s' could not be read, it's reported by the '%s' rule", consts.DefaultKurtosisYamlFilename, packageName, validPackageRuleName)
+		return []*Failure{}
+	}
+	kurtosisYamlNode := &yaml.Node{}
+	if err := yaml.Unmarshal(kurtosisYamlFileContent, kurtosisYamlNode); err != nil {
+		logrus.Debugf("the '%s' file of package '%s' is not valid YAML, it's reported by the '%s' rule", consts.DefaultKurtosisYamlFilename, packageName, validPackageManifestRuleName)
+		return []*Failure{}
+	}
+
+	descriptionNode := yamlnode.FindMappingValueNode(kurtosisYamlNode, kurtosisYamlDescriptionKey)
+	if descriptionNode == nil || descriptionNode.Kind != yaml.ScalarNode || strings.TrimSpace(descriptionNode.Value) == "" {
+		return []*Failure{newFailure(
+			descriptionMissingFailureCode,
+			kurtosisYamlFilepath,
+			"the package does not have a description, it's shown in the catalog UI",
+			fmt.Sprintf("add a '%s' field explaining what the package runs and how to use it", kurtosisYamlDescriptionKey),
+		)}
+	}
+
+	packageFailures := []*Failure{}
+	for _, descriptionFailure := range validPackageDescriptionRule.checkDescription(kurtosisYamlFilepath, descriptionNode.Value) {
+		packageFailures = append(packageFailures, descriptionFailure.withPosition(descriptionNode.Line, descriptionNode.Column))
+	}
+	if len(packageFailures) == 0 {
+		logrus.Debugf("...description of package '%s' successfully validated.", packageName)
+	}
+	return packageFailures
+}
+
+func (validPackageDescriptionRule *validPackageDescriptionRule) checkDescription(kurtosisYamlFilepath string, description string) []*Failure {
+	descriptionFailures := []*Failure{}
+
+	trimmedDescription := strings.TrimSpace(description)
+	descriptionLength := utf8.RuneCountInString(trimmedDescription)
+	if descriptionLength < validPackageDescriptionRule.minLength {
+		descriptionFailures = append(descriptionFailures, newFailure(
+			descriptionTooShortFailureCode,
+			kurtosisYamlFilepath,
+			fmt.Sprintf("the description has %d characters, it has to have at least %d", descriptionLength, validPackageDescriptionRule.minLength),
+			"explain what the package runs and how to use it",
+		))
+	}
+	if descriptionLength > validPackageDescriptionRule.maxLength {
+		descriptionFailures = append(descriptionFailures, newFailure(
+			descriptionTooLongFailureCode,
+			kurtosisYamlFilepath,
+			fmt.Sprintf("the description has %d characters, it can have at most %d", descriptionLength, validPackageDescriptionRule.maxLength),
+			"move the details to the README file of the package",
+		))
+	}
+
+	for placeholderIdx, placeholderRegex := range validPackageDescriptionRule.placeholderRegexes {
+		if placeholderRegex.MatchString(trimmedDescription) {
+			descriptionFailures = append(descriptionFailures, newFailure(
+				descriptionPlaceholderFailureCode,
+				kurtosisYamlFilepath,
+				fmt.Sprintf("the description contains the template placeholder '%s'", validPackageDescriptionRule.placeholders[placeholderIdx]),
+				"replace the placeholder with the description of the package",
+			))
+			break
+		}
+	}
+
+	descriptionWithoutCode, hasUnclosedCodeBlock := removeMarkdownCode(description)
+	if hasUnclosedCodeBlock {
+		descriptionFailures = append(descriptionFailures, newFailure(
+			descriptionInvalidMarkdownFailureCode,
+			kurtosisYamlFilepath,
+			fmt.Sprintf("the description has a code block that isn't closed with '%s'", markdownCodeFence),
+			fmt.Sprintf("close the code block with a '%s' line", markdownCodeFence),
+		))
+	}
+	if brokenLink := findUnclosedMarkdownLink(descriptionWithoutCode); brokenLink != "" {
+		descriptionFailures = append(descriptionFailures, newFailure(
+			descriptionInvalidMarkdownFailureCode,
+			kurtosisYamlFilepath,
+			fmt.Sprintf("the description has a link whose URL isn't closed: '%s'", strings.TrimSpace(brokenLink)),
+			"close the link URL with ')', like '[text](https://example.com)'",
+		))
+	}
+	if markdownEmptyLinkRegex.MatchString(descriptionWithoutCode) {
+		descriptionFailures = append(descriptionFailures, newFailure(
+			descriptionInvalidMarkdownFailureCode,
+			kurtosisYamlFilepath,
+			"the description has a link without URL",
+			"add the URL to the link, like '[text](https://example.com)'",
+		))
+	}
+
+	if htmlTag := htmlTagRegex.FindString(descriptionWithoutCode); htmlTag != "" {
+		descriptionFailures = append(descriptionFailures, newFailure(
+			descriptionRawHtmlFailureCode,
+			kurtosisYamlFilepath,
+			fmt.Sprintf("the description contains raw HTML like '%s', which the catalog UI doesn't render", htmlTag),
+			"use Markdown instead of HTML, or put the HTML in a code span if it's an example",
+		))
+	}
+	return descriptionFailures
+}
+
+// newPlaceholderRegex returns the regex matching the placeholder as whole words, without case, so 'todo' is found in
+// 'TODO: describe' but not in 'a todolist app'
+func newPlaceholderRegex(placeholder string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(placeholder) + `\b`)
+}
+
+// findUnclosedMarkdownLink returns the start of the first link whose destination isn't closed with ')', e.g.
+// '](https://docs.kurtosis.com', or an empty string if all the links are closed
+func findUnclosedMarkdownLink(markdown string) string {
+	remainingMarkdown := markdown
+	for {
+		destinationStartIdx := strings.Index(remainingMarkdown, markdownLinkDestinationStart)
+		if destinationStartIdx < 0 {
+			return ""
+		}
+		remainingMarkdown = remainingMarkdown[destinationStartIdx+len(markdownLinkDestinationStart):]
+		if !markdownLinkDestinationRegex.MatchString(remainingMarkdown) {
+			unclosedDestination := ""
+			if destinationFields := strings.Fields(remainingMarkdown); len(destinationFields) > 0 {
+				unclosedDestination = destinationFields[0]
+			}
+			return markdownLinkDestinationStart + unclosedDestination
+		}
+	}
+}
+
+// removeMarkdownCode returns the Markdown without its code blocks and code spans, whose content isn't Markdown nor
+// HTML, and true if the last code block isn't closed
+func removeMarkdownCode(markdown string) (string, bool) {
+	linesWithoutCodeBlocks := []string{}
+	isInCodeBlock := false
+	for _, line := range strings.Split(markdown, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), markdownCodeFence) {
+			isInCodeBlock = !isInCodeBlock
+			continue
+		}
+		if !isInCodeBlock {
+			linesWithoutCodeBlocks = append(linesWithoutCodeBlocks, line)
+		}
+	}
+	markdownWithoutCodeBlocks := strings.Join(linesWithoutCodeBlocks, "\n")
+	return markdownCodeSpanRegex.ReplaceAllString(markdownWithoutCodeBlocks, ""), isInCodeBlock
+}
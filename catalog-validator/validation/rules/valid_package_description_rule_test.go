@@ -0,0 +1,121 @@
+package rules
+
+import (
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/source"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/consts"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestValidPackageDescriptionRule_CheckPackage(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		kurtosisYamlContent  string
+		expectedFailureCodes []FailureCode
+	}{
+		{
+			name:                 "valid description",
+			kurtosisYamlContent:  "name: github.com/foo/bar\ndescription: \"Runs a Postgres database with the [docs](https://docs.kurtosis.com) example\"\n",
+			expectedFailureCodes: []FailureCode{},
+		},
+		{
+			name:                 "missing description",
+			kurtosisYamlContent:  "name: github.com/foo/bar\n",
+			expectedFailureCodes: []FailureCode{descriptionMissingFailureCode},
+		},
+		{
+			name:                 "blank description",
+			kurtosisYamlContent:  "name: github.com/foo/bar\ndescription: \"   \"\n",
+			expectedFailureCodes: []FailureCode{descriptionMissingFailureCode},
+		},
+		{
+			name:                 "too short description",
+			kurtosisYamlContent:  "name: github.com/foo/bar\ndescription: Postgres\n",
+			expectedFailureCodes: []FailureCode{descriptionTooShortFailureCode},
+		},
+		{
+			name:                 "too long description",
+			kurtosisYamlContent:  "name: github.com/foo/bar\ndescription: " + strings.Repeat("a", defaultMaxDescriptionLength+1) + "\n",
+			expectedFailureCodes: []FailureCode{descriptionTooLongFailureCode},
+		},
+		{
+			name:                 "placeholder description",
+			kurtosisYamlContent:  "name: github.com/foo/bar\ndescription: \"Enter description here, it runs Postgres\"\n",
+			expectedFailureCodes: []FailureCode{descriptionPlaceholderFailureCode},
+		},
+		{
+			name:                 "placeholder inside a word",
+			kurtosisYamlContent:  "name: github.com/foo/bar\ndescription: \"Runs a todolist app backed by Postgres\"\n",
+			expectedFailureCodes: []FailureCode{},
+		},
+		{
+			name:                 "unclosed code block",
+			kurtosisYamlContent:  "name: github.com/foo/bar\ndescription: |\n  Runs a Postgres database\n  ```\n  kurtosis run github.com/foo/bar\n",
+			expectedFailureCodes: []FailureCode{descriptionInvalidMarkdownFailureCode},
+		},
+		{
+			name:                 "unclosed link",
+			kurtosisYamlContent:  "name: github.com/foo/bar\ndescription: \"Runs a Postgres database, see the [docs](https://docs.kurtosis.com\"\n",
+			expectedFailureCodes: []FailureCode{descriptionInvalidMarkdownFailureCode},
+		},
+		{
+			name:                 "unclosed link with a title",
+			kurtosisYamlContent:  "name: github.com/foo/bar\ndescription: 'Runs a Postgres database, see the [docs](https://docs.kurtosis.com \"Docs\"'\n",
+			expectedFailureCodes: []FailureCode{descriptionInvalidMarkdownFailureCode},
+		},
+		{
+			name:                 "links with titles",
+			kurtosisYamlContent:  "name: github.com/foo/bar\ndescription: 'Runs a Postgres database, see the [docs](https://docs.kurtosis.com \"Docs\") and [guide](https://kurtosis.com ''Guide'')'\n",
+			expectedFailureCodes: []FailureCode{},
+		},
+		{
+			name:                 "links with parentheses and angle brackets",
+			kurtosisYamlContent:  "name: github.com/foo/bar\ndescription: \"Runs a [Postgres](https://en.wikipedia.org/wiki/PostgreSQL_(database)) database, see the [docs](<https://docs.kurtosis.com/my docs>)\"\n",
+			expectedFailureCodes: []FailureCode{},
+		},
+		{
+			name:                 "link without URL",
+			kurtosisYamlContent:  "name: github.com/foo/bar\ndescription: \"Runs a Postgres database, see the [docs]()\"\n",
+			expectedFailureCodes: []FailureCode{descriptionInvalidMarkdownFailureCode},
+		},
+		{
+			name:                 "raw HTML",
+			kurtosisYamlContent:  "name: github.com/foo/bar\ndescription: \"Runs a <b>Postgres</b> database\"\n",
+			expectedFailureCodes: []FailureCode{descriptionRawHtmlFailureCode},
+		},
+		{
+			name:                 "HTML in a code span and an autolink",
+			kurtosisYamlContent:  "name: github.com/foo/bar\ndescription: \"Runs a Postgres database, see `<b>` and <https://docs.kurtosis.com>\"\n",
+			expectedFailureCodes: []FailureCode{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			packageSourceReader := source.NewInMemoryPackageSourceReader()
+			packageSourceReader.AddFile(testRepositoryOwner, testRepositoryName, consts.DefaultKurtosisYamlFilename, []byte(testCase.kurtosisYamlContent))
+			descriptionRule := newValidPackageDescriptionRule(packageSourceReader, defaultMinDescriptionLength, defaultMaxDescriptionLength, defaultDescriptionPlaceholders)
+
+			failures := checkTestPackage(t, descriptionRule, getTestCatalogYaml(testPackageName))
+			require.Equal(t, testCase.expectedFailureCodes, getFailureCodes(failures))
+		})
+	}
+}
+
+func TestValidPackageDescriptionRule_CheckPackage_InvalidManifest(t *testing.T) {
+	packageSourceReader := source.NewInMemoryPackageSourceReader()
+	packageSourceReader.AddFile(testRepositoryOwner, testRepositoryName, consts.DefaultKurtosisYamlFilename, []byte("name: [github.com/foo/bar\n"))
+	descriptionRule := newValidPackageDescriptionRule(packageSourceReader, defaultMinDescriptionLength, defaultMaxDescriptionLength, defaultDescriptionPlaceholders)
+
+	failures := checkTestPackage(t, descriptionRule, getTestCatalogYaml(testPackageName))
+	require.Empty(t, failures)
+}
+
+func TestFindUnclosedMarkdownLink(t *testing.T) {
+	require.Equal(t, "", findUnclosedMarkdownLink("see the [docs](https://docs.kurtosis.com \"Docs\")"))
+	require.Equal(t, "", findUnclosedMarkdownLink("see the [docs]( https://docs.kurtosis.com )"))
+	require.Equal(t, "](https://docs.kurtosis.com", findUnclosedMarkdownLink("see the [docs](https://docs.kurtosis.com and more"))
+	require.Equal(t, "](https://docs.kurtosis.com", findUnclosedMarkdownLink("see the [guide](https://kurtosis.com) and [docs](https://docs.kurtosis.com"))
+	require.Equal(t, "](", findUnclosedMarkdownLink("see the [docs]("))
+}
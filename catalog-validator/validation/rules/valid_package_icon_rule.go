@@ -1,140 +1,167 @@
 package rules
 
 import (
+	"bytes"
 	"context"
-	"errors"
 	"fmt"
-	"github.com/google/go-github/v54/github"
-	"github.com/kurtosis-tech/kurtosis-package-indexer/server/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/source"
 	"github.com/kurtosis-tech/kurtosis-package-indexer/server/consts"
 	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
 	"github.com/kurtosis-tech/stacktrace"
 	"github.com/sirupsen/logrus"
 	"image"
 	_ "image/png" // need to import it to get the PNG Encoder/Decoder
-	"net/http"
 	"path"
-	"strings"
 )
 
 const (
 	validPackageIconRuleName = "Valid package icon"
-	minImageSize             = 120
-	maxImageSize             = 1024
+	defaultMinImageSize      = 120
+	defaultMaxImageSize      = 1024
+
+	iconNotFoundFailureCode  FailureCode = "ICON_NOT_FOUND"
+	invalidIconFailureCode   FailureCode = "ICON_INVALID"
+	iconTooSmallFailureCode  FailureCode = "ICON_TOO_SMALL"
+	iconTooLargeFailureCode  FailureCode = "ICON_TOO_LARGE"
+	iconNotSquareFailureCode FailureCode = "ICON_NOT_SQUARE"
 )
 
+// validPackageIconRuleParameters are the parameters of the rule that can be set in the rules config
+type validPackageIconRuleParameters struct {
+	MinSize int `yaml:"min-size"`
+	MaxSize int `yaml:"max-size"`
+}
+
 // validPackageIconRule checks if the package icon is valid by checking if:
-// 1- if the png image exist, does not return an error if it not because it's not mandatory yet
+// 1- if the png image exist, it's only a warning if it not because it's not mandatory yet
 // 2- if the image size is equal or bigger that the minImageSize
 // 3- if the image size is equal or greater than maxImageSize
 // 4- if the aspect ratio is 1:1 (a square image)
 type validPackageIconRule struct {
-	name         string
-	gitHubClient *github.Client
+	name                string
+	packageSourceReader source.PackageSourceReader
+	minImageSize        int
+	maxImageSize        int
+}
+
+func newValidPackageIconRule(packageSourceReader source.PackageSourceReader, minImageSize int, maxImageSize int) *validPackageIconRule {
+	return &validPackageIconRule{name: validPackageIconRuleName, packageSourceReader: packageSourceReader, minImageSize: minImageSize, maxImageSize: maxImageSize}
 }
 
-func newValidPackageIconRule(gitHubClient *github.Client) *validPackageIconRule {
-	return &validPackageIconRule{name: validPackageIconRuleName, gitHubClient: gitHubClient}
+// newValidPackageIconRuleFromConfig returns the rule with the image size bounds set in the rules config, or the default ones
+func newValidPackageIconRuleFromConfig(packageSourceReader source.PackageSourceReader, rulesConfig *RulesConfig) (*validPackageIconRule, error) {
+	parameters := &validPackageIconRuleParameters{MinSize: defaultMinImageSize, MaxSize: defaultMaxImageSize}
+	if err := rulesConfig.decodeRuleParameters(validPackageIconRuleName, parameters); err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred getting the parameters of rule '%s'", validPackageIconRuleName)
+	}
+	if parameters.MinSize <= 0 || parameters.MinSize > parameters.MaxSize {
+		return nil, stacktrace.NewError("expected the 'min-size' parameter of rule '%s' to be positive and not bigger than 'max-size', but they are '%d' and '%d'", validPackageIconRuleName, parameters.MinSize, parameters.MaxSize)
+	}
+	return newValidPackageIconRule(packageSourceReader, parameters.MinSize, parameters.MaxSize), nil
 }
 
 func (validPackageIconRule *validPackageIconRule) GetName() RuleName {
 	return RuleName(validPackageIconRule.name)
 }
 
+func (validPackageIconRule *validPackageIconRule) GetDescription() string {
+	return "checks that the package icon is a square PNG image within the size bounds, the icon isn't mandatory yet"
+}
+
+func (validPackageIconRule *validPackageIconRule) GetParameters() map[string]interface{} {
+	return map[string]interface{}{
+		"min-size": validPackageIconRule.minImageSize,
+		"max-size": validPackageIconRule.maxImageSize,
+	}
+}
+
+func (validPackageIconRule *validPackageIconRule) GetDefaultSeverity() Severity {
+	return SeverityError
+}
+
 func (validPackageIconRule *validPackageIconRule) Check(ctx context.Context, catalog catalog.PackageCatalog) *CheckResult {
+	return checkEachPackage(ctx, validPackageIconRule, catalog)
+}
 
-	wasValidated := true
-	failures := map[types.PackageName][]string{}
-
-	for _, packageData := range catalog {
-		packageName := packageData.GetPackageName()
-		logrus.Debugf("Checking if package '%s' contains a valid icon...", packageName)
-		repositoryOwner := packageData.GetRepositoryOwner()
-		repositoryName := packageData.GetRepositoryName()
-		repositoryPackageRootPath := packageData.GetRepositoryPackageRootPath()
-		packageFailures := []string{}
-		packageIconImageConfig, err := validPackageIconRule.getPackageIconImageConfig(ctx, packageName, repositoryOwner, repositoryName, repositoryPackageRootPath)
-		if err != nil {
-			errorFailure := fmt.Sprintf("an error occurred getting the Kurtosis package icon image config for package '%s'. Error was:\n%s", packageName, err.Error())
-			packageFailures = append(packageFailures, errorFailure)
-		}
-		if err == nil && packageIconImageConfig == nil {
-			logrus.Debugf("package '%s' does not have an icon yet.", packageName)
-			continue
-		}
-		if err == nil {
-			packageIconWidth := packageIconImageConfig.Width
-			packageIconHeight := packageIconImageConfig.Height
-
-			if packageIconWidth < minImageSize || packageIconHeight < minImageSize {
-				invalidMinSizeMsg := fmt.Sprintf(
-					"invalid image min size, it is smaller than expected. "+
-						"Valid min value is '%dpx' and the current size is width: %dpx and height: %dpx",
-					minImageSize,
-					packageIconWidth,
-					packageIconHeight,
-				)
-				packageFailures = append(packageFailures, invalidMinSizeMsg)
-			}
-
-			if packageIconWidth > maxImageSize || packageIconHeight > maxImageSize {
-				invalidMaxSizeMsg := fmt.Sprintf(
-					"invalid image max size, it is bigger than expected. "+
-						"Valid max value is '%dpx' and the current size is width: %dpx and height: %dpx",
-					maxImageSize,
-					packageIconWidth,
-					packageIconHeight,
-				)
-				packageFailures = append(packageFailures, invalidMaxSizeMsg)
-			}
-
-			if packageIconWidth != packageIconHeight {
-				invalidAspectRatioMsg := "invalid aspect ratio, the accepted aspect ration is 1:1 (a square image)."
-
-				packageFailures = append(packageFailures, invalidAspectRatioMsg)
-			}
+func (validPackageIconRule *validPackageIconRule) CheckPackage(ctx context.Context, packageData PackageData) []*Failure {
+	packageName := packageData.GetPackageName()
+	logrus.Debugf("Checking if package '%s' contains a valid icon...", packageName)
+	repository := source.NewPackageRepositoryFromPackageData(packageData)
+	packageIconFilepath := path.Join(packageData.GetRepositoryPackageRootPath(), consts.KurtosisPackageIconImgName)
+	packageFailures := []*Failure{}
+	packageIconImageConfig, err := validPackageIconRule.getPackageIconImageConfig(ctx, packageName, repository, packageIconFilepath)
+	if err != nil {
+		errorFailure := newFailure(
+			invalidIconFailureCode,
+			packageIconFilepath,
+			fmt.Sprintf("an error occurred getting the Kurtosis package icon image config for package '%s'. Error was:\n%s", packageName, err.Error()),
+			fmt.Sprintf("check that '%s' is a valid PNG image", consts.KurtosisPackageIconImgName),
+		)
+		packageFailures = append(packageFailures, errorFailure)
+	}
+	if err == nil && packageIconImageConfig == nil {
+		logrus.Debugf("package '%s' does not have an icon yet.", packageName)
+		iconNotFoundFailure := newFailure(
+			iconNotFoundFailureCode,
+			packageIconFilepath,
+			"the package does not have an icon, it's not mandatory yet but it will be",
+			fmt.Sprintf("add a square PNG image between %dpx and %dpx named '%s' next to the '%s' file", validPackageIconRule.minImageSize, validPackageIconRule.maxImageSize, consts.KurtosisPackageIconImgName, consts.DefaultKurtosisYamlFilename),
+		).withSeverity(SeverityWarning)
+		packageFailures = append(packageFailures, iconNotFoundFailure)
+		return packageFailures
+	}
+	if err == nil {
+		packageIconWidth := packageIconImageConfig.Width
+		packageIconHeight := packageIconImageConfig.Height
+
+		if packageIconWidth < validPackageIconRule.minImageSize || packageIconHeight < validPackageIconRule.minImageSize {
+			invalidMinSizeMsg := fmt.Sprintf(
+				"invalid image min size, it is smaller than expected. "+
+					"Valid min value is '%dpx' and the current size is width: %dpx and height: %dpx",
+				validPackageIconRule.minImageSize,
+				packageIconWidth,
+				packageIconHeight,
+			)
+			packageFailures = append(packageFailures, newFailure(iconTooSmallFailureCode, packageIconFilepath, invalidMinSizeMsg, fmt.Sprintf("resize the icon to at least %dx%dpx", validPackageIconRule.minImageSize, validPackageIconRule.minImageSize)))
 		}
 
-		if len(packageFailures) > 0 {
-			failures[packageName] = packageFailures
-			wasValidated = false
-			continue
+		if packageIconWidth > validPackageIconRule.maxImageSize || packageIconHeight > validPackageIconRule.maxImageSize {
+			invalidMaxSizeMsg := fmt.Sprintf(
+				"invalid image max size, it is bigger than expected. "+
+					"Valid max value is '%dpx' and the current size is width: %dpx and height: %dpx",
+				validPackageIconRule.maxImageSize,
+				packageIconWidth,
+				packageIconHeight,
+			)
+			packageFailures = append(packageFailures, newFailure(iconTooLargeFailureCode, packageIconFilepath, invalidMaxSizeMsg, fmt.Sprintf("resize the icon to at most %dx%dpx", validPackageIconRule.maxImageSize, validPackageIconRule.maxImageSize)))
 		}
-		logrus.Debugf("...package icon for '%s' successfully validated.", packageName)
-	}
 
-	checkResult := newCheckResult(validPackageIconRule.GetName(), wasValidated, failures)
+		if packageIconWidth != packageIconHeight {
+			invalidAspectRatioMsg := "invalid aspect ratio, the accepted aspect ration is 1:1 (a square image)."
 
-	return checkResult
-}
+			packageFailures = append(packageFailures, newFailure(iconNotSquareFailureCode, packageIconFilepath, invalidAspectRatioMsg, "crop or pad the icon so its width and height are the same"))
+		}
+	}
 
-func (validPackageIconRule *validPackageIconRule) getPackageIconImageConfig(ctx context.Context, packageName types.PackageName, repositoryOwner string, repositoryName string, repositoryPackageRootPath string) (*image.Config, error) {
-	packageIconFilepath := path.Join(repositoryPackageRootPath, consts.KurtosisPackageIconImgName)
-	repoGetContentOpts := &github.RepositoryContentGetOptions{
-		Ref: "",
+	if len(packageFailures) == 0 {
+		logrus.Debugf("...package icon for '%s' successfully validated.", packageName)
 	}
+	return packageFailures
+}
 
-	// get contents of kurtosis package icon file from GitHub
-	packageIconFileContentResult, _, resp, err := validPackageIconRule.gitHubClient.Repositories.GetContents(ctx, repositoryOwner, repositoryName, packageIconFilepath, repoGetContentOpts)
+func (validPackageIconRule *validPackageIconRule) getPackageIconImageConfig(ctx context.Context, packageName types.PackageName, repository *source.PackageRepository, packageIconFilepath string) (*image.Config, error) {
+	// get contents of kurtosis package icon file from the package repository
+	packageIconFileContent, err := validPackageIconRule.packageSourceReader.ReadFile(ctx, repository, packageIconFilepath)
 	if err != nil {
-		if resp != nil && resp.StatusCode == http.StatusNotFound {
+		if source.IsFileNotFoundErr(err) {
 			// having the icon is not mandatory
 			return nil, nil
 		}
-		errMsj := fmt.Sprintf("an error occurred reading content of Kurtosis Package '%s' - file '%s'", packageName, packageIconFilepath)
-		if errors.Is(err, &github.RateLimitError{}) {
-			errMsj = "GitHub API rate limit exceeded."
-			logrus.Errorf("%s Error is:\n%v", errMsj, err.Error())
-		}
-		return nil, stacktrace.Propagate(err, errMsj)
-	}
-
-	rawPackageIconContentStr, err := packageIconFileContentResult.GetContent()
-	if err != nil {
-		return nil, stacktrace.Propagate(err, "an error occurred getting the '%s' base 64 file content in package '%s'", packageIconFilepath, packageName)
+		return nil, stacktrace.Propagate(err, "an error occurred reading content of Kurtosis Package '%s' - file '%s'", packageName, packageIconFilepath)
 	}
 
-	packageIconContentReader := strings.NewReader(rawPackageIconContentStr)
+	packageIconContentReader := bytes.NewReader(packageIconFileContent)
 
 	packageIconConfig, _, err := image.DecodeConfig(packageIconContentReader)
 	if err != nil {
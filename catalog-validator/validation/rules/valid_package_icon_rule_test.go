@@ -0,0 +1,119 @@
+package rules
+
+import (
+	"bytes"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/source"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/consts"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/png"
+	"path"
+	"testing"
+)
+
+func TestValidPackageIconRule_CheckPackage(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		packageName          string
+		iconFilepath         string
+		iconContent          []byte
+		expectedFailureCodes []FailureCode
+	}{
+		{
+			name:                 "valid icon",
+			packageName:          testPackageName,
+			iconFilepath:         consts.KurtosisPackageIconImgName,
+			iconContent:          getTestPngImage(t, 200, 200),
+			expectedFailureCodes: []FailureCode{},
+		},
+		{
+			name:                 "valid icon of a package in a subdirectory",
+			packageName:          testPackageName + "/packages/postgres",
+			iconFilepath:         path.Join("packages/postgres", consts.KurtosisPackageIconImgName),
+			iconContent:          getTestPngImage(t, 200, 200),
+			expectedFailureCodes: []FailureCode{},
+		},
+		{
+			name:                 "missing icon",
+			packageName:          testPackageName,
+			iconFilepath:         "",
+			iconContent:          nil,
+			expectedFailureCodes: []FailureCode{iconNotFoundFailureCode},
+		},
+		{
+			name:                 "icon in another package directory",
+			packageName:          testPackageName + "/packages/postgres",
+			iconFilepath:         consts.KurtosisPackageIconImgName,
+			iconContent:          getTestPngImage(t, 200, 200),
+			expectedFailureCodes: []FailureCode{iconNotFoundFailureCode},
+		},
+		{
+			name:                 "icon that isn't a PNG image",
+			packageName:          testPackageName,
+			iconFilepath:         consts.KurtosisPackageIconImgName,
+			iconContent:          []byte("not an image"),
+			expectedFailureCodes: []FailureCode{invalidIconFailureCode},
+		},
+		{
+			name:                 "too small icon",
+			packageName:          testPackageName,
+			iconFilepath:         consts.KurtosisPackageIconImgName,
+			iconContent:          getTestPngImage(t, 50, 50),
+			expectedFailureCodes: []FailureCode{iconTooSmallFailureCode},
+		},
+		{
+			name:                 "too large icon",
+			packageName:          testPackageName,
+			iconFilepath:         consts.KurtosisPackageIconImgName,
+			iconContent:          getTestPngImage(t, 2000, 2000),
+			expectedFailureCodes: []FailureCode{iconTooLargeFailureCode},
+		},
+		{
+			name:                 "icon that isn't square",
+			packageName:          testPackageName,
+			iconFilepath:         consts.KurtosisPackageIconImgName,
+			iconContent:          getTestPngImage(t, 200, 150),
+			expectedFailureCodes: []FailureCode{iconNotSquareFailureCode},
+		},
+		{
+			name:                 "too small icon that isn't square",
+			packageName:          testPackageName,
+			iconFilepath:         consts.KurtosisPackageIconImgName,
+			iconContent:          getTestPngImage(t, 200, 100),
+			expectedFailureCodes: []FailureCode{iconTooSmallFailureCode, iconNotSquareFailureCode},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			packageSourceReader := source.NewInMemoryPackageSourceReader()
+			if testCase.iconContent != nil {
+				packageSourceReader.AddFile(testRepositoryOwner, testRepositoryName, testCase.iconFilepath, testCase.iconContent)
+			}
+			iconRule := newValidPackageIconRule(packageSourceReader, defaultMinImageSize, defaultMaxImageSize)
+
+			failures := checkTestPackage(t, iconRule, getTestCatalogYaml(testCase.packageName))
+			require.Equal(t, testCase.expectedFailureCodes, getFailureCodes(failures))
+		})
+	}
+}
+
+func TestValidPackageIconRule_CheckPackage_MissingIconIsWarningUnlessOverridden(t *testing.T) {
+	iconRule := newValidPackageIconRule(source.NewInMemoryPackageSourceReader(), defaultMinImageSize, defaultMaxImageSize)
+
+	failures := checkTestPackage(t, iconRule, getTestCatalogYaml(testPackageName))
+	require.Len(t, failures, 1)
+	require.Equal(t, SeverityWarning, failures[0].GetSeverity())
+
+	checkResult := newCheckResult(validPackageIconRuleName, true, map[types.PackageName][]*Failure{testPackageName: failures})
+	require.Equal(t, SeverityWarning, checkResult.WithRuleSeverity(SeverityError).GetFailures()[testPackageName][0].GetSeverity())
+	require.Equal(t, SeverityError, checkResult.WithRuleSeverityOverride(SeverityError).GetFailures()[testPackageName][0].GetSeverity())
+}
+
+// getTestPngImage returns the content of a blank PNG image of the size
+func getTestPngImage(t *testing.T, width int, height int) []byte {
+	imageContent := &bytes.Buffer{}
+	require.NoError(t, png.Encode(imageContent, image.NewGray(image.Rect(0, 0, width, height))))
+	return imageContent.Bytes()
+}
@@ -0,0 +1,262 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/locator"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/source"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/consts"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	validPackageManifestRuleName = "Valid package manifest"
+
+	manifestInvalidYamlFailureCode     FailureCode = "MANIFEST_INVALID_YAML"
+	manifestUnknownFieldFailureCode    FailureCode = "MANIFEST_UNKNOWN_FIELD"
+	manifestDuplicatedFieldFailureCode FailureCode = "MANIFEST_DUPLICATED_FIELD"
+	manifestMissingFieldFailureCode    FailureCode = "MANIFEST_MISSING_FIELD"
+	manifestInvalidTypeFailureCode     FailureCode = "MANIFEST_INVALID_TYPE"
+	manifestInvalidLocatorFailureCode  FailureCode = "MANIFEST_INVALID_LOCATOR"
+
+	kurtosisYamlDescriptionKey = "description"
+	kurtosisYamlReplaceKey     = "replace"
+	kurtosisYamlPackagesKey    = "packages"
+
+	yamlStringTag = "!!str"
+
+	// localReplacePathPrefix is the prefix of the replace values that point to a package in the same repository
+	localReplacePathPrefix = "."
+)
+
+var (
+	// kurtosisYamlKnownKeys are the fields of the kurtosis.yml file, any other field is rejected
+	kurtosisYamlKnownKeys = []string{kurtosisYamlNameKey, kurtosisYamlDescriptionKey, kurtosisYamlReplaceKey, kurtosisYamlPackagesKey}
+
+	// yamlErrorLineRegex finds the line in the errors of the YAML parser, e.g. 'yaml: line 3: mapping values are not allowed in this context'
+	yamlErrorLineRegex = regexp.MustCompile(`line (\d+)`)
+)
+
+// validPackageManifestRule checks if the kurtosis.yml file follows the package manifest schema by checking if:
+// 1- it only contains the known fields, without duplicates
+// 2- 'name' is a well-formed 'github.com/owner/repository[/path]' locator
+// 3- 'description' is a string
+// 4- 'replace' maps package locators to package locators or to local paths
+// 5- 'packages' is a list of package locators
+// Every problem is reported with its position, the missing kurtosis.yml file is reported by the 'Valid package' rule
+type validPackageManifestRule struct {
+	name                string
+	packageSourceReader source.PackageSourceReader
+}
+
+func newValidPackageManifestRule(packageSourceReader source.PackageSourceReader) *validPackageManifestRule {
+	return &validPackageManifestRule{name: validPackageManifestRuleName, packageSourceReader: packageSourceReader}
+}
+
+func (validPackageManifestRule *validPackageManifestRule) GetName() RuleName {
+	return RuleName(validPackageManifestRule.name)
+}
+
+func (validPackageManifestRule *validPackageManifestRule) GetDescription() string {
+	return "checks that the kurtosis.yml file only has known fields with the right types, and that its name and package references are well-formed locators"
+}
+
+func (validPackageManifestRule *validPackageManifestRule) GetParameters() map[string]interface{} {
+	return map[string]interface{}{}
+}
+
+func (validPackageManifestRule *validPackageManifestRule) GetDefaultSeverity() Severity {
+	return SeverityError
+}
+
+func (validPackageManifestRule *validPackageManifestRule) Check(ctx context.Context, catalog catalog.PackageCatalog) *CheckResult {
+	return checkEachPackage(ctx, validPackageManifestRule, catalog)
+}
+
+func (validPackageManifestRule *validPackageManifestRule) CheckPackage(ctx context.Context, packageData PackageData) []*Failure {
+	packageName := packageData.GetPackageName()
+	logrus.Debugf("Checking if the manifest of package '%s' is valid...", packageName)
+	repository := source.NewPackageRepositoryFromPackageData(packageData)
+	kurtosisYamlFilepath := path.Join(packageData.GetRepositoryPackageRootPath(), consts.DefaultKurtosisYamlFilename)
+
+	kurtosisYamlFileContent, err := validPackageManifestRule.packageSourceReader.ReadFile(ctx, repository, kurtosisYamlFilepath)
+	if err != nil && source.IsFileNotFoundErr(err) {
+		logrus.Debugf("package '%s' does not have a manifest, it's reported by the '%s' rule", packageName, validPackageRuleName)
+		return []*Failure{}
+	} else if err != nil {
+		return []*Failure{newFailure(
+			manifestInvalidYamlFailureCode,
+			kurtosisYamlFilepath,
+			fmt.Sprintf("the '%s' file could not be read. Error was:\n%s", consts.DefaultKurtosisYamlFilename, err.Error()),
+			"",
+		)}
+	}
+
+	manifestChecker := newPackageManifestChecker(kurtosisYamlFilepath)
+	packageFailures := manifestChecker.checkManifest(kurtosisYamlFileContent)
+	if len(packageFailures) == 0 {
+		logrus.Debugf("...manifest of package '%s' successfully validated.", packageName)
+	}
+	return packageFailures
+}
+
+// packageManifestChecker collects the failures found in a kurtosis.yml file
+type packageManifestChecker struct {
+	kurtosisYamlFilepath string
+	failures             []*Failure
+}
+
+func newPackageManifestChecker(kurtosisYamlFilepath string) *packageManifestChecker {
+	return &packageManifestChecker{kurtosisYamlFilepath: kurtosisYamlFilepath, failures: []*Failure{}}
+}
+
+func (checker *packageManifestChecker) checkManifest(kurtosisYamlFileContent []byte) []*Failure {
+	documentNode := &yaml.Node{}
+	if err := yaml.Unmarshal(kurtosisYamlFileContent, documentNode); err != nil {
+		yamlFailure := newFailure(
+			manifestInvalidYamlFailureCode,
+			checker.kurtosisYamlFilepath,
+			fmt.Sprintf("the '%s' file is not a valid YAML file. Error was:\n%s", consts.DefaultKurtosisYamlFilename, err.Error()),
+			"fix the YAML syntax of the file",
+		)
+		if line := getYamlErrorLine(err); line != unknownPosition {
+			yamlFailure.withPosition(line, unknownPosition)
+		}
+		return []*Failure{yamlFailure}
+	}
+	if len(documentNode.Content) == 0 {
+		checker.addFailure(manifestMissingFieldFailureCode, documentNode, fmt.Sprintf("the '%s' file is empty", consts.DefaultKurtosisYamlFilename), fmt.Sprintf("add the '%s' field with the package locator", kurtosisYamlNameKey))
+		return checker.failures
+	}
+
+	rootNode := documentNode.Content[0]
+	if rootNode.Kind != yaml.MappingNode {
+		checker.addFailure(manifestInvalidTypeFailureCode, rootNode, fmt.Sprintf("the '%s' file has to be a mapping of fields", consts.DefaultKurtosisYamlFilename), fmt.Sprintf("write the fields like '%s: github.com/owner/repository'", kurtosisYamlNameKey))
+		return checker.failures
+	}
+
+	fieldValueNodes := map[string]*yaml.Node{}
+	for keyIndex := 0; keyIndex+1 < len(rootNode.Content); keyIndex += 2 {
+		keyNode := rootNode.Content[keyIndex]
+		valueNode := rootNode.Content[keyIndex+1]
+		if !isKnownKurtosisYamlKey(keyNode.Value) {
+			checker.addFailure(manifestUnknownFieldFailureCode, keyNode, fmt.Sprintf("unknown field '%s'", keyNode.Value), fmt.Sprintf("remove the field, the known fields are: %s", strings.Join(kurtosisYamlKnownKeys, ", ")))
+			continue
+		}
+		if _, found := fieldValueNodes[keyNode.Value]; found {
+			checker.addFailure(manifestDuplicatedFieldFailureCode, keyNode, fmt.Sprintf("the field '%s' is set more than once", keyNode.Value), "keep only one of them")
+			continue
+		}
+		fieldValueNodes[keyNode.Value] = valueNode
+	}
+
+	if nameNode, found := fieldValueNodes[kurtosisYamlNameKey]; !found {
+		checker.addFailure(manifestMissingFieldFailureCode, rootNode, fmt.Sprintf("the required field '%s' is missing", kurtosisYamlNameKey), fmt.Sprintf("add the '%s' field with the package locator", kurtosisYamlNameKey))
+	} else if checker.checkIsString(kurtosisYamlNameKey, nameNode) {
+		checker.checkLocator(kurtosisYamlNameKey, nameNode)
+	}
+	if descriptionNode, found := fieldValueNodes[kurtosisYamlDescriptionKey]; found {
+		checker.checkIsString(kurtosisYamlDescriptionKey, descriptionNode)
+	}
+	if replaceNode, found := fieldValueNodes[kurtosisYamlReplaceKey]; found {
+		checker.checkReplace(replaceNode)
+	}
+	if packagesNode, found := fieldValueNodes[kurtosisYamlPackagesKey]; found {
+		checker.checkPackages(packagesNode)
+	}
+	return checker.failures
+}
+
+// checkReplace checks that the replace field maps package locators to package locators, or to local paths like
+// '../other-package' for the packages in the same repository
+func (checker *packageManifestChecker) checkReplace(replaceNode *yaml.Node) {
+	if replaceNode.Kind != yaml.MappingNode {
+		checker.addFailure(manifestInvalidTypeFailureCode, replaceNode, fmt.Sprintf("the '%s' field has to be a mapping of package locators", kurtosisYamlReplaceKey), fmt.Sprintf("write the replacements like '%s: {github.com/owner/dependency: github.com/owner/fork}'", kurtosisYamlReplaceKey))
+		return
+	}
+	for keyIndex := 0; keyIndex+1 < len(replaceNode.Content); keyIndex += 2 {
+		replacedNode := replaceNode.Content[keyIndex]
+		replacementNode := replaceNode.Content[keyIndex+1]
+		replacedField := fmt.Sprintf("%s.%s", kurtosisYamlReplaceKey, replacedNode.Value)
+		checker.checkLocator(kurtosisYamlReplaceKey, replacedNode)
+		if !checker.checkIsString(replacedField, replacementNode) {
+			continue
+		}
+		if !strings.HasPrefix(replacementNode.Value, localReplacePathPrefix) {
+			checker.checkLocator(replacedField, replacementNode)
+		}
+	}
+}
+
+// checkPackages checks that the packages field is a list of package locators
+func (checker *packageManifestChecker) checkPackages(packagesNode *yaml.Node) {
+	if packagesNode.Kind != yaml.SequenceNode {
+		checker.addFailure(manifestInvalidTypeFailureCode, packagesNode, fmt.Sprintf("the '%s' field has to be a list of package locators", kurtosisYamlPackagesKey), fmt.Sprintf("write the packages like '%s: [github.com/owner/dependency]'", kurtosisYamlPackagesKey))
+		return
+	}
+	for _, packageNode := range packagesNode.Content {
+		if checker.checkIsString(kurtosisYamlPackagesKey, packageNode) {
+			checker.checkLocator(kurtosisYamlPackagesKey, packageNode)
+		}
+	}
+}
+
+// checkIsString returns true if the node is a string, otherwise it adds a failure
+func (checker *packageManifestChecker) checkIsString(field string, node *yaml.Node) bool {
+	if node.Kind == yaml.ScalarNode && node.Tag == yamlStringTag {
+		return true
+	}
+	checker.addFailure(manifestInvalidTypeFailureCode, node, fmt.Sprintf("the '%s' field has to be a string, but it's %s", field, getYamlNodeTypeDescription(node)), "quote the value if it's a string that YAML reads as another type")
+	return false
+}
+
+// checkLocator adds a failure if the node value isn't a well-formed locator
+func (checker *packageManifestChecker) checkLocator(field string, node *yaml.Node) {
+	if _, err := locator.ParseLocator(node.Value); err != nil {
+		checker.addFailure(manifestInvalidLocatorFailureCode, node, fmt.Sprintf("the '%s' field contains an invalid locator '%s': %#s", field, node.Value, err), "use a locator like 'github.com/owner/repository/path/to/package'")
+	}
+}
+
+func (checker *packageManifestChecker) addFailure(code FailureCode, node *yaml.Node, message string, remediation string) {
+	checker.failures = append(checker.failures, newFailure(code, checker.kurtosisYamlFilepath, message, remediation).withPosition(node.Line, node.Column))
+}
+
+func isKnownKurtosisYamlKey(key string) bool {
+	for _, knownKey := range kurtosisYamlKnownKeys {
+		if key == knownKey {
+			return true
+		}
+	}
+	return false
+}
+
+func getYamlNodeTypeDescription(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	case yaml.AliasNode:
+		return "an alias"
+	}
+	return fmt.Sprintf("'%s' with the YAML type '%s'", node.Value, strings.TrimPrefix(node.Tag, "!!"))
+}
+
+// getYamlErrorLine returns the line of the YAML parser error, or unknownPosition if the error doesn't contain it
+func getYamlErrorLine(err error) int {
+	lineMatch := yamlErrorLineRegex.FindStringSubmatch(err.Error())
+	if lineMatch == nil {
+		return unknownPosition
+	}
+	line, convErr := strconv.Atoi(lineMatch[1])
+	if convErr != nil {
+		return unknownPosition
+	}
+	return line
+}
@@ -0,0 +1,109 @@
+package rules
+
+import (
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/source"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/consts"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestValidPackageManifestRule_CheckPackage(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		kurtosisYamlContent  string
+		expectedFailureCodes []FailureCode
+	}{
+		{
+			name: "valid manifest",
+			kurtosisYamlContent: `name: "github.com/foo/bar"
+description: "Runs a Postgres database"
+replace:
+  github.com/foo/dependency: github.com/foo/fork
+  github.com/foo/other: ../other
+packages:
+  - github.com/foo/dependency
+`,
+			expectedFailureCodes: []FailureCode{},
+		},
+		{
+			name:                 "invalid YAML",
+			kurtosisYamlContent:  "name: [github.com/foo/bar\n",
+			expectedFailureCodes: []FailureCode{manifestInvalidYamlFailureCode},
+		},
+		{
+			name:                 "empty manifest",
+			kurtosisYamlContent:  "",
+			expectedFailureCodes: []FailureCode{manifestMissingFieldFailureCode},
+		},
+		{
+			name:                 "manifest that isn't a mapping",
+			kurtosisYamlContent:  "- github.com/foo/bar\n",
+			expectedFailureCodes: []FailureCode{manifestInvalidTypeFailureCode},
+		},
+		{
+			name:                 "missing name",
+			kurtosisYamlContent:  "description: \"Runs a Postgres database\"\n",
+			expectedFailureCodes: []FailureCode{manifestMissingFieldFailureCode},
+		},
+		{
+			name:                 "unknown and duplicated fields",
+			kurtosisYamlContent:  "name: github.com/foo/bar\nversion: 1\nname: github.com/foo/bar\n",
+			expectedFailureCodes: []FailureCode{manifestUnknownFieldFailureCode, manifestDuplicatedFieldFailureCode},
+		},
+		{
+			name:                 "description that isn't a string",
+			kurtosisYamlContent:  "name: github.com/foo/bar\ndescription: 42\n",
+			expectedFailureCodes: []FailureCode{manifestInvalidTypeFailureCode},
+		},
+		{
+			name:                 "invalid name locator",
+			kurtosisYamlContent:  "name: gitlab.com/foo/bar\n",
+			expectedFailureCodes: []FailureCode{manifestInvalidLocatorFailureCode},
+		},
+		{
+			name:                 "replace that isn't a mapping",
+			kurtosisYamlContent:  "name: github.com/foo/bar\nreplace: github.com/foo/fork\n",
+			expectedFailureCodes: []FailureCode{manifestInvalidTypeFailureCode},
+		},
+		{
+			name:                 "replace with invalid locators",
+			kurtosisYamlContent:  "name: github.com/foo/bar\nreplace:\n  dependency: github.com/foo/fork\n  github.com/foo/dependency: fork\n",
+			expectedFailureCodes: []FailureCode{manifestInvalidLocatorFailureCode, manifestInvalidLocatorFailureCode},
+		},
+		{
+			name:                 "packages that aren't a list of locators",
+			kurtosisYamlContent:  "name: github.com/foo/bar\npackages:\n  - 42\n  - dependency\n",
+			expectedFailureCodes: []FailureCode{manifestInvalidTypeFailureCode, manifestInvalidLocatorFailureCode},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			packageSourceReader := source.NewInMemoryPackageSourceReader()
+			packageSourceReader.AddFile(testRepositoryOwner, testRepositoryName, consts.DefaultKurtosisYamlFilename, []byte(testCase.kurtosisYamlContent))
+			manifestRule := newValidPackageManifestRule(packageSourceReader)
+
+			failures := checkTestPackage(t, manifestRule, getTestCatalogYaml(testPackageName))
+			require.Equal(t, testCase.expectedFailureCodes, getFailureCodes(failures))
+		})
+	}
+}
+
+func TestValidPackageManifestRule_CheckPackage_FailurePosition(t *testing.T) {
+	packageSourceReader := source.NewInMemoryPackageSourceReader()
+	packageSourceReader.AddFile(testRepositoryOwner, testRepositoryName, consts.DefaultKurtosisYamlFilename, []byte("name: github.com/foo/bar\ndescription: 42\n"))
+	manifestRule := newValidPackageManifestRule(packageSourceReader)
+
+	failures := checkTestPackage(t, manifestRule, getTestCatalogYaml(testPackageName))
+	require.Len(t, failures, 1)
+	require.Equal(t, consts.DefaultKurtosisYamlFilename, failures[0].GetFilepath())
+	require.Equal(t, 2, failures[0].GetLine())
+	require.Equal(t, 14, failures[0].GetColumn())
+}
+
+func TestValidPackageManifestRule_CheckPackage_MissingManifest(t *testing.T) {
+	manifestRule := newValidPackageManifestRule(source.NewInMemoryPackageSourceReader())
+
+	failures := checkTestPackage(t, manifestRule, getTestCatalogYaml(testPackageName))
+	require.Empty(t, failures)
+}
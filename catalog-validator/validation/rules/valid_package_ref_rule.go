@@ -0,0 +1,190 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/source"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	validPackageRefRuleName = "Valid package ref"
+
+	refInvalidFailureCode    FailureCode = "REF_INVALID"
+	refNotFoundFailureCode   FailureCode = "REF_NOT_FOUND"
+	refSHAInvalidFailureCode FailureCode = "REF_SHA_INVALID"
+	refSHAMissingFailureCode FailureCode = "REF_SHA_MISSING"
+	refMovedFailureCode      FailureCode = "REF_MOVED"
+
+	// RecordRefSHAsCommandName is the validator command writing the missing ref-sha fields in the catalog file, the
+	// failure hints point to it
+	RecordRefSHAsCommandName = "record-ref-shas"
+)
+
+var (
+	// commitSHARegex matches the full SHA-1 and SHA-256 commit SHAs
+	commitSHARegex = regexp.MustCompile(`^(?:[0-9a-f]{40}|[0-9a-f]{64})$`)
+)
+
+// validPackageRefRuleParameters are the parameters of the rule that can be set in the rules config
+type validPackageRefRuleParameters struct {
+	RequireRefSHA bool `yaml:"require-ref-sha"`
+}
+
+// validPackageRefRule checks the ref (branch, tag or commit SHA) the package is read at, when its catalog entry sets
+// one, by checking if:
+// 1- the ref exists in the package repository
+// 2- the recorded ref-sha is a full commit SHA and the ref still resolves to it, so a moved tag is detected
+// 3- the ref-sha is recorded, if requireRefSHA is set and the ref isn't already a commit SHA
+// The packages without a ref are read at the repository default branch, so there is nothing to check
+type validPackageRefRule struct {
+	name                string
+	packageSourceReader source.PackageSourceReader
+
+	// requireRefSHA makes the ref-sha mandatory for the refs that aren't commit SHAs, so every tag is immutable
+	requireRefSHA bool
+}
+
+func newValidPackageRefRule(packageSourceReader source.PackageSourceReader, requireRefSHA bool) *validPackageRefRule {
+	return &validPackageRefRule{name: validPackageRefRuleName, packageSourceReader: packageSourceReader, requireRefSHA: requireRefSHA}
+}
+
+// newValidPackageRefRuleFromConfig returns the rule with the ref-sha requirement set in the rules config, it isn't
+// required by default
+func newValidPackageRefRuleFromConfig(packageSourceReader source.PackageSourceReader, rulesConfig *RulesConfig) (*validPackageRefRule, error) {
+	parameters := &validPackageRefRuleParameters{RequireRefSHA: false}
+	if err := rulesConfig.decodeRuleParameters(validPackageRefRuleName, parameters); err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred getting the parameters of rule '%s'", validPackageRefRuleName)
+	}
+	return newValidPackageRefRule(packageSourceReader, parameters.RequireRefSHA), nil
+}
+
+func (validPackageRefRule *validPackageRefRule) GetName() RuleName {
+	return RuleName(validPackageRefRule.name)
+}
+
+func (validPackageRefRule *validPackageRefRule) GetDescription() string {
+	return "checks that the ref set in the package catalog entry exists in its repository and still resolves to the recorded ref-sha commit"
+}
+
+func (validPackageRefRule *validPackageRefRule) GetParameters() map[string]interface{} {
+	return map[string]interface{}{
+		"require-ref-sha": validPackageRefRule.requireRefSHA,
+	}
+}
+
+func (validPackageRefRule *validPackageRefRule) GetDefaultSeverity() Severity {
+	return SeverityError
+}
+
+func (validPackageRefRule *validPackageRefRule) Check(ctx context.Context, catalog catalog.PackageCatalog) *CheckResult {
+	return checkEachPackage(ctx, validPackageRefRule, catalog)
+}
+
+func (validPackageRefRule *validPackageRefRule) CheckPackage(ctx context.Context, packageData PackageData) []*Failure {
+	packageName := packageData.GetPackageName()
+	logrus.Debugf("Checking if the ref of package '%s' is valid...", packageName)
+	ref := packageData.GetRef()
+	refSHA := packageData.GetRefSHA()
+
+	if ref == "" {
+		if refSHA != "" {
+			return []*Failure{newFailure(
+				refSHAInvalidFailureCode,
+				"",
+				fmt.Sprintf("the catalog entry sets the '%s' field but not the '%s' field, so the package is read at the repository default branch", catalog.PackageRefSHAKey, catalog.PackageRefKey),
+				fmt.Sprintf("set the '%s' field with the tag the '%s' commit belongs to, or remove the '%s' field", catalog.PackageRefKey, catalog.PackageRefSHAKey, catalog.PackageRefSHAKey),
+			)}
+		}
+		logrus.Debugf("...package '%s' has no ref, it's read at the repository default branch.", packageName)
+		return []*Failure{}
+	}
+	if strings.IndexFunc(ref, unicode.IsSpace) >= 0 {
+		return []*Failure{newFailure(
+			refInvalidFailureCode,
+			"",
+			fmt.Sprintf("the ref '%s' in the catalog entry contains whitespaces, it can't be a branch, tag or commit SHA", ref),
+			"use the name of a branch or tag, or a full commit SHA",
+		)}
+	}
+	if refSHA != "" && !commitSHARegex.MatchString(refSHA) {
+		return []*Failure{newFailure(
+			refSHAInvalidFailureCode,
+			"",
+			fmt.Sprintf("the '%s' field '%s' in the catalog entry is not a full lowercase commit SHA", catalog.PackageRefSHAKey, refSHA),
+			fmt.Sprintf("set the '%s' field with the full commit SHA the ref resolves to, e.g. the output of 'git rev-parse %s^{commit}'", catalog.PackageRefSHAKey, ref),
+		)}
+	}
+
+	repository := source.NewPackageRepositoryFromPackageData(packageData)
+	resolvedSHA, err := validPackageRefRule.packageSourceReader.ResolveRef(ctx, repository)
+	if err != nil {
+		failureMsg := fmt.Sprintf("the ref '%s' could not be resolved in the package repository. Error was:\n%s", ref, err.Error())
+		if source.IsFileNotFoundErr(err) {
+			failureMsg = fmt.Sprintf("the ref '%s' does not exist in the package repository", ref)
+		}
+		return []*Failure{newFailure(
+			refNotFoundFailureCode,
+			"",
+			failureMsg,
+			fmt.Sprintf("check that the '%s' field is a branch, tag or commit SHA pushed to the package repository", catalog.PackageRefKey),
+		)}
+	}
+	if !commitSHARegex.MatchString(resolvedSHA) {
+		// the sources that aren't versioned, like the local directories that aren't git working trees, don't resolve the
+		// refs to commits
+		logrus.Debugf("...ref '%s' of package '%s' can't be checked because the package source resolved it to '%s' instead of a commit.", ref, packageName, resolvedSHA)
+		return []*Failure{}
+	}
+
+	if refSHA != "" && refSHA != resolvedSHA {
+		return []*Failure{newFailure(
+			refMovedFailureCode,
+			"",
+			fmt.Sprintf("the ref '%s' resolves to commit '%s', but it resolved to commit '%s' when it was recorded in the catalog entry", ref, resolvedSHA, refSHA),
+			fmt.Sprintf("a tag of a package in the catalog can't be moved, push a new tag and update the '%s' and '%s' fields", catalog.PackageRefKey, catalog.PackageRefSHAKey),
+		)}
+	}
+	isCommitSHARef := ref == resolvedSHA
+	if validPackageRefRule.requireRefSHA && refSHA == "" && !isCommitSHARef {
+		return []*Failure{newFailure(
+			refSHAMissingFailureCode,
+			"",
+			fmt.Sprintf("the catalog entry doesn't record the commit the ref '%s' resolves to, so a moved tag can't be detected", ref),
+			fmt.Sprintf("add the '%s: %s' field to the catalog entry, e.g. running the validator '%s' command, or set the '%s' field to a full commit SHA", catalog.PackageRefSHAKey, resolvedSHA, RecordRefSHAsCommandName, catalog.PackageRefKey),
+		)}
+	}
+
+	logrus.Debugf("...ref '%s' of package '%s' successfully validated.", ref, packageName)
+	return []*Failure{}
+}
+
+// ResolveMissingRefSHAs returns the commit SHA the ref of each catalog package resolves to, keyed by the package index
+// in the catalog, for the packages with a ref that isn't a commit SHA and without a recorded ref-sha, so they can be
+// recorded in the catalog file with catalog.AddPackageRefSHAs
+func ResolveMissingRefSHAs(ctx context.Context, packageSourceReader source.PackageSourceReader, packageCatalog catalog.PackageCatalog) (map[int]string, error) {
+	refSHAs := map[int]string{}
+	for packageIndex, catalogPackage := range packageCatalog {
+		ref := catalogPackage.GetRef()
+		if ref == "" || catalogPackage.GetRefSHA() != "" {
+			continue
+		}
+		resolvedSHA, err := packageSourceReader.ResolveRef(ctx, source.NewPackageRepositoryFromPackageData(catalogPackage))
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "an error occurred resolving ref '%s' of package '%s'", ref, catalogPackage.GetPackageName())
+		}
+		if !commitSHARegex.MatchString(resolvedSHA) {
+			return nil, stacktrace.NewError("expected ref '%s' of package '%s' to resolve to a commit SHA, but the package source resolved it to '%s'", ref, catalogPackage.GetPackageName(), resolvedSHA)
+		}
+		if resolvedSHA == ref {
+			continue
+		}
+		refSHAs[packageIndex] = resolvedSHA
+	}
+	return refSHAs, nil
+}
@@ -0,0 +1,187 @@
+package rules
+
+import (
+	"context"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/source"
+	"github.com/stretchr/testify/require"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	testRef          = "v1"
+	testRefSHA       = "0123456789abcdef0123456789abcdef01234567"
+	testMovedRefSHA  = "89abcdef0123456789abcdef0123456789abcdef"
+	testCommitSHARef = "fedcba9876543210fedcba9876543210fedcba98"
+)
+
+func TestValidPackageRefRule_CheckPackage(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		catalogYaml          string
+		requireRefSHA        bool
+		expectedFailureCodes []FailureCode
+	}{
+		{
+			name:                 "no ref",
+			catalogYaml:          getTestCatalogYaml(testPackageName),
+			requireRefSHA:        true,
+			expectedFailureCodes: []FailureCode{},
+		},
+		{
+			name:                 "ref-sha without ref",
+			catalogYaml:          getTestRefCatalogYaml("", testRefSHA),
+			expectedFailureCodes: []FailureCode{refSHAInvalidFailureCode},
+		},
+		{
+			name:                 "ref with whitespaces",
+			catalogYaml:          getTestRefCatalogYaml("v1 ", ""),
+			expectedFailureCodes: []FailureCode{refInvalidFailureCode},
+		},
+		{
+			name:                 "ref-sha that isn't a full commit SHA",
+			catalogYaml:          getTestRefCatalogYaml(testRef, "0123456"),
+			expectedFailureCodes: []FailureCode{refSHAInvalidFailureCode},
+		},
+		{
+			name:                 "ref not found",
+			catalogYaml:          getTestRefCatalogYaml("v2", ""),
+			expectedFailureCodes: []FailureCode{refNotFoundFailureCode},
+		},
+		{
+			name:                 "ref resolving to the recorded ref-sha",
+			catalogYaml:          getTestRefCatalogYaml(testRef, testRefSHA),
+			requireRefSHA:        true,
+			expectedFailureCodes: []FailureCode{},
+		},
+		{
+			name:                 "moved ref",
+			catalogYaml:          getTestRefCatalogYaml(testRef, testMovedRefSHA),
+			expectedFailureCodes: []FailureCode{refMovedFailureCode},
+		},
+		{
+			name:                 "ref-sha not required",
+			catalogYaml:          getTestRefCatalogYaml(testRef, ""),
+			expectedFailureCodes: []FailureCode{},
+		},
+		{
+			name:                 "ref-sha required",
+			catalogYaml:          getTestRefCatalogYaml(testRef, ""),
+			requireRefSHA:        true,
+			expectedFailureCodes: []FailureCode{refSHAMissingFailureCode},
+		},
+		{
+			name:                 "commit SHA ref without ref-sha",
+			catalogYaml:          getTestRefCatalogYaml(testCommitSHARef, ""),
+			requireRefSHA:        true,
+			expectedFailureCodes: []FailureCode{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			failures := checkTestPackage(t, newValidPackageRefRule(getTestRefsPackageSourceReader(), testCase.requireRefSHA), testCase.catalogYaml)
+			require.Equal(t, testCase.expectedFailureCodes, getFailureCodes(failures))
+		})
+	}
+}
+
+func TestValidPackageRefRule_CheckPackage_LocalSource(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("the 'git' binary is required to test the local source with a git working tree")
+	}
+	rootDirpath := t.TempDir()
+	repositoryDirpath := filepath.Join(rootDirpath, testRepositoryOwner, testRepositoryName)
+	require.NoError(t, os.MkdirAll(repositoryDirpath, 0755))
+	runTestGitCommand(t, repositoryDirpath, "init", "--quiet")
+	require.NoError(t, os.WriteFile(filepath.Join(repositoryDirpath, "kurtosis.yml"), []byte("name: "+testPackageName+"\n"), 0644))
+	runTestGitCommand(t, repositoryDirpath, "add", "--all")
+	runTestGitCommand(t, repositoryDirpath, "-c", "user.name=test", "-c", "user.email=test@kurtosis.com", "commit", "--quiet", "--message", "add kurtosis.yml")
+	runTestGitCommand(t, repositoryDirpath, "tag", testRef)
+	tagCommitSHA := runTestGitCommand(t, repositoryDirpath, "rev-parse", "HEAD")
+
+	validPackageRefRule := newValidPackageRefRule(source.NewLocalPackageSourceReader(rootDirpath), true)
+
+	failures := checkTestPackage(t, validPackageRefRule, getTestRefCatalogYaml(testRef, tagCommitSHA))
+	require.Equal(t, []FailureCode{}, getFailureCodes(failures))
+
+	failures = checkTestPackage(t, validPackageRefRule, getTestRefCatalogYaml(testRef, testMovedRefSHA))
+	require.Equal(t, []FailureCode{refMovedFailureCode}, getFailureCodes(failures))
+
+	failures = checkTestPackage(t, validPackageRefRule, getTestRefCatalogYaml(testRef, ""))
+	require.Equal(t, []FailureCode{refSHAMissingFailureCode}, getFailureCodes(failures))
+	require.Contains(t, failures[0].GetRemediation(), tagCommitSHA)
+}
+
+func TestResolveMissingRefSHAs(t *testing.T) {
+	catalogYaml := "packages:\n" +
+		"  - name: github.com/foo/bar\n" +
+		"  - name: github.com/foo/bar/app\n    ref: " + testRef + "\n" +
+		"  - name: github.com/foo/bar/pinned\n    ref: " + testRef + "\n    ref-sha: \"" + testRefSHA + "\"\n" +
+		"  - name: github.com/foo/bar/commit\n    ref: " + testCommitSHARef + "\n"
+	packageCatalog, err := catalog.GetPackageCatalogFromYamlFileContent([]byte(catalogYaml))
+	require.NoError(t, err)
+
+	refSHAs, err := ResolveMissingRefSHAs(context.Background(), getTestRefsPackageSourceReader(), packageCatalog)
+	require.NoError(t, err)
+	require.Equal(t, map[int]string{1: testRefSHA}, refSHAs)
+
+	// the recorded ref-sha makes the package pass the rule when it's required
+	newCatalogYaml, err := catalog.AddPackageRefSHAs([]byte(catalogYaml), refSHAs)
+	require.NoError(t, err)
+	newPackageCatalog, err := catalog.GetPackageCatalogFromYamlFileContent(newCatalogYaml)
+	require.NoError(t, err)
+	failures := newValidPackageRefRule(getTestRefsPackageSourceReader(), true).CheckPackage(context.Background(), newPackageCatalog[1])
+	require.Equal(t, []FailureCode{}, getFailureCodes(failures))
+}
+
+func TestResolveMissingRefSHAs_Error(t *testing.T) {
+	packageCatalog, err := catalog.GetPackageCatalogFromYamlFileContent([]byte(getTestRefCatalogYaml("v2", "")))
+	require.NoError(t, err)
+
+	_, err = ResolveMissingRefSHAs(context.Background(), getTestRefsPackageSourceReader(), packageCatalog)
+	require.Error(t, err)
+	require.True(t, source.IsFileNotFoundErr(err))
+
+	// the local directories that aren't git working trees don't resolve the refs to commits
+	localRootDirpath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(localRootDirpath, testRepositoryOwner, testRepositoryName), 0755))
+	packageCatalog, err = catalog.GetPackageCatalogFromYamlFileContent([]byte(getTestRefCatalogYaml(testRef, "")))
+	require.NoError(t, err)
+
+	_, err = ResolveMissingRefSHAs(context.Background(), source.NewLocalPackageSourceReader(localRootDirpath), packageCatalog)
+	require.Error(t, err)
+}
+
+// getTestRefsPackageSourceReader returns an in-memory source where the test ref resolves to the test ref SHA and the
+// test commit SHA ref to itself
+func getTestRefsPackageSourceReader() *source.InMemoryPackageSourceReader {
+	packageSourceReader := source.NewInMemoryPackageSourceReader()
+	packageSourceReader.AddRef(testRepositoryOwner, testRepositoryName, testRef, testRefSHA)
+	packageSourceReader.AddRef(testRepositoryOwner, testRepositoryName, testCommitSHARef, testCommitSHARef)
+	return packageSourceReader
+}
+
+// getTestRefCatalogYaml returns the content of a catalog file with only the test package, with the ref and ref-sha
+// fields if they aren't empty
+func getTestRefCatalogYaml(ref string, refSHA string) string {
+	catalogYaml := getTestCatalogYaml(testPackageName)
+	if ref != "" {
+		catalogYaml += "    ref: \"" + ref + "\"\n"
+	}
+	if refSHA != "" {
+		catalogYaml += "    ref-sha: \"" + refSHA + "\"\n"
+	}
+	return catalogYaml
+}
+
+// runTestGitCommand runs git in the repository and returns its output without the trailing newline
+func runTestGitCommand(t *testing.T, repositoryDirpath string, args ...string) string {
+	output, err := exec.Command("git", append([]string{"-C", repositoryDirpath}, args...)...).Output()
+	require.NoError(t, err)
+	return strings.TrimSpace(string(output))
+}
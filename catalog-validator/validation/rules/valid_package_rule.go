@@ -2,123 +2,233 @@ package rules
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"github.com/google/go-github/v54/github"
-	"github.com/kurtosis-tech/kurtosis-package-indexer/server/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/source"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/yamlnode"
 	"github.com/kurtosis-tech/kurtosis-package-indexer/server/consts"
 	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
 	"github.com/kurtosis-tech/stacktrace"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
-	"net/http"
 	"path"
+	"strings"
 )
 
 const (
 	validPackageRuleName = "Valid package"
+
+	kurtosisYmlNotFoundFailureCode  FailureCode = "KURTOSIS_YML_NOT_FOUND"
+	kurtosisYmlInvalidFailureCode   FailureCode = "KURTOSIS_YML_INVALID"
+	packageNameMismatchFailureCode  FailureCode = "PACKAGE_NAME_MISMATCH"
+	ownerNotAllowedFailureCode      FailureCode = "REPOSITORY_OWNER_NOT_ALLOWED"
+	requiredFileNotFoundFailureCode FailureCode = "REQUIRED_FILE_NOT_FOUND"
+
+	kurtosisYamlNameKey = "name"
 )
 
 type KurtosisYaml struct {
 	Name        string `yaml:"name"`
 	Description string `yaml:"description"`
+
+	// nameNode is the YAML node of the name value, it keeps its position in the file. It's nil if the name isn't a key
+	// of the file mapping, like when it's set through a merge key
+	nameNode *yaml.Node
+}
+
+// validPackageRuleParameters are the parameters of the rule that can be set in the rules config
+type validPackageRuleParameters struct {
+	AllowedOwners []string `yaml:"allowed-owners"`
+	RequiredFiles []string `yaml:"required-files"`
 }
 
 // validPackageRule checks if the package is valid by checking if:
 // 1- the package repository exist
 // 2- if the package repository contains the kurtosis.yml file
 // 3- if the name inside the kurtosis.yml file is the same in the package catalog
+// 4- if the repository owner is one of the allowed owners, when they are set
+// 5- if the package contains the required files, when they are set
 type validPackageRule struct {
-	name         string
-	gitHubClient *github.Client
+	name                string
+	packageSourceReader source.PackageSourceReader
+
+	// allowedOwners are the GitHub users and organizations whose repositories can be added, any owner is allowed if it's empty
+	allowedOwners []string
+
+	// requiredFiles are the files that every package has to contain besides the kurtosis.yml file, relative to the package root
+	requiredFiles []string
 }
 
-func newValidPackageRule(gitHubClient *github.Client) *validPackageRule {
-	return &validPackageRule{name: validPackageRuleName, gitHubClient: gitHubClient}
+func newValidPackageRule(packageSourceReader source.PackageSourceReader, allowedOwners []string, requiredFiles []string) *validPackageRule {
+	return &validPackageRule{name: validPackageRuleName, packageSourceReader: packageSourceReader, allowedOwners: allowedOwners, requiredFiles: requiredFiles}
+}
+
+// newValidPackageRuleFromConfig returns the rule with the allowed owners and required files set in the rules config,
+// there aren't any by default
+func newValidPackageRuleFromConfig(packageSourceReader source.PackageSourceReader, rulesConfig *RulesConfig) (*validPackageRule, error) {
+	parameters := &validPackageRuleParameters{AllowedOwners: nil, RequiredFiles: nil}
+	if err := rulesConfig.decodeRuleParameters(validPackageRuleName, parameters); err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred getting the parameters of rule '%s'", validPackageRuleName)
+	}
+	for _, allowedOwner := range parameters.AllowedOwners {
+		if allowedOwner == "" {
+			return nil, stacktrace.NewError("expected the 'allowed-owners' parameter of rule '%s' to contain GitHub users or organizations, but one of them is empty", validPackageRuleName)
+		}
+	}
+	for _, requiredFile := range parameters.RequiredFiles {
+		if requiredFile == "" || path.IsAbs(requiredFile) {
+			return nil, stacktrace.NewError("expected the 'required-files' parameter of rule '%s' to contain paths relative to the package root, but it contains '%s'", validPackageRuleName, requiredFile)
+		}
+	}
+	return newValidPackageRule(packageSourceReader, parameters.AllowedOwners, parameters.RequiredFiles), nil
 }
 
 func (validPackageRule *validPackageRule) GetName() RuleName {
 	return RuleName(validPackageRule.name)
 }
 
+func (validPackageRule *validPackageRule) GetDescription() string {
+	return "checks that the package exists, that its kurtosis.yml file has the same name as the catalog entry, that its repository owner is allowed and that it contains the required files"
+}
+
+func (validPackageRule *validPackageRule) GetParameters() map[string]interface{} {
+	return map[string]interface{}{
+		"allowed-owners": validPackageRule.allowedOwners,
+		"required-files": validPackageRule.requiredFiles,
+	}
+}
+
+func (validPackageRule *validPackageRule) GetDefaultSeverity() Severity {
+	return SeverityError
+}
+
 func (validPackageRule *validPackageRule) Check(ctx context.Context, catalog catalog.PackageCatalog) *CheckResult {
+	return checkEachPackage(ctx, validPackageRule, catalog)
+}
 
-	wasValidated := true
-	failures := map[types.PackageName][]string{}
-
-	for _, packageData := range catalog {
-		packageName := packageData.GetPackageName()
-		logrus.Debugf("Checking if package '%s' is valid...", packageName)
-		repositoryOwner := packageData.GetRepositoryOwner()
-		repositoryName := packageData.GetRepositoryName()
-		repositoryPackageRootPath := packageData.GetRepositoryPackageRootPath()
-		packageFailures := []string{}
-		packageNameFromKurtosisYamlFile, err := validPackageRule.getPackageNameFromKurtosisYmlFile(ctx, packageName, repositoryOwner, repositoryName, repositoryPackageRootPath)
-		if err != nil {
-			errorFailure := fmt.Sprintf("the package does not exist or does not contains the '%s' file", consts.DefaultKurtosisYamlFilename)
-			packageFailures = append(packageFailures, errorFailure)
-		} else {
-			if packageName != packageNameFromKurtosisYamlFile {
-				invalidPackageNameMsg := fmt.Sprintf("package name '%s' in the catalog does not match with the name '%s' found in the package repository", packageName, packageNameFromKurtosisYamlFile)
-				packageFailures = append(packageFailures, invalidPackageNameMsg)
+func (validPackageRule *validPackageRule) CheckPackage(ctx context.Context, packageData PackageData) []*Failure {
+	packageName := packageData.GetPackageName()
+	logrus.Debugf("Checking if package '%s' is valid...", packageName)
+	repository := source.NewPackageRepositoryFromPackageData(packageData)
+	kurtosisYamlFilepath := path.Join(packageData.GetRepositoryPackageRootPath(), consts.DefaultKurtosisYamlFilename)
+	packageFailures := []*Failure{}
+	if failure := validPackageRule.checkRepositoryOwner(packageData); failure != nil {
+		packageFailures = append(packageFailures, failure)
+	}
+	kurtosisYaml, err := validPackageRule.getKurtosisYaml(ctx, packageName, repository, kurtosisYamlFilepath)
+	if err != nil && source.IsFileNotFoundErr(err) {
+		errorFailure := newFailure(
+			kurtosisYmlNotFoundFailureCode,
+			kurtosisYamlFilepath,
+			fmt.Sprintf("the package does not exist or does not contains the '%s' file", consts.DefaultKurtosisYamlFilename),
+			fmt.Sprintf("check that the package name in the catalog points to the directory containing the '%s' file in a public GitHub repository", consts.DefaultKurtosisYamlFilename),
+		)
+		packageFailures = append(packageFailures, errorFailure)
+	} else if err != nil {
+		errorFailure := newFailure(
+			kurtosisYmlInvalidFailureCode,
+			kurtosisYamlFilepath,
+			fmt.Sprintf("the '%s' file could not be read or it's not valid. Error was:\n%s", consts.DefaultKurtosisYamlFilename, err.Error()),
+			fmt.Sprintf("check that the '%s' file is a valid YAML file with a non-empty '%s' field", consts.DefaultKurtosisYamlFilename, kurtosisYamlNameKey),
+		)
+		packageFailures = append(packageFailures, errorFailure)
+	} else {
+		packageNameFromKurtosisYamlFile := types.PackageName(kurtosisYaml.Name)
+		if packageName != packageNameFromKurtosisYamlFile {
+			invalidPackageNameFailure := newFailure(
+				packageNameMismatchFailureCode,
+				kurtosisYamlFilepath,
+				fmt.Sprintf("package name '%s' in the catalog does not match with the name '%s' found in the package repository", packageName, packageNameFromKurtosisYamlFile),
+				fmt.Sprintf("use the same name in the catalog and in the '%s' field of the '%s' file", kurtosisYamlNameKey, consts.DefaultKurtosisYamlFilename),
+			)
+			if kurtosisYaml.nameNode != nil {
+				invalidPackageNameFailure = invalidPackageNameFailure.withPosition(kurtosisYaml.nameNode.Line, kurtosisYaml.nameNode.Column)
 			}
+			packageFailures = append(packageFailures, invalidPackageNameFailure)
 		}
+		packageFailures = append(packageFailures, validPackageRule.checkRequiredFiles(ctx, packageData, repository)...)
+	}
 
-		if len(packageFailures) > 0 {
-			failures[packageName] = packageFailures
-			wasValidated = false
-			continue
-		}
+	if len(packageFailures) == 0 {
 		logrus.Debugf("...package '%s' successfully validated.", packageName)
 	}
+	return packageFailures
+}
 
-	checkResult := newCheckResult(validPackageRule.GetName(), wasValidated, failures)
-
-	return checkResult
+// checkRepositoryOwner returns a failure if the repository owner isn't allowed, the owners are compared without case
+// because GitHub doesn't take it into account
+func (validPackageRule *validPackageRule) checkRepositoryOwner(packageData PackageData) *Failure {
+	if len(validPackageRule.allowedOwners) == 0 {
+		return nil
+	}
+	repositoryOwner := packageData.GetRepositoryOwner()
+	for _, allowedOwner := range validPackageRule.allowedOwners {
+		if strings.EqualFold(allowedOwner, repositoryOwner) {
+			return nil
+		}
+	}
+	return newFailure(
+		ownerNotAllowedFailureCode,
+		"",
+		fmt.Sprintf("the owner '%s' of the package repository is not one of the allowed owners: %s", repositoryOwner, strings.Join(validPackageRule.allowedOwners, ", ")),
+		"move the package to a repository of an allowed owner, or ask the catalog maintainers to allow the owner",
+	)
 }
 
-func (validPackageRule *validPackageRule) getPackageNameFromKurtosisYmlFile(ctx context.Context, packageName types.PackageName, repositoryOwner string, repositoryName string, repositoryPackageRootPath string) (types.PackageName, error) {
-	kurtosisYamlFilepath := path.Join(repositoryPackageRootPath, consts.DefaultKurtosisYamlFilename)
-	repoGetContentOpts := &github.RepositoryContentGetOptions{
-		Ref: "",
+// checkRequiredFiles returns a failure for each required file that the package doesn't contain
+func (validPackageRule *validPackageRule) checkRequiredFiles(ctx context.Context, packageData PackageData, repository *source.PackageRepository) []*Failure {
+	requiredFileFailures := []*Failure{}
+	for _, requiredFile := range validPackageRule.requiredFiles {
+		requiredFilepath := path.Join(packageData.GetRepositoryPackageRootPath(), requiredFile)
+		_, err := validPackageRule.packageSourceReader.ReadFile(ctx, repository, requiredFilepath)
+		if err == nil {
+			continue
+		}
+		failureMsg := fmt.Sprintf("the package does not contain the required file '%s'", requiredFile)
+		if !source.IsFileNotFoundErr(err) {
+			failureMsg = fmt.Sprintf("the required file '%s' could not be read. Error was:\n%s", requiredFile, err.Error())
+		}
+		requiredFileFailures = append(requiredFileFailures, newFailure(
+			requiredFileNotFoundFailureCode,
+			requiredFilepath,
+			failureMsg,
+			fmt.Sprintf("add the '%s' file next to the '%s' file", requiredFile, consts.DefaultKurtosisYamlFilename),
+		))
 	}
+	return requiredFileFailures
+}
 
-	// get contents of kurtosis yaml file from GitHub
-	kurtosisYamlFileContentResult, _, resp, err := validPackageRule.gitHubClient.Repositories.GetContents(ctx, repositoryOwner, repositoryName, kurtosisYamlFilepath, repoGetContentOpts)
-	if err != nil && resp != nil && resp.StatusCode == http.StatusNotFound {
-		return "", stacktrace.NewError("No '%s' file for package '%s'", kurtosisYamlFilepath, packageName)
+func (validPackageRule *validPackageRule) getKurtosisYaml(ctx context.Context, packageName types.PackageName, repository *source.PackageRepository, kurtosisYamlFilepath string) (*KurtosisYaml, error) {
+	// get contents of kurtosis yaml file from the package repository
+	kurtosisYamlFileContent, err := validPackageRule.packageSourceReader.ReadFile(ctx, repository, kurtosisYamlFilepath)
+	if err != nil && source.IsFileNotFoundErr(err) {
+		return nil, stacktrace.Propagate(err, "No '%s' file for package '%s'", kurtosisYamlFilepath, packageName)
 	} else if err != nil {
-		errMsj := fmt.Sprintf("An error occurred reading content of Kurtosis Package '%s' - file '%s'", packageName, kurtosisYamlFilepath)
-		if errors.Is(err, &github.RateLimitError{}) {
-			errMsj = "GitHub API rate limit exceeded."
-			logrus.Errorf("%s Error is:\n%v", errMsj, err.Error())
-		}
-		return "", stacktrace.Propagate(err, "%s", errMsj)
+		return nil, stacktrace.Propagate(err, "An error occurred reading content of Kurtosis Package '%s' - file '%s'", packageName, kurtosisYamlFilepath)
 	}
 
-	kurtosisYaml, err := parseKurtosisYaml(kurtosisYamlFileContentResult)
+	kurtosisYaml, err := parseKurtosisYaml(kurtosisYamlFileContent)
 	if err != nil {
-		return "", stacktrace.Propagate(err, "an error occurred parsing the Kurtosis YAML file for '%s'", packageName)
+		return nil, stacktrace.Propagate(err, "an error occurred parsing the Kurtosis YAML file for '%s'", packageName)
 	}
 
-	packageNameFromYamlFile := types.PackageName(kurtosisYaml.Name)
-
-	return packageNameFromYamlFile, nil
+	return kurtosisYaml, nil
 }
 
-func parseKurtosisYaml(kurtosisYamlContent *github.RepositoryContent) (*KurtosisYaml, error) {
-	rawFileContent, err := kurtosisYamlContent.GetContent()
-	if err != nil {
-		return nil, stacktrace.Propagate(err, "An error occurred getting the content of the '%s' file", consts.DefaultKurtosisYamlFilename)
+func parseKurtosisYaml(kurtosisYamlContent []byte) (*KurtosisYaml, error) {
+	kurtosisYamlNode := &yaml.Node{}
+	if err := yaml.Unmarshal(kurtosisYamlContent, kurtosisYamlNode); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred parsing YAML for '%s'", consts.DefaultKurtosisYamlFilename)
 	}
 
 	kurtosisYaml := new(KurtosisYaml)
-	if err = yaml.Unmarshal([]byte(rawFileContent), kurtosisYaml); err != nil {
+	if err := kurtosisYamlNode.Decode(kurtosisYaml); err != nil {
 		return nil, stacktrace.Propagate(err, "An error occurred parsing YAML for '%s'", consts.DefaultKurtosisYamlFilename)
 	}
 
 	if kurtosisYaml.Name == "" {
 		return nil, stacktrace.NewError("Kurtosis YAML file had an empty name. This is invalid.")
 	}
+	kurtosisYaml.nameNode = yamlnode.FindMappingValueNode(kurtosisYamlNode, kurtosisYamlNameKey)
 	return kurtosisYaml, nil
 }
@@ -0,0 +1,80 @@
+package rules
+
+import (
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/source"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/consts"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestValidPackageRule_CheckPackage(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		kurtosisYamlContent  string
+		expectedFailureCodes []FailureCode
+		expectedLine         int
+	}{
+		{
+			name:                 "valid package",
+			kurtosisYamlContent:  "name: github.com/foo/bar\n",
+			expectedFailureCodes: []FailureCode{},
+		},
+		{
+			name:                 "missing kurtosis.yml file",
+			kurtosisYamlContent:  "",
+			expectedFailureCodes: []FailureCode{kurtosisYmlNotFoundFailureCode},
+		},
+		{
+			name:                 "invalid kurtosis.yml file",
+			kurtosisYamlContent:  "description: Runs Postgres\n",
+			expectedFailureCodes: []FailureCode{kurtosisYmlInvalidFailureCode},
+		},
+		{
+			name:                 "name mismatch",
+			kurtosisYamlContent:  "description: Runs Postgres\nname: github.com/foo/baz\n",
+			expectedFailureCodes: []FailureCode{packageNameMismatchFailureCode},
+			expectedLine:         2,
+		},
+		{
+			name:                 "name set with a merge key",
+			kurtosisYamlContent:  "base: &base\n  name: github.com/foo/bar\n<<: *base\n",
+			expectedFailureCodes: []FailureCode{},
+		},
+		{
+			name:                 "name mismatch set with a merge key",
+			kurtosisYamlContent:  "base: &base\n  name: github.com/foo/baz\n<<: *base\n",
+			expectedFailureCodes: []FailureCode{packageNameMismatchFailureCode},
+			expectedLine:         unknownPosition,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			packageSourceReader := source.NewInMemoryPackageSourceReader()
+			if testCase.kurtosisYamlContent != "" {
+				packageSourceReader.AddFile(testRepositoryOwner, testRepositoryName, consts.DefaultKurtosisYamlFilename, []byte(testCase.kurtosisYamlContent))
+			}
+			validPackageRule := newValidPackageRule(packageSourceReader, nil, nil)
+
+			failures := checkTestPackage(t, validPackageRule, getTestCatalogYaml(testPackageName))
+			require.Equal(t, testCase.expectedFailureCodes, getFailureCodes(failures))
+			if len(failures) > 0 {
+				require.Equal(t, testCase.expectedLine, failures[0].GetLine())
+			}
+		})
+	}
+}
+
+func TestValidPackageRule_CheckPackage_OwnerAndRequiredFiles(t *testing.T) {
+	packageSourceReader := source.NewInMemoryPackageSourceReader()
+	packageSourceReader.AddFile(testRepositoryOwner, testRepositoryName, consts.DefaultKurtosisYamlFilename, []byte("name: github.com/foo/bar\n"))
+	packageSourceReader.AddFile(testRepositoryOwner, testRepositoryName, "README.md", []byte("# Bar\n"))
+
+	validPackageRule := newValidPackageRule(packageSourceReader, []string{"FOO"}, []string{"README.md"})
+	require.Empty(t, checkTestPackage(t, validPackageRule, getTestCatalogYaml(testPackageName)))
+
+	validPackageRule = newValidPackageRule(packageSourceReader, []string{"kurtosis-tech"}, []string{"README.md", "LICENSE"})
+	failures := checkTestPackage(t, validPackageRule, getTestCatalogYaml(testPackageName))
+	require.Equal(t, []FailureCode{ownerNotAllowedFailureCode, requiredFileNotFoundFailureCode}, getFailureCodes(failures))
+	require.Equal(t, "LICENSE", failures[1].GetFilepath())
+}
@@ -0,0 +1,188 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/source"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/starlarkfile"
+	"github.com/sirupsen/logrus"
+	"regexp"
+	"strings"
+)
+
+const (
+	validRunArgumentsRuleName = "Valid run arguments"
+
+	// jsonArgumentType is the type of the arguments that accept any value, it's the type the catalog UI uses when the
+	// argument type isn't documented
+	jsonArgumentType = "json"
+
+	runArgumentNotInSignatureFailureCode FailureCode = "RUN_ARGUMENT_NOT_IN_SIGNATURE"
+	runArgumentNotDocumentedFailureCode  FailureCode = "RUN_ARGUMENT_NOT_DOCUMENTED"
+	runArgumentUnknownTypeFailureCode    FailureCode = "RUN_ARGUMENT_UNKNOWN_TYPE"
+	runArgumentTypeMismatchFailureCode   FailureCode = "RUN_ARGUMENT_TYPE_MISMATCH"
+)
+
+var (
+	// knownArgumentTypes are the argument types the catalog UI can render, the list and dict types can also declare
+	// the type of their values, like 'list[string]' and 'dict[string, int]'
+	knownArgumentTypes = []string{"string", "bool", "int", "list", "dict", jsonArgumentType}
+
+	listArgumentTypeRegexp = regexp.MustCompile(`^list\s*\[\s*(?P<valueType>[a-z]+)\s*]$`)
+	dictArgumentTypeRegexp = regexp.MustCompile(`^dict\s*\[\s*(?P<keyType>[a-z]+)\s*,\s*(?P<valueType>[a-z]+)\s*]$`)
+)
+
+// validRunArgumentsRule checks if the arguments of the run function of the package, which the catalog UI shows, are
+// documented in the "Args:" block of its docstring, by checking if:
+// 1- every documented argument is in the run function signature
+// 2- every required argument of the run function, other than plan, is documented
+// 3- the documented types are known types
+// 4- the literal default values match the documented types
+// The types declared in a '# type: ...' comment after the arguments that aren't in the docstring are also checked,
+// the docstring type wins like in the package indexer. The packages without a run function are skipped, the Runnable
+// package rule reports them
+type validRunArgumentsRule struct {
+	name                string
+	packageSourceReader source.PackageSourceReader
+}
+
+func newValidRunArgumentsRule(packageSourceReader source.PackageSourceReader) *validRunArgumentsRule {
+	return &validRunArgumentsRule{name: validRunArgumentsRuleName, packageSourceReader: packageSourceReader}
+}
+
+func (validRunArgumentsRule *validRunArgumentsRule) GetName() RuleName {
+	return RuleName(validRunArgumentsRule.name)
+}
+
+func (validRunArgumentsRule *validRunArgumentsRule) GetDescription() string {
+	return "checks that the arguments documented in the 'Args:' block of the run function docstring match its signature, with known types, and that its required arguments are documented"
+}
+
+func (validRunArgumentsRule *validRunArgumentsRule) GetParameters() map[string]interface{} {
+	return map[string]interface{}{}
+}
+
+func (validRunArgumentsRule *validRunArgumentsRule) GetDefaultSeverity() Severity {
+	return SeverityError
+}
+
+func (validRunArgumentsRule *validRunArgumentsRule) Check(ctx context.Context, catalog catalog.PackageCatalog) *CheckResult {
+	return checkEachPackage(ctx, validRunArgumentsRule, catalog)
+}
+
+func (validRunArgumentsRule *validRunArgumentsRule) CheckPackage(ctx context.Context, packageData PackageData) []*Failure {
+	packageName := packageData.GetPackageName()
+	logrus.Debugf("Checking if the run arguments of package '%s' are valid...", packageName)
+	repository := source.NewPackageRepositoryFromPackageData(packageData)
+	mainStarFilepath := getMainStarFilepath(packageData)
+
+	mainStarFileContent, err := validRunArgumentsRule.packageSourceReader.ReadFile(ctx, repository, mainStarFilepath)
+	if err != nil {
+		logrus.Debugf("Skipping the run arguments of package '%s' because its main.star file can't be read, the '%s' rule reports it", packageName, runnablePackageRuleName)
+		return []*Failure{}
+	}
+	mainStarFile, syntaxErr := starlarkfile.ParseFile(mainStarFilepath, mainStarFileContent)
+	if syntaxErr != nil {
+		logrus.Debugf("Skipping the run arguments of package '%s' because its main.star file isn't valid, the '%s' rule reports it", packageName, runnablePackageRuleName)
+		return []*Failure{}
+	}
+	runFunction := mainStarFile.FindTopLevelFunction(runFunctionName)
+	if runFunction == nil {
+		logrus.Debugf("Skipping the run arguments of package '%s' because it has no run function, the '%s' rule reports it", packageName, runnablePackageRuleName)
+		return []*Failure{}
+	}
+
+	runArguments := starlarkfile.ExtractFunctionArguments(runFunction)
+	failures := []*Failure{}
+	for _, documentedArgument := range runArguments.GetDocumentedArguments() {
+		failures = append(failures, checkDocumentedRunArgument(mainStarFilepath, runArguments, documentedArgument)...)
+	}
+	for _, signatureArgument := range runArguments.GetSignatureArguments() {
+		commentedType := signatureArgument.GetCommentedType()
+		if commentedType != "" && runArguments.FindDocumentedArgument(signatureArgument.GetName()) == nil {
+			failures = append(failures, checkRunArgumentType(mainStarFilepath, signatureArgument, commentedType, signatureArgument.GetLine(), signatureArgument.GetColumn())...)
+		}
+
+		// the plan is passed by Kurtosis, so it doesn't need to be documented
+		isPlanArgument := signatureArgument.GetName() == runFunctionPlanParamName
+		if isPlanArgument || !signatureArgument.IsRequired() || runArguments.FindDocumentedArgument(signatureArgument.GetName()) != nil {
+			continue
+		}
+		failures = append(failures, newFailure(
+			runArgumentNotDocumentedFailureCode,
+			mainStarFilepath,
+			fmt.Sprintf("the required argument '%s' of the '%s' function isn't documented in the '%s' block of its docstring", signatureArgument.GetName(), runFunctionName, starlarkfile.DocstringArgsSectionHeader),
+			fmt.Sprintf("document the argument in the docstring, like '%s (string): what the argument sets'", signatureArgument.GetName()),
+		).withPosition(signatureArgument.GetLine(), signatureArgument.GetColumn()))
+	}
+
+	if len(failures) == 0 {
+		logrus.Debugf("...run arguments of package '%s' successfully validated.", packageName)
+	}
+	return failures
+}
+
+// checkDocumentedRunArgument returns the failures of an argument documented in the run function docstring
+func checkDocumentedRunArgument(mainStarFilepath string, runArguments *starlarkfile.FunctionArguments, documentedArgument *starlarkfile.DocumentedArgument) []*Failure {
+	argumentName := documentedArgument.GetName()
+	signatureArgument := runArguments.FindSignatureArgument(argumentName)
+	if signatureArgument == nil {
+		return []*Failure{newFailure(
+			runArgumentNotInSignatureFailureCode,
+			mainStarFilepath,
+			fmt.Sprintf("the argument '%s' is documented in the '%s' function docstring but the function doesn't declare it", argumentName, runFunctionName),
+			"remove the argument from the docstring, or fix its name if the argument was renamed",
+		).withPosition(documentedArgument.GetLine(), documentedArgument.GetColumn())}
+	}
+
+	if documentedArgument.GetDocumentedType() == "" {
+		return []*Failure{}
+	}
+	return checkRunArgumentType(mainStarFilepath, signatureArgument, documentedArgument.GetDocumentedType(), documentedArgument.GetLine(), documentedArgument.GetColumn())
+}
+
+// checkRunArgumentType returns the failures of the type documented for an argument of the run function, either in the
+// docstring or in a type comment. The line and column are the position of the documented type
+func checkRunArgumentType(mainStarFilepath string, signatureArgument *starlarkfile.SignatureArgument, documentedType string, line int, column int) []*Failure {
+	argumentName := signatureArgument.GetName()
+	lowercaseDocumentedType := strings.ToLower(documentedType)
+	if !isKnownArgumentType(lowercaseDocumentedType) {
+		return []*Failure{newFailure(
+			runArgumentUnknownTypeFailureCode,
+			mainStarFilepath,
+			fmt.Sprintf("the documented type '%s' of argument '%s' isn't a known type, the catalog UI will treat the argument as '%s'", documentedType, argumentName, jsonArgumentType),
+			fmt.Sprintf("use one of the types %s, the list and dict types can declare their value types like 'list[string]' or 'dict[string, int]'", strings.Join(knownArgumentTypes, ", ")),
+		).withPosition(line, column)}
+	}
+
+	defaultValueType := signatureArgument.GetDefaultValueType()
+	documentedBaseType, _, _ := strings.Cut(lowercaseDocumentedType, "[")
+	documentedBaseType = strings.TrimSpace(documentedBaseType)
+	if defaultValueType == "" || documentedBaseType == jsonArgumentType || defaultValueType == documentedBaseType {
+		return []*Failure{}
+	}
+	return []*Failure{newFailure(
+		runArgumentTypeMismatchFailureCode,
+		mainStarFilepath,
+		fmt.Sprintf("the argument '%s' is documented as '%s' but its default value is a %s", argumentName, documentedType, defaultValueType),
+		"fix the documented type or the default value so they match",
+	).withPosition(signatureArgument.GetLine(), signatureArgument.GetColumn())}
+}
+
+// isKnownArgumentType returns true if the catalog UI can render the lowercase argument type
+func isKnownArgumentType(argumentType string) bool {
+	if listTypeMatch := listArgumentTypeRegexp.FindStringSubmatch(argumentType); listTypeMatch != nil {
+		return isKnownArgumentType(listTypeMatch[listArgumentTypeRegexp.SubexpIndex("valueType")])
+	}
+	if dictTypeMatch := dictArgumentTypeRegexp.FindStringSubmatch(argumentType); dictTypeMatch != nil {
+		return isKnownArgumentType(dictTypeMatch[dictArgumentTypeRegexp.SubexpIndex("keyType")]) &&
+			isKnownArgumentType(dictTypeMatch[dictArgumentTypeRegexp.SubexpIndex("valueType")])
+	}
+	for _, knownArgumentType := range knownArgumentTypes {
+		if argumentType == knownArgumentType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package rules
+
+import (
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/source"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/consts"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestValidRunArgumentsRule_CheckPackage(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		mainStarContent      string
+		expectedFailureCodes []FailureCode
+	}{
+		{
+			name:                 "documented arguments",
+			mainStarContent:      "def run(plan, name, count = 1):\n    \"\"\"Runs the service\n\n    Args:\n        name (string): the name of the service\n        count (int): how many services to run\n    \"\"\"\n    pass\n",
+			expectedFailureCodes: []FailureCode{},
+		},
+		{
+			name:                 "documented argument not in the signature",
+			mainStarContent:      "def run(plan, name):\n    \"\"\"Runs the service\n\n    Args:\n        name (string): the name of the service\n        image (string): the image of the service\n    \"\"\"\n    pass\n",
+			expectedFailureCodes: []FailureCode{runArgumentNotInSignatureFailureCode},
+		},
+		{
+			name:                 "required argument not documented",
+			mainStarContent:      "def run(plan, name, count = 1, *args):\n    pass\n",
+			expectedFailureCodes: []FailureCode{runArgumentNotDocumentedFailureCode},
+		},
+		{
+			name:                 "unknown and mismatched documented types",
+			mainStarContent:      "def run(plan, name = \"postgres\", count = \"1\"):\n    \"\"\"Runs the service\n\n    Args:\n        name (text): the name of the service\n        count (int): how many services to run\n    \"\"\"\n    pass\n",
+			expectedFailureCodes: []FailureCode{runArgumentUnknownTypeFailureCode, runArgumentTypeMismatchFailureCode},
+		},
+		{
+			name:                 "unknown and mismatched commented types",
+			mainStarContent:      "def run(\n    plan,\n    name = \"postgres\", # type: text\n    count = \"1\", # type: int\n    ports = [], # type: list\n):\n    pass\n",
+			expectedFailureCodes: []FailureCode{runArgumentUnknownTypeFailureCode, runArgumentTypeMismatchFailureCode},
+		},
+		{
+			name:                 "docstring type wins over the commented type",
+			mainStarContent:      "def run(\n    plan,\n    count = 1, # type: string\n):\n    \"\"\"Runs the service\n\n    Args:\n        count (int): how many services to run\n    \"\"\"\n    pass\n",
+			expectedFailureCodes: []FailureCode{},
+		},
+		{
+			name:                 "no run function",
+			mainStarContent:      "def main(plan):\n    pass\n",
+			expectedFailureCodes: []FailureCode{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			packageSourceReader := source.NewInMemoryPackageSourceReader()
+			packageSourceReader.AddFile(testRepositoryOwner, testRepositoryName, consts.StarlarkMainDotStarFileName, []byte(testCase.mainStarContent))
+
+			failures := checkTestPackage(t, newValidRunArgumentsRule(packageSourceReader), getTestCatalogYaml(testPackageName))
+			require.Equal(t, testCase.expectedFailureCodes, getFailureCodes(failures))
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package rules
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+	"github.com/kurtosis-tech/stacktrace"
+	"gopkg.in/yaml.v3"
+	"time"
+)
+
+const (
+	// WaiversFilename is the default name of the waivers file, it's next to the catalog file
+	WaiversFilename = "catalog-validator-waivers.yml"
+
+	waiversRuleName = "Waivers"
+
+	waiverExpiryDateLayout = "2006-01-02"
+
+	waiverExpiredFailureCode FailureCode = "WAIVER_EXPIRED"
+	waiverUnusedFailureCode  FailureCode = "WAIVER_UNUSED"
+)
+
+// waiversFileContent is the content of the waivers file, e.g.:
+// waivers:
+//   - package: "github.com/foo/bar"
+//     rule: "Valid package icon"
+//     code: "ICON_TOO_LARGE"
+//     reason: "the icon size was agreed with the package author"
+//     expires: "2024-12-31"
+type waiversFileContent struct {
+	Waivers []*waiverFileEntry `yaml:"waivers"`
+}
+
+type waiverFileEntry struct {
+	Package string `yaml:"package"`
+	Rule    string `yaml:"rule"`
+	Code    string `yaml:"code"`
+	Reason  string `yaml:"reason"`
+	Expires string `yaml:"expires"`
+}
+
+// Waiver accepts the failures of a package with a failure code found by a rule, until its expiry date
+type Waiver struct {
+	packageName types.PackageName
+	ruleName    RuleName
+	failureCode FailureCode
+
+	// reason explains why the failures are accepted
+	reason string
+
+	// expiryDate is the last day when the waiver is applied
+	expiryDate time.Time
+}
+
+// ReadWaiversFileContent returns the waivers in the waivers file content, all the fields of each waiver are required
+func ReadWaiversFileContent(fileContent []byte) ([]*Waiver, error) {
+	waiversFileContentObj := &waiversFileContent{}
+	if err := yaml.Unmarshal(fileContent, waiversFileContentObj); err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred unmarshalling the waivers file content")
+	}
+
+	waivers := []*Waiver{}
+	for waiverIndex, waiverEntry := range waiversFileContentObj.Waivers {
+		if waiverEntry.Package == "" || waiverEntry.Rule == "" || waiverEntry.Code == "" || waiverEntry.Reason == "" || waiverEntry.Expires == "" {
+			return nil, stacktrace.NewError("expected the waiver number '%d' to have the 'package', 'rule', 'code', 'reason' and 'expires' fields, but some of them are missing", waiverIndex+1)
+		}
+		expiryDate, err := time.Parse(waiverExpiryDateLayout, waiverEntry.Expires)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "an error occurred parsing the expiry date '%s' of the waiver number '%d', it has to be like 'YYYY-MM-DD'", waiverEntry.Expires, waiverIndex+1)
+		}
+		waivers = append(waivers, &Waiver{
+			packageName: types.PackageName(waiverEntry.Package),
+			ruleName:    RuleName(waiverEntry.Rule),
+			failureCode: FailureCode(waiverEntry.Code),
+			reason:      waiverEntry.Reason,
+			expiryDate:  expiryDate,
+		})
+	}
+	return waivers, nil
+}
+
+func (waiver *Waiver) GetPackageName() types.PackageName {
+	return waiver.packageName
+}
+
+func (waiver *Waiver) GetRuleName() RuleName {
+	return waiver.ruleName
+}
+
+func (waiver *Waiver) GetFailureCode() FailureCode {
+	return waiver.failureCode
+}
+
+func (waiver *Waiver) GetReason() string {
+	return waiver.reason
+}
+
+func (waiver *Waiver) GetExpiryDate() time.Time {
+	return waiver.expiryDate
+}
+
+// IsExpired returns true if the expiry date has passed, the waiver is still applied during the expiry date
+func (waiver *Waiver) IsExpired(now time.Time) bool {
+	return !now.Before(waiver.expiryDate.AddDate(0, 0, 1))
+}
+
+// Matches returns true if the waiver accepts the failure of the package found by the rule, even if it's expired
+func (waiver *Waiver) Matches(ruleName RuleName, packageName types.PackageName, failure *Failure) bool {
+	return waiver.ruleName == ruleName && waiver.packageName == packageName && waiver.failureCode == failure.GetCode()
+}
+
+func (waiver *Waiver) String() string {
+	return fmt.Sprintf("package '%s', rule '%s' and code '%s'", waiver.packageName, waiver.ruleName, waiver.failureCode)
+}
+
+// CheckWaivers returns the failures of the waivers that have to be removed from the waivers file: the expired ones,
+// and the unused ones which didn't match any failure of their package and rule. Both lists only have to contain the
+// waivers whose package and rule were checked
+func CheckWaivers(waivers []*Waiver, unusedWaivers []*Waiver, now time.Time) *CheckResult {
+	failures := map[types.PackageName][]*Failure{}
+
+	for _, waiver := range waivers {
+		if waiver.IsExpired(now) {
+			expiredWaiverFailure := newFailure(
+				waiverExpiredFailureCode,
+				"",
+				fmt.Sprintf("the waiver for rule '%s' and code '%s' expired on %s, its failures are reported again", waiver.ruleName, waiver.failureCode, waiver.expiryDate.Format(waiverExpiryDateLayout)),
+				fmt.Sprintf("fix the failures and remove the waiver from '%s', or extend its expiry date if they are still accepted", WaiversFilename),
+			).withSeverity(SeverityError)
+			failures[waiver.packageName] = append(failures[waiver.packageName], expiredWaiverFailure)
+		}
+	}
+
+	for _, waiver := range unusedWaivers {
+		unusedWaiverFailure := newFailure(
+			waiverUnusedFailureCode,
+			"",
+			fmt.Sprintf("the waiver for rule '%s' and code '%s' doesn't match any failure anymore", waiver.ruleName, waiver.failureCode),
+			fmt.Sprintf("remove the waiver from '%s'", WaiversFilename),
+		).withSeverity(SeverityError)
+		failures[waiver.packageName] = append(failures[waiver.packageName], unusedWaiverFailure)
+	}
+
+	return NewCheckResultFromFailures(waiversRuleName, failures)
+}
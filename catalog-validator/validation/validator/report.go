@@ -0,0 +1,256 @@
+package validator
+
+import (
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/rules"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+	"sort"
+	"time"
+)
+
+// Report accumulates the failures of every rule for every package checked, reports from several validator runs
+// can be merged into a single one
+type Report struct {
+	// ruleNames contains every rule checked, in the order they were added to the report
+	ruleNames []rules.RuleName
+
+	// packageNames contains every package checked, in the order they were added to the report. The packages that
+	// weren't checked can still have failures, like the packages removed from the catalog
+	packageNames []types.PackageName
+
+	failures map[rules.RuleName]map[types.PackageName][]*rules.Failure
+
+	// duration is the time elapsed validating the catalog, it's shorter than the sum of the rule durations because
+	// the rules are checked at the same time
+	duration time.Duration
+
+	// ruleDurations is the time elapsed checking each rule, for package rules it goes from the start of the first
+	// package check to the end of the last one
+	ruleDurations map[rules.RuleName]time.Duration
+
+	// packageDurations is the time spent checking each package, it's only set for package rules
+	packageDurations map[rules.RuleName]map[types.PackageName]time.Duration
+
+	// waivedFailures are the failures accepted by a waiver, they aren't part of the failures
+	waivedFailures []*WaivedFailure
+}
+
+func NewReport() *Report {
+	return &Report{
+		ruleNames:        []rules.RuleName{},
+		packageNames:     []types.PackageName{},
+		failures:         map[rules.RuleName]map[types.PackageName][]*rules.Failure{},
+		duration:         0,
+		ruleDurations:    map[rules.RuleName]time.Duration{},
+		packageDurations: map[rules.RuleName]map[types.PackageName]time.Duration{},
+		waivedFailures:   []*WaivedFailure{},
+	}
+}
+
+// AddCheckedPackages records the packages checked, the ones already in the report are ignored
+func (report *Report) AddCheckedPackages(packageNames ...types.PackageName) {
+	for _, packageName := range packageNames {
+		if !report.IsCheckedPackage(packageName) {
+			report.packageNames = append(report.packageNames, packageName)
+		}
+	}
+}
+
+// AddCheckResult records the rule as checked and adds all its failures, if the rule was already in the report
+// the failures are appended to the existing ones of each package
+func (report *Report) AddCheckResult(checkResult *rules.CheckResult) {
+	ruleName := checkResult.GetRuleName()
+	report.addRule(ruleName)
+	for packageName, packageFailures := range checkResult.GetFailures() {
+		report.addPackageFailures(ruleName, packageName, packageFailures)
+	}
+}
+
+// AddWaivedFailure records a failure accepted by a waiver
+func (report *Report) AddWaivedFailure(waivedFailure *WaivedFailure) {
+	report.waivedFailures = append(report.waivedFailures, waivedFailure)
+}
+
+// AddDuration adds the time elapsed validating the catalog
+func (report *Report) AddDuration(duration time.Duration) {
+	report.duration += duration
+}
+
+// AddRuleDuration adds the time elapsed checking the rule
+func (report *Report) AddRuleDuration(ruleName rules.RuleName, duration time.Duration) {
+	report.ruleDurations[ruleName] += duration
+}
+
+// AddPackageCheckDuration adds the time spent checking the rule for the package, the rule duration is added separately
+// because the packages are checked at the same time
+func (report *Report) AddPackageCheckDuration(ruleName rules.RuleName, packageName types.PackageName, duration time.Duration) {
+	report.addPackageDuration(ruleName, packageName, duration)
+}
+
+// Merge adds all the rules, packages, failures, waived failures and durations of the other report to this one
+func (report *Report) Merge(otherReport *Report) {
+	report.AddCheckedPackages(otherReport.packageNames...)
+	report.waivedFailures = append(report.waivedFailures, otherReport.waivedFailures...)
+	report.AddDuration(otherReport.duration)
+	for _, ruleName := range otherReport.ruleNames {
+		report.addRule(ruleName)
+		for _, packageName := range otherReport.GetPackageNamesWithFailures(ruleName) {
+			report.addPackageFailures(ruleName, packageName, otherReport.failures[ruleName][packageName])
+		}
+		report.AddRuleDuration(ruleName, otherReport.ruleDurations[ruleName])
+		for packageName, packageDuration := range otherReport.packageDurations[ruleName] {
+			report.addPackageDuration(ruleName, packageName, packageDuration)
+		}
+	}
+}
+
+// IsValidCatalog returns true if none of the packages has an error failure, warnings and info failures don't invalidate the catalog
+func (report *Report) IsValidCatalog() bool {
+	return !report.HasFailuresWithSeverity(rules.SeverityError)
+}
+
+// HasWarnings returns true if any package has a warning failure
+func (report *Report) HasWarnings() bool {
+	return report.HasFailuresWithSeverity(rules.SeverityWarning)
+}
+
+func (report *Report) HasFailuresWithSeverity(severity rules.Severity) bool {
+	for _, failuresByPackage := range report.failures {
+		for _, packageFailures := range failuresByPackage {
+			for _, failure := range packageFailures {
+				if failure.GetSeverity() == severity {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// HasFailures returns true if any package has a failure, of any severity
+func (report *Report) HasFailures() bool {
+	return len(report.failures) > 0
+}
+
+// GetRuleNames returns every rule checked, in the order they were added to the report
+func (report *Report) GetRuleNames() []rules.RuleName {
+	return report.ruleNames
+}
+
+// GetPackageNames returns every package checked, in the order they were added to the report
+func (report *Report) GetPackageNames() []types.PackageName {
+	return report.packageNames
+}
+
+// GetReportedPackageNames returns every package checked, in the order they were added to the report, followed by the
+// packages that weren't checked but have failures, like the packages removed from the catalog, sorted by name
+func (report *Report) GetReportedPackageNames() []types.PackageName {
+	uncheckedPackageNames := []types.PackageName{}
+	for _, failuresByPackage := range report.failures {
+		for packageName := range failuresByPackage {
+			if !report.IsCheckedPackage(packageName) && !containsPackageName(uncheckedPackageNames, packageName) {
+				uncheckedPackageNames = append(uncheckedPackageNames, packageName)
+			}
+		}
+	}
+	sort.Slice(uncheckedPackageNames, func(i, j int) bool {
+		return uncheckedPackageNames[i] < uncheckedPackageNames[j]
+	})
+	return append(append([]types.PackageName{}, report.packageNames...), uncheckedPackageNames...)
+}
+
+// IsCheckedPackage returns true if the package was checked, a package with failures may not have been checked
+func (report *Report) IsCheckedPackage(packageName types.PackageName) bool {
+	return containsPackageName(report.packageNames, packageName)
+}
+
+// GetRuleNamesWithFailures returns the name of the rules with failures, in the order they were added to the report
+func (report *Report) GetRuleNamesWithFailures() []rules.RuleName {
+	ruleNames := []rules.RuleName{}
+	for _, ruleName := range report.ruleNames {
+		if _, found := report.failures[ruleName]; found {
+			ruleNames = append(ruleNames, ruleName)
+		}
+	}
+	return ruleNames
+}
+
+// GetPackageNamesWithFailures returns the name of the packages that failed the rule, sorted by name
+func (report *Report) GetPackageNamesWithFailures(ruleName rules.RuleName) []types.PackageName {
+	packageNames := []types.PackageName{}
+	for packageName := range report.failures[ruleName] {
+		packageNames = append(packageNames, packageName)
+	}
+	sort.Slice(packageNames, func(i, j int) bool {
+		return packageNames[i] < packageNames[j]
+	})
+	return packageNames
+}
+
+// GetFailures returns the failures of the package for the rule, it's empty if the package passed the rule
+func (report *Report) GetFailures(ruleName rules.RuleName, packageName types.PackageName) []*rules.Failure {
+	return report.failures[ruleName][packageName]
+}
+
+// GetWaivedFailures returns the failures accepted by a waiver, in the order they were added to the report
+func (report *Report) GetWaivedFailures() []*WaivedFailure {
+	return report.waivedFailures
+}
+
+// GetDuration returns the time elapsed validating the catalog
+func (report *Report) GetDuration() time.Duration {
+	return report.duration
+}
+
+// GetRuleDuration returns the time elapsed checking the rule
+func (report *Report) GetRuleDuration(ruleName rules.RuleName) time.Duration {
+	return report.ruleDurations[ruleName]
+}
+
+// GetPackageCheckDuration returns the time spent checking the rule for the package, it's zero if the rule
+// checks the whole catalog at once
+func (report *Report) GetPackageCheckDuration(ruleName rules.RuleName, packageName types.PackageName) time.Duration {
+	return report.packageDurations[ruleName][packageName]
+}
+
+func (report *Report) GetRulesResult() map[rules.RuleName]map[types.PackageName][]*rules.Failure {
+	return report.failures
+}
+
+func (report *Report) addRule(ruleName rules.RuleName) {
+	for _, existingRuleName := range report.ruleNames {
+		if existingRuleName == ruleName {
+			return
+		}
+	}
+	report.ruleNames = append(report.ruleNames, ruleName)
+}
+
+func (report *Report) addPackageFailures(ruleName rules.RuleName, packageName types.PackageName, packageFailures []*rules.Failure) {
+	if len(packageFailures) == 0 {
+		return
+	}
+	failuresByPackage, found := report.failures[ruleName]
+	if !found {
+		failuresByPackage = map[types.PackageName][]*rules.Failure{}
+		report.failures[ruleName] = failuresByPackage
+	}
+	failuresByPackage[packageName] = append(failuresByPackage[packageName], packageFailures...)
+}
+
+func (report *Report) addPackageDuration(ruleName rules.RuleName, packageName types.PackageName, duration time.Duration) {
+	durationsByPackage, found := report.packageDurations[ruleName]
+	if !found {
+		durationsByPackage = map[types.PackageName]time.Duration{}
+		report.packageDurations[ruleName] = durationsByPackage
+	}
+	durationsByPackage[packageName] += duration
+}
+
+func containsPackageName(packageNames []types.PackageName, packageName types.PackageName) bool {
+	for _, existingPackageName := range packageNames {
+		if existingPackageName == packageName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,276 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/source"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/rules"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/consts"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/png"
+	"testing"
+)
+
+const (
+	iconRuleName        rules.RuleName = "Valid package icon"
+	descriptionRuleName rules.RuleName = "Valid package description"
+	waiversRuleName     rules.RuleName = "Waivers"
+
+	fooPackageName types.PackageName = "github.com/foo/foo"
+	barPackageName types.PackageName = "github.com/foo/bar"
+	bazPackageName types.PackageName = "github.com/foo/baz"
+
+	// the test failures are found by the rules checking this package
+	testFailureRepositoryOwner = "failures"
+	testFailureRepositoryName  = "test"
+	testFailurePackageName     = "github.com/failures/test"
+
+	iconNotFoundCode rules.FailureCode = "ICON_NOT_FOUND"
+	iconTooSmallCode rules.FailureCode = "ICON_TOO_SMALL"
+	tooShortCode     rules.FailureCode = "DESCRIPTION_TOO_SHORT"
+
+	waiverExpiredCode rules.FailureCode = "WAIVER_EXPIRED"
+	waiverUnusedCode  rules.FailureCode = "WAIVER_UNUSED"
+)
+
+func TestReport_AddCheckResult(t *testing.T) {
+	testCases := []struct {
+		name                         string
+		checkResults                 []*rules.CheckResult
+		expectedRuleNames            []rules.RuleName
+		expectedRuleNamesWithFailure []rules.RuleName
+		expectedFailureCodes         map[rules.RuleName]map[types.PackageName][]rules.FailureCode
+		expectedIsValidCatalog       bool
+	}{
+		{
+			name:                         "rule without failures",
+			checkResults:                 []*rules.CheckResult{newTestCheckResult(t, iconRuleName, nil)},
+			expectedRuleNames:            []rules.RuleName{iconRuleName},
+			expectedRuleNamesWithFailure: []rules.RuleName{},
+			expectedFailureCodes:         map[rules.RuleName]map[types.PackageName][]rules.FailureCode{},
+			expectedIsValidCatalog:       true,
+		},
+		{
+			name: "several failing packages in the same rule",
+			checkResults: []*rules.CheckResult{
+				newTestCheckResult(t, iconRuleName, map[types.PackageName][]rules.FailureCode{
+					fooPackageName: {iconNotFoundCode},
+					barPackageName: {iconTooSmallCode},
+					bazPackageName: {iconNotFoundCode, iconTooSmallCode},
+				}),
+			},
+			expectedRuleNames:            []rules.RuleName{iconRuleName},
+			expectedRuleNamesWithFailure: []rules.RuleName{iconRuleName},
+			expectedFailureCodes: map[rules.RuleName]map[types.PackageName][]rules.FailureCode{
+				iconRuleName: {
+					fooPackageName: {iconNotFoundCode},
+					barPackageName: {iconTooSmallCode},
+					bazPackageName: {iconNotFoundCode, iconTooSmallCode},
+				},
+			},
+			expectedIsValidCatalog: false,
+		},
+		{
+			name: "duplicated rule names keep the failures of every package",
+			checkResults: []*rules.CheckResult{
+				newTestCheckResult(t, iconRuleName, map[types.PackageName][]rules.FailureCode{
+					fooPackageName: {iconNotFoundCode},
+					barPackageName: {iconTooSmallCode},
+				}),
+				newTestCheckResult(t, iconRuleName, map[types.PackageName][]rules.FailureCode{
+					barPackageName: {iconNotFoundCode},
+					bazPackageName: {iconTooSmallCode},
+				}),
+			},
+			expectedRuleNames:            []rules.RuleName{iconRuleName},
+			expectedRuleNamesWithFailure: []rules.RuleName{iconRuleName},
+			expectedFailureCodes: map[rules.RuleName]map[types.PackageName][]rules.FailureCode{
+				iconRuleName: {
+					fooPackageName: {iconNotFoundCode},
+					barPackageName: {iconTooSmallCode, iconNotFoundCode},
+					bazPackageName: {iconTooSmallCode},
+				},
+			},
+			expectedIsValidCatalog: false,
+		},
+		{
+			name: "several rules with failing packages",
+			checkResults: []*rules.CheckResult{
+				newTestCheckResult(t, descriptionRuleName, map[types.PackageName][]rules.FailureCode{
+					fooPackageName: {tooShortCode},
+					barPackageName: {tooShortCode},
+				}),
+				newTestCheckResult(t, iconRuleName, nil),
+				newTestCheckResult(t, iconRuleName, map[types.PackageName][]rules.FailureCode{
+					fooPackageName: {iconNotFoundCode},
+				}),
+			},
+			expectedRuleNames:            []rules.RuleName{descriptionRuleName, iconRuleName},
+			expectedRuleNamesWithFailure: []rules.RuleName{descriptionRuleName, iconRuleName},
+			expectedFailureCodes: map[rules.RuleName]map[types.PackageName][]rules.FailureCode{
+				descriptionRuleName: {
+					fooPackageName: {tooShortCode},
+					barPackageName: {tooShortCode},
+				},
+				iconRuleName: {
+					fooPackageName: {iconNotFoundCode},
+				},
+			},
+			expectedIsValidCatalog: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			report := NewReport()
+			for _, checkResult := range testCase.checkResults {
+				report.AddCheckResult(checkResult.WithRuleSeverity(rules.SeverityError))
+			}
+
+			require.Equal(t, testCase.expectedRuleNames, report.GetRuleNames())
+			require.Equal(t, testCase.expectedRuleNamesWithFailure, report.GetRuleNamesWithFailures())
+			require.Equal(t, testCase.expectedFailureCodes, getReportFailureCodes(report))
+			require.Equal(t, testCase.expectedIsValidCatalog, report.IsValidCatalog())
+		})
+	}
+}
+
+func TestReport_Merge(t *testing.T) {
+	report := NewReport()
+	report.AddCheckedPackages(fooPackageName)
+	report.AddCheckResult(newTestCheckResult(t, iconRuleName, map[types.PackageName][]rules.FailureCode{
+		fooPackageName: {iconNotFoundCode},
+	}))
+	report.AddDuration(4)
+	report.AddRuleDuration(iconRuleName, 2)
+	report.AddPackageCheckDuration(iconRuleName, fooPackageName, 2)
+
+	otherReport := NewReport()
+	otherReport.AddCheckedPackages(barPackageName, bazPackageName)
+	otherReport.AddCheckResult(newTestCheckResult(t, iconRuleName, map[types.PackageName][]rules.FailureCode{
+		fooPackageName: {iconTooSmallCode},
+		barPackageName: {iconNotFoundCode},
+	}))
+	otherReport.AddCheckResult(newTestCheckResult(t, descriptionRuleName, map[types.PackageName][]rules.FailureCode{
+		bazPackageName: {tooShortCode},
+	}))
+	otherReport.AddDuration(6)
+	otherReport.AddRuleDuration(iconRuleName, 5)
+	otherReport.AddPackageCheckDuration(iconRuleName, fooPackageName, 3)
+	otherReport.AddPackageCheckDuration(iconRuleName, barPackageName, 5)
+
+	report.Merge(otherReport)
+
+	require.Equal(t, []types.PackageName{fooPackageName, barPackageName, bazPackageName}, report.GetPackageNames())
+	require.Equal(t, []rules.RuleName{iconRuleName, descriptionRuleName}, report.GetRuleNames())
+	require.Equal(t, map[rules.RuleName]map[types.PackageName][]rules.FailureCode{
+		iconRuleName: {
+			fooPackageName: {iconNotFoundCode, iconTooSmallCode},
+			barPackageName: {iconNotFoundCode},
+		},
+		descriptionRuleName: {
+			bazPackageName: {tooShortCode},
+		},
+	}, getReportFailureCodes(report))
+	require.EqualValues(t, 10, report.GetDuration())
+	require.EqualValues(t, 7, report.GetRuleDuration(iconRuleName))
+	require.EqualValues(t, 5, report.GetPackageCheckDuration(iconRuleName, fooPackageName))
+	require.EqualValues(t, 5, report.GetPackageCheckDuration(iconRuleName, barPackageName))
+}
+
+func TestReport_UncheckedPackagesWithFailures(t *testing.T) {
+	report := NewReport()
+	report.AddCheckedPackages(fooPackageName, barPackageName)
+	report.AddCheckResult(newTestCheckResult(t, iconRuleName, map[types.PackageName][]rules.FailureCode{
+		fooPackageName: {iconTooSmallCode},
+	}))
+	// the package isn't checked because it was removed from the catalog, but a rule comparing the catalogs failed it
+	report.AddCheckResult(newTestCheckResult(t, descriptionRuleName, map[types.PackageName][]rules.FailureCode{
+		bazPackageName: {tooShortCode},
+	}))
+
+	require.Equal(t, []types.PackageName{fooPackageName, barPackageName}, report.GetPackageNames())
+	require.Equal(t, []types.PackageName{fooPackageName, barPackageName, bazPackageName}, report.GetReportedPackageNames())
+	require.True(t, report.IsCheckedPackage(fooPackageName))
+	require.False(t, report.IsCheckedPackage(bazPackageName))
+}
+
+func TestReport_FailureSeverities(t *testing.T) {
+	report := NewReport()
+	report.AddCheckResult(newTestCheckResult(t, iconRuleName, map[types.PackageName][]rules.FailureCode{
+		fooPackageName: {iconNotFoundCode},
+	}).WithRuleSeverity(rules.SeverityWarning))
+
+	require.True(t, report.HasFailures())
+	require.True(t, report.HasWarnings())
+	require.True(t, report.IsValidCatalog())
+}
+
+// newTestCheckResult returns the check result of the rule with a failure for each failure code of each package, the
+// failures take the rule severity unless they set their own default
+func newTestCheckResult(t *testing.T, ruleName rules.RuleName, failureCodes map[types.PackageName][]rules.FailureCode) *rules.CheckResult {
+	return rules.NewCheckResultFromFailures(ruleName, newTestFailures(t, failureCodes))
+}
+
+// newTestFailures returns a failure for each failure code of each package, the failures are found by the package rule
+// of the code checking a package whose files make it fail with that code
+func newTestFailures(t *testing.T, failureCodes map[types.PackageName][]rules.FailureCode) map[types.PackageName][]*rules.Failure {
+	failures := map[types.PackageName][]*rules.Failure{}
+	for packageName, packageFailureCodes := range failureCodes {
+		for _, failureCode := range packageFailureCodes {
+			failures[packageName] = append(failures[packageName], newTestFailure(t, failureCode))
+		}
+	}
+	return failures
+}
+
+func newTestFailure(t *testing.T, failureCode rules.FailureCode) *rules.Failure {
+	packageSourceReader := source.NewInMemoryPackageSourceReader()
+	var ruleName rules.RuleName
+	switch failureCode {
+	case iconNotFoundCode:
+		ruleName = iconRuleName
+	case iconTooSmallCode:
+		ruleName = iconRuleName
+		iconContent := &bytes.Buffer{}
+		require.NoError(t, png.Encode(iconContent, image.NewGray(image.Rect(0, 0, 1, 1))))
+		packageSourceReader.AddFile(testFailureRepositoryOwner, testFailureRepositoryName, consts.KurtosisPackageIconImgName, iconContent.Bytes())
+	case tooShortCode:
+		ruleName = descriptionRuleName
+		packageSourceReader.AddFile(testFailureRepositoryOwner, testFailureRepositoryName, consts.DefaultKurtosisYamlFilename, []byte("name: "+testFailurePackageName+"\ndescription: Postgres\n"))
+	default:
+		require.FailNow(t, "there isn't a package failing with the test failure code", "failure code '%s'", failureCode)
+	}
+
+	allRules, err := rules.GetAll(context.Background(), packageSourceReader, "", nil, nil)
+	require.NoError(t, err)
+	selectedRules, err := rules.SelectRules(allRules, []string{string(ruleName)}, nil)
+	require.NoError(t, err)
+	require.Len(t, selectedRules, 1)
+	packageRule, isPackageRule := selectedRules[0].(rules.PackageRule)
+	require.True(t, isPackageRule, "rule '%s' isn't a package rule", ruleName)
+
+	packageCatalog, err := catalog.GetPackageCatalogFromYamlFileContent([]byte("packages:\n  - name: \"" + testFailurePackageName + "\"\n"))
+	require.NoError(t, err)
+	packageFailures := packageRule.CheckPackage(context.Background(), packageCatalog[0])
+	require.Len(t, packageFailures, 1)
+	require.Equal(t, failureCode, packageFailures[0].GetCode())
+	return packageFailures[0]
+}
+
+// getReportFailureCodes returns the codes of all the failures in the report, by rule and package
+func getReportFailureCodes(report *Report) map[rules.RuleName]map[types.PackageName][]rules.FailureCode {
+	failureCodes := map[rules.RuleName]map[types.PackageName][]rules.FailureCode{}
+	for _, ruleName := range report.GetRuleNamesWithFailures() {
+		failureCodes[ruleName] = map[types.PackageName][]rules.FailureCode{}
+		for _, packageName := range report.GetPackageNamesWithFailures(ruleName) {
+			for _, failure := range report.GetFailures(ruleName, packageName) {
+				failureCodes[ruleName][packageName] = append(failureCodes[ruleName][packageName], failure.GetCode())
+			}
+		}
+	}
+	return failureCodes
+}
@@ -2,56 +2,296 @@ package validator
 
 import (
 	"context"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
 	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/rules"
-	"github.com/kurtosis-tech/kurtosis-package-indexer/server/catalog"
 	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
 	"github.com/kurtosis-tech/stacktrace"
 	"github.com/sirupsen/logrus"
+	"time"
 )
 
 type Validator struct {
 	catalog catalog.PackageCatalog
 	rules   []rules.Rule
+
+	// parallelism is the max number of checks running at the same time
+	parallelism int
+
+	// ruleSeverityOverrides replaces the default severity of the rules for this run
+	ruleSeverityOverrides map[rules.RuleName]rules.Severity
+
+	// catalogDiff contains the changes compared with the base catalog, which are checked by the diff rules.
+	// It's nil if the catalog isn't compared with a base catalog
+	catalogDiff *catalog.CatalogDiff
+
+	// waivers accept some failures, the expired ones and the ones that don't match any failure are reported
+	waivers []*rules.Waiver
+}
+
+// ruleCheckDurations keeps how long the rule checks took, the package start times and durations are only set for
+// package rules
+type ruleCheckDurations struct {
+	// ruleDuration is the time elapsed checking the rule, for package rules it goes from the start of the first package
+	// check to the end of the last one, so the package checks running at the same time are only counted once
+	ruleDuration time.Duration
+
+	packageStartTimes []time.Time
+	packageDurations  []time.Duration
+}
+
+func NewValidator(catalog catalog.PackageCatalog, rules []rules.Rule, parallelism int, ruleSeverityOverrides map[rules.RuleName]rules.Severity, catalogDiff *catalog.CatalogDiff, waivers []*rules.Waiver) *Validator {
+	return &Validator{catalog: catalog, rules: rules, parallelism: parallelism, ruleSeverityOverrides: ruleSeverityOverrides, catalogDiff: catalogDiff, waivers: waivers}
+}
+
+func (validator *Validator) Validate(ctx context.Context) (*Report, error) {
+
+	if validator.parallelism < 1 {
+		return nil, stacktrace.NewError("the validator parallelism has to be at least 1, but it's '%d'", validator.parallelism)
+	}
+
+	if err := validator.checkRuleSeverityOverrides(); err != nil {
+		return nil, stacktrace.Propagate(err, "invalid rule severity overrides")
+	}
+
+	isFirstPackageEntry := getFirstPackageEntries(validator.catalog)
+	startTime := time.Now()
+	checkResults, checkDurations, err := validator.checkRules(ctx, isFirstPackageEntry)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "an error occurred checking the rules")
+	}
+
+	report := NewReport()
+	report.AddDuration(time.Since(startTime))
+	for _, packageData := range validator.catalog {
+		report.AddCheckedPackages(packageData.GetPackageName())
+	}
+
+	now := time.Now()
+	usedWaivers := map[*rules.Waiver]bool{}
+	for ruleIndex, rule := range validator.rules {
+		checkResult := validator.withRuleSeverity(rule, checkResults[ruleIndex])
+		checkResult = validator.applyWaivers(report, checkResult, usedWaivers, now)
+		report.AddCheckResult(checkResult)
+		validator.addCheckDurations(report, rule.GetName(), checkDurations[ruleIndex], isFirstPackageEntry)
+		if !checkResult.WasValidated() {
+			logrus.Debugf("the current catalog version does not pass rule '%s'", checkResult.GetRuleName())
+			continue
+		}
+		logrus.Debugf("'%s' rule passed", checkResult.GetRuleName())
+	}
+
+	if len(validator.waivers) > 0 {
+		checkedWaivers := validator.getCheckedWaivers(report)
+		report.AddCheckResult(rules.CheckWaivers(checkedWaivers, getUnusedWaivers(checkedWaivers, usedWaivers, now), now))
+	}
+
+	return report, nil
+}
+
+// applyWaivers returns a copy of the check result without the failures accepted by a waiver that isn't expired,
+// the failures accepted are added to the report as waived failures and their waivers are marked as used
+func (validator *Validator) applyWaivers(report *Report, checkResult *rules.CheckResult, usedWaivers map[*rules.Waiver]bool, now time.Time) *rules.CheckResult {
+	if len(validator.waivers) == 0 {
+		return checkResult
+	}
+
+	ruleName := checkResult.GetRuleName()
+	failures := map[types.PackageName][]*rules.Failure{}
+	for packageName, packageFailures := range checkResult.GetFailures() {
+		for _, failure := range packageFailures {
+			waiver := validator.findWaiver(ruleName, packageName, failure, now)
+			if waiver == nil {
+				failures[packageName] = append(failures[packageName], failure)
+				continue
+			}
+			logrus.Debugf("the '%s' failure of package '%s' for rule '%s' was waived", failure.GetCode(), packageName, ruleName)
+			report.AddWaivedFailure(newWaivedFailure(ruleName, packageName, failure, waiver))
+			usedWaivers[waiver] = true
+		}
+	}
+	return rules.NewCheckResultFromFailures(ruleName, failures)
 }
 
-func NewValidator(catalog catalog.PackageCatalog, rules []rules.Rule) *Validator {
-	return &Validator{catalog: catalog, rules: rules}
+// findWaiver returns the waiver that accepts the failure, or nil if there isn't any that isn't expired
+func (validator *Validator) findWaiver(ruleName rules.RuleName, packageName types.PackageName, failure *rules.Failure, now time.Time) *rules.Waiver {
+	for _, waiver := range validator.waivers {
+		if waiver.Matches(ruleName, packageName, failure) && !waiver.IsExpired(now) {
+			return waiver
+		}
+	}
+	return nil
 }
 
-func (validator *Validator) Validate(ctx context.Context) (*result, error) {
+// getCheckedWaivers returns the waivers whose package and rule were checked, the waivers of packages or rules not
+// checked in this run can't be known to be expired or unused, as their failures weren't looked for
+func (validator *Validator) getCheckedWaivers(report *Report) []*rules.Waiver {
+	checkedRules := map[rules.RuleName]bool{}
+	for _, ruleName := range report.GetRuleNames() {
+		checkedRules[ruleName] = true
+	}
+	checkedPackages := map[types.PackageName]bool{}
+	for _, packageName := range report.GetPackageNames() {
+		checkedPackages[packageName] = true
+	}
 
-	isValidCatalog := true
-	rulesResult := map[rules.RuleName]map[types.PackageName][]string{}
+	checkedWaivers := []*rules.Waiver{}
+	for _, waiver := range validator.waivers {
+		if checkedRules[waiver.GetRuleName()] && checkedPackages[waiver.GetPackageName()] {
+			checkedWaivers = append(checkedWaivers, waiver)
+		}
+	}
+	return checkedWaivers
+}
 
-	for _, rule := range validator.rules {
-		logrus.Debugf("Checking rule '%s'", rule.GetName())
-		if checkResult := rule.Check(ctx, validator.catalog); !checkResult.WasValidated() {
-			isValidCatalog = false
-			failures := checkResult.GetFailures()
-			var packageName types.PackageName
-			for packageNameInFailures := range failures {
-				packageName = packageNameInFailures
+// getUnusedWaivers returns the checked waivers that aren't expired and didn't match any failure
+func getUnusedWaivers(checkedWaivers []*rules.Waiver, usedWaivers map[*rules.Waiver]bool, now time.Time) []*rules.Waiver {
+	unusedWaivers := []*rules.Waiver{}
+	for _, waiver := range checkedWaivers {
+		if !usedWaivers[waiver] && !waiver.IsExpired(now) {
+			unusedWaivers = append(unusedWaivers, waiver)
+		}
+	}
+	return unusedWaivers
+}
+
+// withRuleSeverity returns a copy of the check result with the severity of the rule, a severity overridden for the run
+// also replaces the default severity set by the failures
+func (validator *Validator) withRuleSeverity(rule rules.Rule, checkResult *rules.CheckResult) *rules.CheckResult {
+	if severity, found := validator.ruleSeverityOverrides[rule.GetName()]; found {
+		return checkResult.WithRuleSeverityOverride(severity)
+	}
+	return checkResult.WithRuleSeverity(rule.GetDefaultSeverity())
+}
+
+// addCheckDurations adds the durations of the rule checks to the report, the duplicated package entries aren't checked
+// so they don't have a duration
+func (validator *Validator) addCheckDurations(report *Report, ruleName rules.RuleName, checkDurations *ruleCheckDurations, isFirstPackageEntry []bool) {
+	report.AddRuleDuration(ruleName, checkDurations.ruleDuration)
+	for packageIndex, packageDuration := range checkDurations.packageDurations {
+		if !isFirstPackageEntry[packageIndex] {
+			continue
+		}
+		report.AddPackageCheckDuration(ruleName, validator.catalog[packageIndex].GetPackageName(), packageDuration)
+	}
+}
+
+// checkRuleSeverityOverrides checks that the overrides refer to rules being checked, so a typo in a rule name doesn't go unnoticed
+func (validator *Validator) checkRuleSeverityOverrides() error {
+	for ruleName := range validator.ruleSeverityOverrides {
+		found := false
+		for _, rule := range validator.rules {
+			if rule.GetName() == ruleName {
+				found = true
 				break
 			}
-			ruleName := checkResult.GetRuleName()
-			failuresByPackageForRule, found := rulesResult[ruleName]
-			if found {
-				packageFailures, err := checkResult.GetFailuresForPackage(packageName)
-				if err != nil {
-					return nil, stacktrace.Propagate(err, "an error occurred getting failures for package '%s'", packageName)
-				}
-				failuresByPackageForRule[packageName] = packageFailures
-			} else {
-				rulesResult[ruleName] = checkResult.GetFailures()
+		}
+		if !found {
+			return stacktrace.NewError("there is a severity override for rule '%s' but there isn't any rule with that name", ruleName)
+		}
+	}
+	return nil
+}
+
+// checkRules runs all the rule checks in the worker pool and returns the check results and their durations in the same
+// order as the rules. Package rules are split in one job per package, each job writes in its own slot so there is no
+// shared state between them. Only the first entry of a duplicated package is checked, the Duplicated package rule
+// reports the other ones
+func (validator *Validator) checkRules(ctx context.Context, isFirstPackageEntry []bool) ([]*rules.CheckResult, []*ruleCheckDurations, error) {
+	checkResults := make([]*rules.CheckResult, len(validator.rules))
+	checkDurations := make([]*ruleCheckDurations, len(validator.rules))
+	packageFailuresByRule := make([][][]*rules.Failure, len(validator.rules))
+	jobs := []checkJob{}
+
+	for ruleIndex, rule := range validator.rules {
+		ruleIndex, rule := ruleIndex, rule
+		checkDurations[ruleIndex] = &ruleCheckDurations{}
+		if diffRule, isDiffRule := rule.(rules.DiffRule); isDiffRule && validator.catalogDiff != nil {
+			jobs = append(jobs, func(ctx context.Context) {
+				logrus.Debugf("Checking rule '%s' over the catalog changes", diffRule.GetName())
+				startTime := time.Now()
+				checkResults[ruleIndex] = diffRule.CheckDiff(ctx, validator.catalogDiff)
+				checkDurations[ruleIndex].ruleDuration = time.Since(startTime)
+			})
+			continue
+		}
+
+		packageRule, isPackageRule := rule.(rules.PackageRule)
+		if !isPackageRule {
+			jobs = append(jobs, func(ctx context.Context) {
+				logrus.Debugf("Checking rule '%s'", rule.GetName())
+				startTime := time.Now()
+				checkResults[ruleIndex] = rule.Check(ctx, validator.catalog)
+				checkDurations[ruleIndex].ruleDuration = time.Since(startTime)
+			})
+			continue
+		}
+
+		packageFailuresByRule[ruleIndex] = make([][]*rules.Failure, len(validator.catalog))
+		checkDurations[ruleIndex].packageStartTimes = make([]time.Time, len(validator.catalog))
+		checkDurations[ruleIndex].packageDurations = make([]time.Duration, len(validator.catalog))
+		for packageIndex := range validator.catalog {
+			if !isFirstPackageEntry[packageIndex] {
+				continue
 			}
+			packageIndex := packageIndex
+			packageData := validator.catalog[packageIndex]
+			jobs = append(jobs, func(ctx context.Context) {
+				logrus.Debugf("Checking rule '%s' for package '%s'", packageRule.GetName(), packageData.GetPackageName())
+				startTime := time.Now()
+				packageFailuresByRule[ruleIndex][packageIndex] = rules.CheckPackageInCatalog(ctx, packageRule, packageData)
+				checkDurations[ruleIndex].packageStartTimes[packageIndex] = startTime
+				checkDurations[ruleIndex].packageDurations[packageIndex] = time.Since(startTime)
+			})
+		}
+	}
+
+	if err := runCheckJobs(ctx, validator.parallelism, jobs); err != nil {
+		return nil, nil, stacktrace.Propagate(err, "an error occurred running the rule checks")
+	}
 
-			logrus.Debugf("the current catalog version does not pass rule '%s'", rule.GetName())
+	for ruleIndex, rule := range validator.rules {
+		packagesFailures := packageFailuresByRule[ruleIndex]
+		if packagesFailures == nil {
 			continue
 		}
-		logrus.Debugf("'%s' rule passed", rule.GetName())
+		failures := map[types.PackageName][]*rules.Failure{}
+		for packageIndex, packageFailures := range packagesFailures {
+			if len(packageFailures) > 0 {
+				failures[validator.catalog[packageIndex].GetPackageName()] = packageFailures
+			}
+		}
+		checkResults[ruleIndex] = rules.NewCheckResultFromFailures(rule.GetName(), failures)
+		checkDurations[ruleIndex].ruleDuration = checkDurations[ruleIndex].getPackagesElapsedDuration()
 	}
 
-	resultObj := newResult(isValidCatalog, rulesResult)
+	return checkResults, checkDurations, nil
+}
 
-	return resultObj, nil
+// getPackagesElapsedDuration returns the time from the start of the first package check to the end of the last one,
+// the package entries that weren't checked don't have a start time
+func (checkDurations *ruleCheckDurations) getPackagesElapsedDuration() time.Duration {
+	var firstStartTime, lastEndTime time.Time
+	for packageIndex, packageStartTime := range checkDurations.packageStartTimes {
+		if packageStartTime.IsZero() {
+			continue
+		}
+		if firstStartTime.IsZero() || packageStartTime.Before(firstStartTime) {
+			firstStartTime = packageStartTime
+		}
+		if packageEndTime := packageStartTime.Add(checkDurations.packageDurations[packageIndex]); packageEndTime.After(lastEndTime) {
+			lastEndTime = packageEndTime
+		}
+	}
+	return lastEndTime.Sub(firstStartTime)
+}
+
+// getFirstPackageEntries returns, for each entry of the catalog, true if it's the first entry of its package
+func getFirstPackageEntries(packageCatalog catalog.PackageCatalog) []bool {
+	isFirstPackageEntry := make([]bool, len(packageCatalog))
+	foundPackageNames := map[types.PackageName]bool{}
+	for packageIndex, packageData := range packageCatalog {
+		isFirstPackageEntry[packageIndex] = !foundPackageNames[packageData.GetPackageName()]
+		foundPackageNames[packageData.GetPackageName()] = true
+	}
+	return isFirstPackageEntry
 }
@@ -0,0 +1,306 @@
+package validator
+
+import (
+	"context"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/catalog"
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/rules"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+	"github.com/stretchr/testify/require"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testParallelism = 4
+
+	testPackageCheckDuration = 20 * time.Millisecond
+
+	testCatalogYaml = `packages:
+  - name: "github.com/foo/foo"
+  - name: "github.com/foo/bar"
+  - name: "github.com/foo/baz"
+`
+
+	duplicatedPackagesTestCatalogYaml = `packages:
+  - name: "github.com/foo/foo"
+  - name: "github.com/foo/foo"
+    ref: "v2"
+  - name: "github.com/foo/bar"
+    ref: "v1"
+  - name: "github.com/foo/bar"
+`
+)
+
+// testRule is a rule checking the whole catalog at once, it returns the same failures on every check
+type testRule struct {
+	name     rules.RuleName
+	failures map[types.PackageName][]*rules.Failure
+}
+
+func (rule *testRule) GetName() rules.RuleName {
+	return rule.name
+}
+
+func (rule *testRule) GetDescription() string {
+	return "returns the failures it was created with"
+}
+
+func (rule *testRule) GetParameters() map[string]interface{} {
+	return map[string]interface{}{}
+}
+
+func (rule *testRule) GetDefaultSeverity() rules.Severity {
+	return rules.SeverityError
+}
+
+func (rule *testRule) Check(_ context.Context, _ catalog.PackageCatalog) *rules.CheckResult {
+	return rules.NewCheckResultFromFailures(rule.name, rule.failures)
+}
+
+// testPackageRule is a package rule, so the validator checks each package in its own job
+type testPackageRule struct {
+	*testRule
+}
+
+func (rule *testPackageRule) CheckPackage(_ context.Context, packageData rules.PackageData) []*rules.Failure {
+	return rule.failures[packageData.GetPackageName()]
+}
+
+// refPackageRule is a package rule failing the package entries with a ref, it records the refs of the entries checked
+type refPackageRule struct {
+	*testRule
+	checkedRefsMutex *sync.Mutex
+	checkedRefs      []string
+}
+
+func (rule *refPackageRule) CheckPackage(_ context.Context, packageData rules.PackageData) []*rules.Failure {
+	rule.checkedRefsMutex.Lock()
+	defer rule.checkedRefsMutex.Unlock()
+	rule.checkedRefs = append(rule.checkedRefs, packageData.GetRef())
+	if packageData.GetRef() == "" {
+		return []*rules.Failure{}
+	}
+	return rule.failures[packageData.GetPackageName()]
+}
+
+// slowPackageRule is a package rule taking some time to check each package
+type slowPackageRule struct {
+	*testRule
+}
+
+func (rule *slowPackageRule) CheckPackage(_ context.Context, _ rules.PackageData) []*rules.Failure {
+	time.Sleep(testPackageCheckDuration)
+	return []*rules.Failure{}
+}
+
+func TestValidator_Validate(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		rules                 []rules.Rule
+		ruleSeverityOverrides map[rules.RuleName]rules.Severity
+		expectedFailureCodes  map[rules.RuleName]map[types.PackageName][]rules.FailureCode
+		expectedIsValid       bool
+	}{
+		{
+			name:                  "rules without failures",
+			rules:                 []rules.Rule{&testRule{name: iconRuleName}, &testPackageRule{&testRule{name: descriptionRuleName}}},
+			ruleSeverityOverrides: nil,
+			expectedFailureCodes:  map[rules.RuleName]map[types.PackageName][]rules.FailureCode{},
+			expectedIsValid:       true,
+		},
+		{
+			name: "package rule with several failing packages",
+			rules: []rules.Rule{&testPackageRule{&testRule{name: iconRuleName, failures: newTestFailures(t, map[types.PackageName][]rules.FailureCode{
+				fooPackageName: {iconNotFoundCode},
+				bazPackageName: {iconNotFoundCode, iconTooSmallCode},
+			})}}},
+			ruleSeverityOverrides: nil,
+			expectedFailureCodes: map[rules.RuleName]map[types.PackageName][]rules.FailureCode{
+				iconRuleName: {
+					fooPackageName: {iconNotFoundCode},
+					bazPackageName: {iconNotFoundCode, iconTooSmallCode},
+				},
+			},
+			expectedIsValid: false,
+		},
+		{
+			name: "rules with the same name keep the failures of both",
+			rules: []rules.Rule{
+				&testRule{name: iconRuleName, failures: newTestFailures(t, map[types.PackageName][]rules.FailureCode{
+					fooPackageName: {iconNotFoundCode},
+					barPackageName: {iconNotFoundCode},
+				})},
+				&testPackageRule{&testRule{name: iconRuleName, failures: newTestFailures(t, map[types.PackageName][]rules.FailureCode{
+					barPackageName: {iconTooSmallCode},
+					bazPackageName: {iconTooSmallCode},
+				})}},
+			},
+			ruleSeverityOverrides: nil,
+			expectedFailureCodes: map[rules.RuleName]map[types.PackageName][]rules.FailureCode{
+				iconRuleName: {
+					fooPackageName: {iconNotFoundCode},
+					barPackageName: {iconNotFoundCode, iconTooSmallCode},
+					bazPackageName: {iconTooSmallCode},
+				},
+			},
+			expectedIsValid: false,
+		},
+		{
+			name: "severity override to warning keeps the catalog valid",
+			rules: []rules.Rule{&testRule{name: iconRuleName, failures: newTestFailures(t, map[types.PackageName][]rules.FailureCode{
+				fooPackageName: {iconNotFoundCode},
+			})}},
+			ruleSeverityOverrides: map[rules.RuleName]rules.Severity{iconRuleName: rules.SeverityWarning},
+			expectedFailureCodes: map[rules.RuleName]map[types.PackageName][]rules.FailureCode{
+				iconRuleName: {
+					fooPackageName: {iconNotFoundCode},
+				},
+			},
+			expectedIsValid: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			validator := NewValidator(getTestCatalog(t), testCase.rules, testParallelism, testCase.ruleSeverityOverrides, nil, nil)
+			report, err := validator.Validate(context.Background())
+			require.NoError(t, err)
+
+			require.Equal(t, []types.PackageName{fooPackageName, barPackageName, bazPackageName}, report.GetPackageNames())
+			require.Equal(t, testCase.expectedFailureCodes, getReportFailureCodes(report))
+			require.Equal(t, testCase.expectedIsValid, report.IsValidCatalog())
+		})
+	}
+}
+
+func TestValidator_Validate_DuplicatedPackages(t *testing.T) {
+	packageCatalog, err := catalog.GetPackageCatalogFromYamlFileContent([]byte(duplicatedPackagesTestCatalogYaml))
+	require.NoError(t, err)
+	iconRule := &refPackageRule{
+		testRule: &testRule{name: iconRuleName, failures: newTestFailures(t, map[types.PackageName][]rules.FailureCode{
+			fooPackageName: {iconNotFoundCode},
+			barPackageName: {iconNotFoundCode},
+		})},
+		checkedRefsMutex: &sync.Mutex{},
+		checkedRefs:      []string{},
+	}
+
+	validator := NewValidator(packageCatalog, []rules.Rule{iconRule}, testParallelism, nil, nil, nil)
+	report, err := validator.Validate(context.Background())
+	require.NoError(t, err)
+
+	// only the first entry of each package is checked, so the failures of the second entry of 'foo' aren't reported
+	sort.Strings(iconRule.checkedRefs)
+	require.Equal(t, []string{"", "v1"}, iconRule.checkedRefs)
+	require.Equal(t, map[rules.RuleName]map[types.PackageName][]rules.FailureCode{
+		iconRuleName: {
+			barPackageName: {iconNotFoundCode},
+		},
+	}, getReportFailureCodes(report))
+}
+
+func TestValidator_Validate_PackageRuleDuration(t *testing.T) {
+	validator := NewValidator(getTestCatalog(t), []rules.Rule{&slowPackageRule{&testRule{name: iconRuleName}}}, testParallelism, nil, nil, nil)
+	report, err := validator.Validate(context.Background())
+	require.NoError(t, err)
+
+	// the packages are checked at the same time, so the rule takes less time than the sum of the package checks
+	var packageDurationsSum time.Duration
+	for _, packageName := range report.GetPackageNames() {
+		packageDuration := report.GetPackageCheckDuration(iconRuleName, packageName)
+		require.GreaterOrEqual(t, packageDuration, testPackageCheckDuration)
+		require.GreaterOrEqual(t, report.GetRuleDuration(iconRuleName), packageDuration)
+		packageDurationsSum += packageDuration
+	}
+	require.Less(t, report.GetRuleDuration(iconRuleName), packageDurationsSum)
+	require.GreaterOrEqual(t, report.GetDuration(), report.GetRuleDuration(iconRuleName))
+}
+
+func TestValidator_Validate_UnknownSeverityOverride(t *testing.T) {
+	validator := NewValidator(getTestCatalog(t), []rules.Rule{&testRule{name: iconRuleName}}, testParallelism, map[rules.RuleName]rules.Severity{descriptionRuleName: rules.SeverityWarning}, nil, nil)
+	_, err := validator.Validate(context.Background())
+	require.Error(t, err)
+}
+
+func TestValidator_Validate_InvalidParallelism(t *testing.T) {
+	validator := NewValidator(getTestCatalog(t), []rules.Rule{&testRule{name: iconRuleName}}, 0, nil, nil, nil)
+	_, err := validator.Validate(context.Background())
+	require.Error(t, err)
+}
+
+func TestValidator_Validate_Waivers(t *testing.T) {
+	waivers, err := rules.ReadWaiversFileContent([]byte(`waivers:
+  - package: "github.com/foo/foo"
+    rule: "Valid package icon"
+    code: "ICON_NOT_FOUND"
+    reason: "the icon is being designed"
+    expires: "2999-12-31"
+`))
+	require.NoError(t, err)
+	iconRule := &testPackageRule{&testRule{name: iconRuleName, failures: newTestFailures(t, map[types.PackageName][]rules.FailureCode{
+		fooPackageName: {iconNotFoundCode, iconTooSmallCode},
+	})}}
+
+	validator := NewValidator(getTestCatalog(t), []rules.Rule{iconRule}, testParallelism, nil, nil, waivers)
+	report, err := validator.Validate(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, map[rules.RuleName]map[types.PackageName][]rules.FailureCode{
+		iconRuleName: {
+			fooPackageName: {iconTooSmallCode},
+		},
+	}, getReportFailureCodes(report))
+	require.Len(t, report.GetWaivedFailures(), 1)
+}
+
+func TestValidator_Validate_ExpiredAndUnusedWaivers(t *testing.T) {
+	waivers, err := rules.ReadWaiversFileContent([]byte(`waivers:
+  - package: "github.com/foo/foo"
+    rule: "Valid package icon"
+    code: "ICON_NOT_FOUND"
+    reason: "the icon is being designed"
+    expires: "2000-01-01"
+  - package: "github.com/foo/bar"
+    rule: "Valid package icon"
+    code: "ICON_TOO_SMALL"
+    reason: "the icon is being resized"
+    expires: "2999-12-31"
+  - package: "github.com/foo/other"
+    rule: "Valid package icon"
+    code: "ICON_NOT_FOUND"
+    reason: "the package isn't checked in this run"
+    expires: "2000-01-01"
+  - package: "github.com/foo/foo"
+    rule: "Valid package description"
+    code: "DESCRIPTION_TOO_SHORT"
+    reason: "the rule isn't checked in this run"
+    expires: "2000-01-01"
+`))
+	require.NoError(t, err)
+	iconRule := &testPackageRule{&testRule{name: iconRuleName, failures: newTestFailures(t, map[types.PackageName][]rules.FailureCode{
+		fooPackageName: {iconNotFoundCode},
+	})}}
+
+	validator := NewValidator(getTestCatalog(t), []rules.Rule{iconRule}, testParallelism, nil, nil, waivers)
+	report, err := validator.Validate(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, map[rules.RuleName]map[types.PackageName][]rules.FailureCode{
+		iconRuleName: {
+			fooPackageName: {iconNotFoundCode},
+		},
+		waiversRuleName: {
+			fooPackageName: {waiverExpiredCode},
+			barPackageName: {waiverUnusedCode},
+		},
+	}, getReportFailureCodes(report))
+	require.Empty(t, report.GetWaivedFailures())
+}
+
+func getTestCatalog(t *testing.T) catalog.PackageCatalog {
+	packageCatalog, err := catalog.GetPackageCatalogFromYamlFileContent([]byte(testCatalogYaml))
+	require.NoError(t, err)
+	return packageCatalog
+}
@@ -0,0 +1,34 @@
+package validator
+
+import (
+	"github.com/kurtosis-tech/kurtosis-package-catalog/catalog-validator/validation/rules"
+	"github.com/kurtosis-tech/kurtosis-package-indexer/server/types"
+)
+
+// WaivedFailure is a failure found by a rule that was accepted by a waiver, so it doesn't count as a failure
+type WaivedFailure struct {
+	ruleName    rules.RuleName
+	packageName types.PackageName
+	failure     *rules.Failure
+	waiver      *rules.Waiver
+}
+
+func newWaivedFailure(ruleName rules.RuleName, packageName types.PackageName, failure *rules.Failure, waiver *rules.Waiver) *WaivedFailure {
+	return &WaivedFailure{ruleName: ruleName, packageName: packageName, failure: failure, waiver: waiver}
+}
+
+func (waivedFailure *WaivedFailure) GetRuleName() rules.RuleName {
+	return waivedFailure.ruleName
+}
+
+func (waivedFailure *WaivedFailure) GetPackageName() types.PackageName {
+	return waivedFailure.packageName
+}
+
+func (waivedFailure *WaivedFailure) GetFailure() *rules.Failure {
+	return waivedFailure.failure
+}
+
+func (waivedFailure *WaivedFailure) GetWaiver() *rules.Waiver {
+	return waivedFailure.waiver
+}
@@ -0,0 +1,45 @@
+package validator
+
+import (
+	"context"
+	"github.com/kurtosis-tech/stacktrace"
+	"sync"
+)
+
+// checkJob is the unit of work run by the worker pool, it checks a rule over the whole catalog or over a single package
+type checkJob func(ctx context.Context)
+
+// runCheckJobs runs the jobs with at most 'parallelism' of them at the same time and waits until all of them are done.
+// The jobs not started yet are skipped once the context is cancelled, and the context error is returned
+func runCheckJobs(ctx context.Context, parallelism int, jobs []checkJob) error {
+	jobsChan := make(chan checkJob, len(jobs))
+	for _, job := range jobs {
+		jobsChan <- job
+	}
+	close(jobsChan)
+
+	numberOfWorkers := parallelism
+	if len(jobs) < numberOfWorkers {
+		numberOfWorkers = len(jobs)
+	}
+
+	waitGroup := &sync.WaitGroup{}
+	for workerIndex := 0; workerIndex < numberOfWorkers; workerIndex++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for job := range jobsChan {
+				if ctx.Err() != nil {
+					return
+				}
+				job(ctx)
+			}
+		}()
+	}
+	waitGroup.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return stacktrace.Propagate(err, "the rule checks were cancelled before all of them finished")
+	}
+	return nil
+}
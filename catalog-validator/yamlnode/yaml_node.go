@@ -0,0 +1,25 @@
+package yamlnode
+
+import "gopkg.in/yaml.v3"
+
+const (
+	// the content of a YAML mapping node alternates keys and values
+	yamlMappingKeyValueStep = 2
+)
+
+// FindMappingValueNode returns the value node of the key in a mapping node, or in the mapping of a document node.
+// It returns nil if the key is not found
+func FindMappingValueNode(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for keyIndex := 0; keyIndex+1 < len(node.Content); keyIndex += yamlMappingKeyValueStep {
+		if node.Content[keyIndex].Value == key {
+			return node.Content[keyIndex+1]
+		}
+	}
+	return nil
+}